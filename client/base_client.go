@@ -15,6 +15,7 @@ package pd
 
 import (
 	"context"
+	"math/rand"
 	"reflect"
 	"sort"
 	"sync"
@@ -47,6 +48,17 @@ type baseClient struct {
 	security SecurityOption
 
 	gRPCDialOptions []grpc.DialOption
+
+	// followerReadMaxStaleness is the max staleness of the regions read from
+	// followers, 0 means the follower read is disabled.
+	followerReadMaxStaleness time.Duration
+
+	// tsoMaxBatchWait is the max time to wait for more TSO requests before
+	// sending a batch, 0 means the adaptive batching is disabled.
+	tsoMaxBatchWait time.Duration
+	// tsoStreamCount is the number of TSO streams to the leader, which is 1
+	// if not set.
+	tsoStreamCount int
 }
 
 // SecurityOption records options about tls
@@ -66,6 +78,38 @@ func WithGRPCDialOptions(opts ...grpc.DialOption) ClientOption {
 	}
 }
 
+// WithFollowerRead allows the region and store reads to be served by a random
+// follower, whose regions are synced from the leader no earlier than
+// maxStaleness ago. The read falls back to the leader if the follower fails or
+// does not have the data. The leader peers of the regions read from followers
+// are unknown. It takes effect only if the region storage is used by PD,
+// otherwise followers do not sync regions from the leader.
+func WithFollowerRead(maxStaleness time.Duration) ClientOption {
+	return func(c *baseClient) {
+		c.followerReadMaxStaleness = maxStaleness
+	}
+}
+
+// WithTSOBatchWait enables the adaptive batching of the TSO requests. After
+// receiving a request, the client waits for more requests to grow the batch,
+// for a fraction of the recent RPC latency but no longer than maxWait. It
+// trades a little latency for fewer RPCs of the callers with very high QPS.
+func WithTSOBatchWait(maxWait time.Duration) ClientOption {
+	return func(c *baseClient) {
+		c.tsoMaxBatchWait = maxWait
+	}
+}
+
+// WithTSOStreamCount configures the number of TSO streams to the leader, which
+// send the batches concurrently. The timestamps are still monotonic for the
+// requests not concurrent with each other. It does not affect the local
+// timestamps, which always use one stream.
+func WithTSOStreamCount(count int) ClientOption {
+	return func(c *baseClient) {
+		c.tsoStreamCount = count
+	}
+}
+
 // newBaseClient returns a new baseClient.
 func newBaseClient(ctx context.Context, urls []string, security SecurityOption, opts ...ClientOption) (*baseClient, error) {
 	ctx1, cancel := context.WithCancel(ctx)
@@ -224,7 +268,9 @@ func (c *baseClient) updateURLs(members []*pdpb.Member) {
 	}
 
 	log.Info("[pd] update member urls", zap.Strings("old-urls", c.urls), zap.Strings("new-urls", urls))
+	c.connMu.Lock()
 	c.urls = urls
+	c.connMu.Unlock()
 }
 
 func (c *baseClient) switchLeader(addrs []string) error {
@@ -250,6 +296,23 @@ func (c *baseClient) switchLeader(addrs []string) error {
 	return nil
 }
 
+// getFollowerAddr returns the address of a random follower, or an empty string
+// if there is no follower.
+func (c *baseClient) getFollowerAddr() string {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	followers := make([]string, 0, len(c.urls))
+	for _, u := range c.urls {
+		if u != c.connMu.leader {
+			followers = append(followers, u)
+		}
+	}
+	if len(followers) == 0 {
+		return ""
+	}
+	return followers[rand.Intn(len(followers))]
+}
+
 func (c *baseClient) getOrCreateGRPCConn(addr string) (*grpc.ClientConn, error) {
 	c.connMu.RLock()
 	conn, ok := c.connMu.clientConns[addr]
@@ -17,14 +17,17 @@ import (
 	"context"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 // Client is a PD (Placement Driver) client.
@@ -36,9 +39,9 @@ type Client interface {
 	// syncing leader from server.
 	GetLeaderAddr() string
 	// GetTS gets a timestamp from PD.
-	GetTS(ctx context.Context) (int64, int64, error)
+	GetTS(ctx context.Context, opts ...GetTSOption) (int64, int64, error)
 	// GetTSAsync gets a timestamp from PD, without block the caller.
-	GetTSAsync(ctx context.Context) TSFuture
+	GetTSAsync(ctx context.Context, opts ...GetTSOption) TSFuture
 	// GetRegion gets a region and its leader Peer from PD by key.
 	// The region may expire after split. Caller is responsible for caching and
 	// taking care of region change.
@@ -90,6 +93,23 @@ func WithExcludeTombstone() GetStoreOption {
 	return func(op *GetStoreOp) { op.excludeTombstone = true }
 }
 
+// GetTSOp represents available options when getting timestamps.
+type GetTSOp struct {
+	dcLocation string
+}
+
+// GetTSOption configures GetTSOp.
+type GetTSOption func(*GetTSOp)
+
+// WithDCLocation gets the local timestamp of the data center, which is
+// allocated by the local TSO allocator elected among the PD members in the
+// data center. A global timestamp is greater than the local timestamps
+// allocated before it, but the local timestamps of different data centers
+// are not ordered.
+func WithDCLocation(dcLocation string) GetTSOption {
+	return func(op *GetTSOp) { op.dcLocation = dcLocation }
+}
+
 type tsoRequest struct {
 	start    time.Time
 	ctx      context.Context
@@ -104,6 +124,13 @@ const (
 	updateLeaderTimeout   = time.Second // Use a shorter timeout to recover faster from network isolation.
 	maxMergeTSORequests   = 10000
 	maxInitClusterRetries = 100
+	followerReadTimeout   = time.Second // Use a shorter timeout to leave time for falling back to the leader.
+	// tsoBatchWaitDivisor limits the time to wait for more TSO requests to a
+	// quarter of the RPC latency, so the batching adds little latency.
+	tsoBatchWaitDivisor = 4
+	// tsoLatencySmoothing is the weight of the old latency in the moving
+	// average of the TSO RPC latency, in eighths.
+	tsoLatencySmoothing = 7
 )
 
 var (
@@ -115,16 +142,44 @@ var (
 	errTSOLength = errors.New("[pd] tso length in rpc response is incorrect")
 )
 
-type client struct {
-	*baseClient
+// tsoDispatcher batches the requests for the global timestamps or the local
+// timestamps of a data center, and sends them by a stream.
+type tsoDispatcher struct {
+	// dcLocation is empty for the global timestamps.
+	dcLocation  string
 	tsoRequests chan *tsoRequest
 
+	// rpcLatency is the moving average of the TSO RPC latency in
+	// nanoseconds, from which the time to wait for more requests is tuned.
+	rpcLatency int64
+
+	// localAddr is the last known local allocator leader of the data center.
+	localAddr string
+
+	// streams is the number of workers having a stream, which serve the
+	// requests while the other workers fail to create their streams.
+	streams int32
+}
+
+// tsoWorker sends the batches of a dispatcher by its own stream. The
+// timestamps are checked to be increasing in each stream.
+type tsoWorker struct {
 	lastPhysical int64
 	lastLogical  int64
 
 	tsDeadlineCh chan deadline
 }
 
+type client struct {
+	*baseClient
+
+	tsoDispatchers struct {
+		sync.RWMutex
+		// The dispatcher of the global timestamps is keyed by "".
+		m map[string]*tsoDispatcher
+	}
+}
+
 // NewClient creates a PD client.
 func NewClient(pdAddrs []string, security SecurityOption, opts ...ClientOption) (Client, error) {
 	return NewClientWithContext(context.Background(), pdAddrs, security, opts...)
@@ -137,19 +192,46 @@ func NewClientWithContext(ctx context.Context, pdAddrs []string, security Securi
 	if err != nil {
 		return nil, err
 	}
-	c := &client{
-		baseClient:   base,
-		tsoRequests:  make(chan *tsoRequest, maxMergeTSORequests),
-		tsDeadlineCh: make(chan deadline, 1),
-	}
-
-	c.wg.Add(2)
-	go c.tsLoop()
-	go c.tsCancelLoop()
+	c := &client{baseClient: base}
+	c.tsoDispatchers.m = make(map[string]*tsoDispatcher)
+	c.getTSODispatcher("")
 
 	return c, nil
 }
 
+// getTSODispatcher returns the dispatcher of the data center, and starts it
+// if it does not exist.
+func (c *client) getTSODispatcher(dcLocation string) *tsoDispatcher {
+	c.tsoDispatchers.RLock()
+	d, ok := c.tsoDispatchers.m[dcLocation]
+	c.tsoDispatchers.RUnlock()
+	if ok {
+		return d
+	}
+
+	c.tsoDispatchers.Lock()
+	defer c.tsoDispatchers.Unlock()
+	if d, ok = c.tsoDispatchers.m[dcLocation]; ok {
+		return d
+	}
+	d = &tsoDispatcher{
+		dcLocation:  dcLocation,
+		tsoRequests: make(chan *tsoRequest, maxMergeTSORequests),
+	}
+	c.tsoDispatchers.m[dcLocation] = d
+	workerCount := 1
+	if dcLocation == "" && c.tsoStreamCount > 1 {
+		workerCount = c.tsoStreamCount
+	}
+	c.wg.Add(2 * workerCount)
+	for i := 0; i < workerCount; i++ {
+		w := &tsoWorker{tsDeadlineCh: make(chan deadline, 1)}
+		go c.tsLoop(d, w)
+		go c.tsCancelLoop(d, w)
+	}
+	return d
+}
+
 func (c *client) ConfigClient() ConfigClient {
 	return &configClient{c.baseClient}
 }
@@ -160,7 +242,7 @@ type deadline struct {
 	cancel context.CancelFunc
 }
 
-func (c *client) tsCancelLoop() {
+func (c *client) tsCancelLoop(d *tsoDispatcher, w *tsoWorker) {
 	defer c.wg.Done()
 
 	ctx, cancel := context.WithCancel(c.ctx)
@@ -168,12 +250,12 @@ func (c *client) tsCancelLoop() {
 
 	for {
 		select {
-		case d := <-c.tsDeadlineCh:
+		case dl := <-w.tsDeadlineCh:
 			select {
-			case <-d.timer:
-				log.Error("tso request is canceled due to timeout")
-				d.cancel()
-			case <-d.done:
+			case <-dl.timer:
+				log.Error("tso request is canceled due to timeout", zap.String("dc-location", d.dcLocation))
+				dl.cancel()
+			case <-dl.done:
 			case <-ctx.Done():
 				return
 			}
@@ -183,7 +265,7 @@ func (c *client) tsCancelLoop() {
 	}
 }
 
-func (c *client) tsLoop() {
+func (c *client) tsLoop(d *tsoDispatcher, w *tsoWorker) {
 	defer c.wg.Done()
 
 	loopCtx, loopCancel := context.WithCancel(c.ctx)
@@ -193,6 +275,11 @@ func (c *client) tsLoop() {
 	var opts []opentracing.StartSpanOption
 	var stream pdpb.PD_TsoClient
 	var cancel context.CancelFunc
+	defer func() {
+		if stream != nil {
+			atomic.AddInt32(&d.streams, -1)
+		}
+	}()
 
 	for {
 		var err error
@@ -200,18 +287,26 @@ func (c *client) tsLoop() {
 		if stream == nil {
 			var ctx context.Context
 			ctx, cancel = context.WithCancel(loopCtx)
-			stream, err = c.leaderClient().Tso(ctx)
+			if d.dcLocation == "" {
+				stream, err = c.leaderClient().Tso(ctx)
+			} else {
+				stream, err = c.createLocalTSOStream(ctx, d, w)
+			}
 			if err != nil {
+				stream = nil
 				select {
 				case <-loopCtx.Done():
 					cancel()
 					return
 				default:
 				}
-				log.Error("[pd] create tso stream error", zap.Error(err))
+				log.Error("[pd] create tso stream error", zap.String("dc-location", d.dcLocation), zap.Error(err))
 				c.ScheduleCheckLeader()
 				cancel()
-				c.revokeTSORequest(errors.WithStack(err))
+				// Leave the requests to the other workers if they have streams.
+				if atomic.LoadInt32(&d.streams) == 0 {
+					d.revokeTSORequest(errors.WithStack(err))
+				}
 				select {
 				case <-time.After(time.Second):
 				case <-loopCtx.Done():
@@ -219,14 +314,18 @@ func (c *client) tsLoop() {
 				}
 				continue
 			}
+			atomic.AddInt32(&d.streams, 1)
 		}
 
 		select {
-		case first := <-c.tsoRequests:
+		case first := <-d.tsoRequests:
 			requests = append(requests, first)
-			pending := len(c.tsoRequests)
+			pending := len(d.tsoRequests)
 			for i := 0; i < pending; i++ {
-				requests = append(requests, <-c.tsoRequests)
+				requests = append(requests, <-d.tsoRequests)
+			}
+			if c.tsoMaxBatchWait > 0 {
+				requests = c.waitTSORequests(loopCtx, d, requests)
 			}
 			done := make(chan struct{})
 			dl := deadline{
@@ -235,13 +334,13 @@ func (c *client) tsLoop() {
 				cancel: cancel,
 			}
 			select {
-			case c.tsDeadlineCh <- dl:
+			case w.tsDeadlineCh <- dl:
 			case <-loopCtx.Done():
 				cancel()
 				return
 			}
 			opts = extractSpanReference(requests, opts[:0])
-			err = c.processTSORequests(stream, requests, opts)
+			err = c.processTSORequests(d, w, stream, requests, opts)
 			close(done)
 			requests = requests[:0]
 		case <-loopCtx.Done():
@@ -256,14 +355,55 @@ func (c *client) tsLoop() {
 				return
 			default:
 			}
-			log.Error("[pd] getTS error", zap.Error(err))
+			log.Error("[pd] getTS error", zap.String("dc-location", d.dcLocation), zap.Error(err))
 			c.ScheduleCheckLeader()
 			cancel()
 			stream, cancel = nil, nil
+			atomic.AddInt32(&d.streams, -1)
 		}
 	}
 }
 
+// waitTSORequests waits for more requests to grow the batch, for a fraction
+// of the recent RPC latency but no longer than the max batch wait.
+func (c *client) waitTSORequests(ctx context.Context, d *tsoDispatcher, requests []*tsoRequest) []*tsoRequest {
+	wait := time.Duration(atomic.LoadInt64(&d.rpcLatency)) / tsoBatchWaitDivisor
+	if wait > c.tsoMaxBatchWait {
+		wait = c.tsoMaxBatchWait
+	}
+	if wait <= 0 || len(requests) >= maxMergeTSORequests {
+		return requests
+	}
+
+	start := time.Now()
+	defer func() { requestDurationTSOBatchWait.Observe(time.Since(start).Seconds()) }()
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for len(requests) < maxMergeTSORequests {
+		select {
+		case req := <-d.tsoRequests:
+			requests = append(requests, req)
+		case <-timer.C:
+			return requests
+		case <-ctx.Done():
+			return requests
+		}
+	}
+	return requests
+}
+
+// updateRPCLatency updates the moving average of the TSO RPC latency. The
+// updates of the concurrent streams may overwrite each other, which is fine
+// for an estimation.
+func (d *tsoDispatcher) updateRPCLatency(latency time.Duration) {
+	old := atomic.LoadInt64(&d.rpcLatency)
+	if old == 0 {
+		atomic.StoreInt64(&d.rpcLatency, int64(latency))
+		return
+	}
+	atomic.StoreInt64(&d.rpcLatency, (old*tsoLatencySmoothing+int64(latency))/(tsoLatencySmoothing+1))
+}
+
 func extractSpanReference(requests []*tsoRequest, opts []opentracing.StartSpanOption) []opentracing.StartSpanOption {
 	for _, req := range requests {
 		if span := opentracing.SpanFromContext(req.ctx); span != nil {
@@ -273,7 +413,46 @@ func extractSpanReference(requests []*tsoRequest, opts []opentracing.StartSpanOp
 	return opts
 }
 
-func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoRequest, opts []opentracing.StartSpanOption) error {
+// createLocalTSOStream creates a stream to the local allocator leader of the
+// data center. The leader is found by asking the members for one timestamp,
+// starting from the last known leader.
+func (c *client) createLocalTSOStream(ctx context.Context, d *tsoDispatcher, w *tsoWorker) (pdpb.PD_TsoClient, error) {
+	c.connMu.RLock()
+	urls := append([]string{d.localAddr}, c.urls...)
+	c.connMu.RUnlock()
+
+	ctx = grpcutil.WithDCLocation(ctx, d.dcLocation)
+	err := errors.Errorf("no member in %s", d.dcLocation)
+	for _, u := range urls {
+		if u == "" {
+			continue
+		}
+		var cc *grpc.ClientConn
+		if cc, err = c.getOrCreateGRPCConn(u); err != nil {
+			continue
+		}
+		var stream pdpb.PD_TsoClient
+		if stream, err = pdpb.NewPDClient(cc).Tso(ctx); err != nil {
+			continue
+		}
+		if err = stream.Send(&pdpb.TsoRequest{Header: c.requestHeader(), Count: 1}); err != nil {
+			continue
+		}
+		var resp *pdpb.TsoResponse
+		if resp, err = stream.Recv(); err != nil {
+			continue
+		}
+		if d.localAddr != u {
+			log.Info("[pd] switch local tso allocator", zap.String("dc-location", d.dcLocation), zap.String("new-leader", u), zap.String("old-leader", d.localAddr))
+			d.localAddr = u
+		}
+		w.lastPhysical, w.lastLogical = resp.GetTimestamp().GetPhysical(), resp.GetTimestamp().GetLogical()
+		return stream, nil
+	}
+	return nil, errors.WithStack(err)
+}
+
+func (c *client) processTSORequests(d *tsoDispatcher, w *tsoWorker, stream pdpb.PD_TsoClient, requests []*tsoRequest, opts []opentracing.StartSpanOption) error {
 	if len(opts) > 0 {
 		span := opentracing.StartSpan("pdclient.processTSORequests", opts...)
 		defer span.Finish()
@@ -296,7 +475,9 @@ func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoReq
 		c.finishTSORequest(requests, 0, 0, err)
 		return err
 	}
-	requestDurationTSO.Observe(time.Since(start).Seconds())
+	latency := time.Since(start)
+	requestDurationTSO.Observe(latency.Seconds())
+	d.updateRPCLatency(latency)
 	tsoBatchSize.Observe(float64(count))
 
 	if resp.GetCount() != uint32(len(requests)) {
@@ -308,12 +489,12 @@ func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoReq
 	physical, logical := resp.GetTimestamp().GetPhysical(), resp.GetTimestamp().GetLogical()
 	// Server returns the highest ts.
 	logical -= int64(resp.GetCount() - 1)
-	if tsLessEqual(physical, logical, c.lastPhysical, c.lastLogical) {
+	if tsLessEqual(physical, logical, w.lastPhysical, w.lastLogical) {
 		panic(errors.Errorf("timestamp fallback, newly acquired ts (%d,%d) is less or equal to last one (%d, %d)",
-			physical, logical, c.lastLogical, c.lastLogical))
+			physical, logical, w.lastPhysical, w.lastLogical))
 	}
-	c.lastPhysical = physical
-	c.lastLogical = logical + int64(len(requests)) - 1
+	w.lastPhysical = physical
+	w.lastLogical = logical + int64(len(requests)) - 1
 	c.finishTSORequest(requests, physical, logical, nil)
 	return nil
 }
@@ -335,10 +516,10 @@ func (c *client) finishTSORequest(requests []*tsoRequest, physical, firstLogical
 	}
 }
 
-func (c *client) revokeTSORequest(err error) {
-	n := len(c.tsoRequests)
+func (d *tsoDispatcher) revokeTSORequest(err error) {
+	n := len(d.tsoRequests)
 	for i := 0; i < n; i++ {
-		req := <-c.tsoRequests
+		req := <-d.tsoRequests
 		req.done <- err
 	}
 }
@@ -347,7 +528,11 @@ func (c *client) Close() {
 	c.cancel()
 	c.wg.Wait()
 
-	c.revokeTSORequest(errors.WithStack(errClosing))
+	c.tsoDispatchers.RLock()
+	for _, d := range c.tsoDispatchers.m {
+		d.revokeTSORequest(errors.WithStack(errClosing))
+	}
+	c.tsoDispatchers.RUnlock()
 
 	c.connMu.Lock()
 	defer c.connMu.Unlock()
@@ -366,6 +551,33 @@ func (c *client) leaderClient() pdpb.PDClient {
 	return pdpb.NewPDClient(c.connMu.clientConns[c.connMu.leader])
 }
 
+// followerRead serves a read by a random follower if the follower read is
+// enabled, where the read reports whether the follower has the data. It
+// returns false if the read should fall back to the leader.
+func (c *client) followerRead(ctx context.Context, read func(ctx context.Context, cli pdpb.PDClient) bool) bool {
+	if c.followerReadMaxStaleness <= 0 {
+		return false
+	}
+	addr := c.getFollowerAddr()
+	if addr == "" {
+		return false
+	}
+	cc, err := c.getOrCreateGRPCConn(addr)
+	if err != nil {
+		log.Warn("[pd] failed to connect follower", zap.String("follower", addr), zap.Error(err))
+		followerReadCounterFallback.Inc()
+		return false
+	}
+	ctx, cancel := context.WithTimeout(grpcutil.WithFollowerHandle(ctx, c.followerReadMaxStaleness), followerReadTimeout)
+	defer cancel()
+	if !read(ctx, pdpb.NewPDClient(cc)) {
+		followerReadCounterFallback.Inc()
+		return false
+	}
+	followerReadCounterServed.Inc()
+	return true
+}
+
 var tsoReqPool = sync.Pool{
 	New: func() interface{} {
 		return &tsoRequest{
@@ -374,7 +586,13 @@ var tsoReqPool = sync.Pool{
 	},
 }
 
-func (c *client) GetTSAsync(ctx context.Context) TSFuture {
+func (c *client) GetTSAsync(ctx context.Context, opts ...GetTSOption) TSFuture {
+	// Applies options
+	options := &GetTSOp{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		span = opentracing.StartSpan("GetTSAsync", opentracing.ChildOf(span.Context()))
 		ctx = opentracing.ContextWithSpan(ctx, span)
@@ -384,7 +602,7 @@ func (c *client) GetTSAsync(ctx context.Context) TSFuture {
 	req.ctx = ctx
 	req.physical = 0
 	req.logical = 0
-	c.tsoRequests <- req
+	c.getTSODispatcher(options.dcLocation).tsoRequests <- req
 
 	return req
 }
@@ -418,8 +636,8 @@ func (req *tsoRequest) Wait() (physical int64, logical int64, err error) {
 	}
 }
 
-func (c *client) GetTS(ctx context.Context) (physical int64, logical int64, err error) {
-	resp := c.GetTSAsync(ctx)
+func (c *client) GetTS(ctx context.Context, opts ...GetTSOption) (physical int64, logical int64, err error) {
+	resp := c.GetTSAsync(ctx, opts...)
 	return resp.Wait()
 }
 
@@ -432,10 +650,18 @@ func (c *client) GetRegion(ctx context.Context, key []byte) (*metapb.Region, *me
 	defer func() { cmdDurationGetRegion.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetRegion(ctx, &pdpb.GetRegionRequest{
+	req := &pdpb.GetRegionRequest{
 		Header:    c.requestHeader(),
 		RegionKey: key,
-	})
+	}
+	var resp *pdpb.GetRegionResponse
+	var err error
+	if !c.followerRead(ctx, func(ctx context.Context, cli pdpb.PDClient) bool {
+		resp, err = cli.GetRegion(ctx, req)
+		return err == nil && resp.GetRegion() != nil
+	}) {
+		resp, err = c.leaderClient().GetRegion(ctx, req)
+	}
 	cancel()
 
 	if err != nil {
@@ -455,10 +681,18 @@ func (c *client) GetPrevRegion(ctx context.Context, key []byte) (*metapb.Region,
 	defer func() { cmdDurationGetPrevRegion.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetPrevRegion(ctx, &pdpb.GetRegionRequest{
+	req := &pdpb.GetRegionRequest{
 		Header:    c.requestHeader(),
 		RegionKey: key,
-	})
+	}
+	var resp *pdpb.GetRegionResponse
+	var err error
+	if !c.followerRead(ctx, func(ctx context.Context, cli pdpb.PDClient) bool {
+		resp, err = cli.GetPrevRegion(ctx, req)
+		return err == nil && resp.GetRegion() != nil
+	}) {
+		resp, err = c.leaderClient().GetPrevRegion(ctx, req)
+	}
 	cancel()
 
 	if err != nil {
@@ -478,10 +712,18 @@ func (c *client) GetRegionByID(ctx context.Context, regionID uint64) (*metapb.Re
 	defer func() { cmdDurationGetRegionByID.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetRegionByID(ctx, &pdpb.GetRegionByIDRequest{
+	req := &pdpb.GetRegionByIDRequest{
 		Header:   c.requestHeader(),
 		RegionId: regionID,
-	})
+	}
+	var resp *pdpb.GetRegionResponse
+	var err error
+	if !c.followerRead(ctx, func(ctx context.Context, cli pdpb.PDClient) bool {
+		resp, err = cli.GetRegionByID(ctx, req)
+		return err == nil && resp.GetRegion() != nil
+	}) {
+		resp, err = c.leaderClient().GetRegionByID(ctx, req)
+	}
 	cancel()
 
 	if err != nil {
@@ -507,12 +749,20 @@ func (c *client) ScanRegions(ctx context.Context, key, endKey []byte, limit int)
 		defer cancel()
 	}
 
-	resp, err := c.leaderClient().ScanRegions(scanCtx, &pdpb.ScanRegionsRequest{
+	req := &pdpb.ScanRegionsRequest{
 		Header:   c.requestHeader(),
 		StartKey: key,
 		EndKey:   endKey,
 		Limit:    int32(limit),
-	})
+	}
+	var resp *pdpb.ScanRegionsResponse
+	var err error
+	if !c.followerRead(scanCtx, func(ctx context.Context, cli pdpb.PDClient) bool {
+		resp, err = cli.ScanRegions(ctx, req)
+		return err == nil && len(resp.GetRegions()) > 0
+	}) {
+		resp, err = c.leaderClient().ScanRegions(scanCtx, req)
+	}
 	if err != nil {
 		cmdFailedDurationScanRegions.Observe(time.Since(start).Seconds())
 		c.ScheduleCheckLeader()
@@ -530,10 +780,18 @@ func (c *client) GetStore(ctx context.Context, storeID uint64) (*metapb.Store, e
 	defer func() { cmdDurationGetStore.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetStore(ctx, &pdpb.GetStoreRequest{
+	req := &pdpb.GetStoreRequest{
 		Header:  c.requestHeader(),
 		StoreId: storeID,
-	})
+	}
+	var resp *pdpb.GetStoreResponse
+	var err error
+	if !c.followerRead(ctx, func(ctx context.Context, cli pdpb.PDClient) bool {
+		resp, err = cli.GetStore(ctx, req)
+		return err == nil && resp.GetStore() != nil
+	}) {
+		resp, err = c.leaderClient().GetStore(ctx, req)
+	}
 	cancel()
 
 	if err != nil {
@@ -52,6 +52,14 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(1, 2, 13),
 		})
 
+	followerReadCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "follower_read_total",
+			Help:      "Counter of the reads sent to followers.",
+		}, []string{"result"})
+
 	configCmdDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "config_client",
@@ -95,6 +103,10 @@ var (
 	cmdFailedDurationGetAllStores      = cmdFailedDuration.WithLabelValues("get_all_stores")
 	cmdFailedDurationUpdateGCSafePoint = cmdFailedDuration.WithLabelValues("update_gc_safe_point")
 	requestDurationTSO                 = requestDuration.WithLabelValues("tso")
+	requestDurationTSOBatchWait        = requestDuration.WithLabelValues("tso_batch_wait")
+
+	followerReadCounterServed   = followerReadCounter.WithLabelValues("served")
+	followerReadCounterFallback = followerReadCounter.WithLabelValues("fallback")
 
 	// config
 	configCmdDurationCreate = configCmdDuration.WithLabelValues("create")
@@ -113,6 +125,7 @@ func init() {
 	prometheus.MustRegister(cmdFailedDuration)
 	prometheus.MustRegister(requestDuration)
 	prometheus.MustRegister(tsoBatchSize)
+	prometheus.MustRegister(followerReadCounter)
 
 	// config
 	prometheus.MustRegister(configCmdDuration)
@@ -0,0 +1,167 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package extscheduler defines the protocol between PD and the schedulers
+// running out of the PD process.
+//
+// PD is the client of the protocol. Every 10 seconds, PD calls the client
+// streaming method Schedule of the external scheduler, streams the snapshot
+// of the cluster and receives the proposed operators. The messages
+// are encoded in JSON, so an external scheduler can be written in any
+// language with a gRPC implementation.
+package extscheduler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+const (
+	// CodecName is the content subtype of the messages.
+	CodecName   = "json"
+	serviceName = "extscheduler.ExternalScheduler"
+)
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
+
+// Scheduler is implemented by an external scheduler.
+type Scheduler interface {
+	// Schedule proposes operators according to the snapshot.
+	Schedule(ctx context.Context, snapshot *Snapshot) ([]*OperatorProposal, error)
+}
+
+type scheduleServer struct {
+	grpc.ServerStream
+}
+
+func (s *scheduleServer) Recv() (*SnapshotChunk, error) {
+	chunk := &SnapshotChunk{}
+	if err := s.ServerStream.RecvMsg(chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+func (s *scheduleServer) SendAndClose(resp *ScheduleResponse) error {
+	return s.ServerStream.SendMsg(resp)
+}
+
+func scheduleHandler(srv interface{}, stream grpc.ServerStream) error {
+	s := &scheduleServer{ServerStream: stream}
+	snapshot := &Snapshot{}
+	for {
+		chunk, err := s.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if chunk.Header != nil {
+			snapshot.SnapshotHeader = *chunk.Header
+		}
+		snapshot.Regions = append(snapshot.Regions, chunk.Regions...)
+	}
+	ops, err := srv.(Scheduler).Schedule(stream.Context(), snapshot)
+	if err != nil {
+		return err
+	}
+	return s.SendAndClose(&ScheduleResponse{Operators: ops})
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Scheduler)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Schedule",
+			Handler:       scheduleHandler,
+			ClientStreams: true,
+		},
+	},
+}
+
+// RegisterScheduler registers the external scheduler to the gRPC server.
+func RegisterScheduler(s *grpc.Server, scheduler Scheduler) {
+	s.RegisterService(&serviceDesc, scheduler)
+}
+
+// ScheduleClient is the client side stream of the Schedule method.
+type ScheduleClient interface {
+	Send(*SnapshotChunk) error
+	CloseAndRecv() (*ScheduleResponse, error)
+}
+
+type scheduleClient struct {
+	grpc.ClientStream
+}
+
+func (c *scheduleClient) Send(chunk *SnapshotChunk) error {
+	return c.ClientStream.SendMsg(chunk)
+}
+
+func (c *scheduleClient) CloseAndRecv() (*ScheduleResponse, error) {
+	if err := c.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	resp := &ScheduleResponse{}
+	if err := c.ClientStream.RecvMsg(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Client calls an external scheduler.
+type Client struct {
+	cc *grpc.ClientConn
+}
+
+// NewClient creates a client with the connection to an external scheduler.
+func NewClient(cc *grpc.ClientConn) *Client {
+	return &Client{cc: cc}
+}
+
+// Schedule starts a scheduling round.
+func (c *Client) Schedule(ctx context.Context) (ScheduleClient, error) {
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/Schedule", grpc.CallContentSubtype(CodecName))
+	if err != nil {
+		return nil, err
+	}
+	return &scheduleClient{ClientStream: stream}, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.cc.Close()
+}
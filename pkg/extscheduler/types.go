@@ -0,0 +1,103 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package extscheduler
+
+import (
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+)
+
+// SnapshotChunk is a part of the cluster snapshot which PD streams to an
+// external scheduler. The first chunk of a round carries the header, and the
+// following chunks carry the regions in key order.
+type SnapshotChunk struct {
+	Header  *SnapshotHeader   `json:"header,omitempty"`
+	Regions []*RegionSnapshot `json:"regions,omitempty"`
+}
+
+// SnapshotHeader describes the cluster except the regions.
+type SnapshotHeader struct {
+	// Scheduler is the name of the scheduler in PD.
+	Scheduler      string           `json:"scheduler"`
+	MaxReplicas    int              `json:"max-replicas"`
+	LocationLabels []string         `json:"location-labels"`
+	Stores         []*StoreSnapshot `json:"stores"`
+}
+
+// StoreSnapshot is the state of a store.
+type StoreSnapshot struct {
+	Store       *metapb.Store    `json:"store"`
+	Stats       *pdpb.StoreStats `json:"stats"`
+	LeaderCount int              `json:"leader-count"`
+	RegionCount int              `json:"region-count"`
+	LeaderSize  int64            `json:"leader-size"`
+	RegionSize  int64            `json:"region-size"`
+	// Blocked means PD does not schedule regions to the store.
+	Blocked bool `json:"blocked"`
+}
+
+// RegionSnapshot is the state of a region.
+type RegionSnapshot struct {
+	Region          *metapb.Region    `json:"region"`
+	Leader          *metapb.Peer      `json:"leader"`
+	DownPeers       []*pdpb.PeerStats `json:"down-peers,omitempty"`
+	PendingPeers    []*metapb.Peer    `json:"pending-peers,omitempty"`
+	ApproximateSize int64             `json:"approximate-size"`
+	ApproximateKeys int64             `json:"approximate-keys"`
+	WrittenBytes    uint64            `json:"written-bytes"`
+	WrittenKeys     uint64            `json:"written-keys"`
+	ReadBytes       uint64            `json:"read-bytes"`
+	ReadKeys        uint64            `json:"read-keys"`
+}
+
+// Snapshot is the whole cluster snapshot of a scheduling round.
+type Snapshot struct {
+	SnapshotHeader
+	Regions []*RegionSnapshot `json:"regions"`
+}
+
+// ProposalKind is the kind of an operator proposal.
+type ProposalKind string
+
+// Kinds of the operator proposals.
+const (
+	// TransferLeader transfers the leader to the target store.
+	TransferLeader ProposalKind = "transfer-leader"
+	// MovePeer moves the peer on the source store to the target store.
+	MovePeer ProposalKind = "move-peer"
+	// AddPeer adds a peer on the target store.
+	AddPeer ProposalKind = "add-peer"
+	// RemovePeer removes the peer on the source store.
+	RemovePeer ProposalKind = "remove-peer"
+)
+
+// OperatorProposal is an operator proposed by an external scheduler. PD
+// rejects the proposal if the epoch of the region has changed since the
+// snapshot was taken.
+type OperatorProposal struct {
+	Desc          string              `json:"desc"`
+	RegionID      uint64              `json:"region-id"`
+	RegionEpoch   *metapb.RegionEpoch `json:"region-epoch"`
+	Kind          ProposalKind        `json:"kind"`
+	SourceStoreID uint64              `json:"source-store-id,omitempty"`
+	TargetStoreID uint64              `json:"target-store-id,omitempty"`
+	// HighPriority makes the operator preempt the running operators of
+	// lower priority.
+	HighPriority bool `json:"high-priority,omitempty"`
+}
+
+// ScheduleResponse is the result of a scheduling round.
+type ScheduleResponse struct {
+	Operators []*OperatorProposal `json:"operators"`
+}
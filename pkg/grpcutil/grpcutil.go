@@ -17,13 +17,24 @@ import (
 	"context"
 	"crypto/tls"
 	"net/url"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/pkg/transport"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 )
 
+// FollowerHandleMetadataKey is the key of the gRPC metadata which allows a
+// read request to be served by a follower. The value is the max staleness of
+// the data served by the follower.
+const FollowerHandleMetadataKey = "pd-follower-handle"
+
+// DCLocationMetadataKey is the key of the gRPC metadata which asks for the
+// local timestamps of the data center in the value.
+const DCLocationMetadataKey = "pd-dc-location"
+
 // SecurityConfig is the configuration for supporting tls.
 type SecurityConfig struct {
 	// CAPath is the path of file that contains list of trusted SSL CAs. if set, following four settings shouldn't be empty
@@ -80,3 +91,47 @@ func GetClientConn(ctx context.Context, addr string, tlsCfg *tls.Config, do ...g
 	}
 	return cc, nil
 }
+
+// WithFollowerHandle returns a context which allows the request to be served
+// by a follower whose data is not staler than maxStaleness.
+func WithFollowerHandle(ctx context.Context, maxStaleness time.Duration) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, FollowerHandleMetadataKey, maxStaleness.String())
+}
+
+// GetFollowerHandle returns the max staleness if the incoming request allows
+// to be served by a follower.
+func GetFollowerHandle(ctx context.Context) (time.Duration, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0, false
+	}
+	values := md.Get(FollowerHandleMetadataKey)
+	if len(values) == 0 {
+		return 0, false
+	}
+	maxStaleness, err := time.ParseDuration(values[0])
+	if err != nil || maxStaleness <= 0 {
+		return 0, false
+	}
+	return maxStaleness, true
+}
+
+// WithDCLocation returns a context which asks for the local timestamps of the
+// data center.
+func WithDCLocation(ctx context.Context, dcLocation string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, DCLocationMetadataKey, dcLocation)
+}
+
+// GetDCLocation returns the data center whose local timestamps the incoming
+// request asks for, or an empty string for the global timestamps.
+func GetDCLocation(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(DCLocationMetadataKey)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/pd/v4/server/statistics"
 	"go.uber.org/zap"
@@ -38,13 +39,15 @@ type Cluster struct {
 	*placement.RuleManager
 	*statistics.HotCache
 	*statistics.StoresStats
-	ID uint64
+	ID            uint64
+	RegionLabeler *labeler.RegionLabeler
 }
 
 // NewCluster creates a new Cluster
 func NewCluster(opt *mockoption.ScheduleOptions) *Cluster {
 	ruleManager := placement.NewRuleManager(core.NewStorage(kv.NewMemoryKV()))
 	ruleManager.Initialize(opt.MaxReplicas, opt.GetLocationLabels())
+	regionLabeler, _ := labeler.NewRegionLabeler(core.NewStorage(kv.NewMemoryKV()))
 	return &Cluster{
 		BasicCluster:    core.NewBasicCluster(),
 		IDAllocator:     mockid.NewIDAllocator(),
@@ -52,6 +55,7 @@ func NewCluster(opt *mockoption.ScheduleOptions) *Cluster {
 		RuleManager:     ruleManager,
 		HotCache:        statistics.NewHotCache(),
 		StoresStats:     statistics.NewStoresStats(),
+		RegionLabeler:   regionLabeler,
 	}
 }
 
@@ -130,6 +134,14 @@ func (mc *Cluster) FitRegion(region *core.RegionInfo) *placement.RegionFit {
 	return mc.RuleManager.FitRegion(mc.BasicCluster, region)
 }
 
+// ObserveRuleFit mocks method.
+func (mc *Cluster) ObserveRuleFit(region *core.RegionInfo, fit *placement.RegionFit) {}
+
+// GetRegionLabeler returns the region labeler.
+func (mc *Cluster) GetRegionLabeler() *labeler.RegionLabeler {
+	return mc.RegionLabeler
+}
+
 // SetStoreUp sets store state to be up.
 func (mc *Cluster) SetStoreUp(storeID uint64) {
 	store := mc.GetStore(storeID)
@@ -388,6 +400,16 @@ func (mc *Cluster) UpdateStorageRatio(storeID uint64, usedRatio, availableRatio
 	mc.PutStore(newStore)
 }
 
+// UpdateStorageCapacity updates store capacity and keeps the used space.
+func (mc *Cluster) UpdateStorageCapacity(storeID uint64, capacity uint64) {
+	store := mc.GetStore(storeID)
+	newStats := proto.Clone(store.GetStoreStats()).(*pdpb.StoreStats)
+	newStats.Available = capacity - (newStats.Capacity - newStats.Available)
+	newStats.Capacity = capacity
+	newStore := store.Clone(core.SetStoreStats(newStats))
+	mc.PutStore(newStore)
+}
+
 // UpdateStorageWrittenBytes updates store written bytes.
 func (mc *Cluster) UpdateStorageWrittenBytes(storeID uint64, bytesWritten uint64) {
 	store := mc.GetStore(storeID)
@@ -440,6 +462,32 @@ func (mc *Cluster) UpdateStorageReadKeys(storeID uint64, keysRead uint64) {
 	mc.PutStore(newStore)
 }
 
+// UpdateStorageCPUUsage updates store cpu usage.
+func (mc *Cluster) UpdateStorageCPUUsage(storeID uint64, cpuUsage uint64) {
+	store := mc.GetStore(storeID)
+	newStats := proto.Clone(store.GetStoreStats()).(*pdpb.StoreStats)
+	newStats.CpuUsages = []*pdpb.RecordPair{{Key: "cpu", Value: cpuUsage}}
+	now := time.Now().Second()
+	interval := &pdpb.TimeInterval{StartTimestamp: uint64(now - statistics.StoreHeartBeatReportInterval), EndTimestamp: uint64(now)}
+	newStats.Interval = interval
+	newStore := store.Clone(core.SetStoreStats(newStats))
+	mc.Set(storeID, newStats)
+	mc.PutStore(newStore)
+}
+
+// UpdateStorageDiskWriteRate updates store disk write rate.
+func (mc *Cluster) UpdateStorageDiskWriteRate(storeID uint64, writeRate uint64) {
+	store := mc.GetStore(storeID)
+	newStats := proto.Clone(store.GetStoreStats()).(*pdpb.StoreStats)
+	newStats.WriteIoRates = []*pdpb.RecordPair{{Key: "disk", Value: writeRate}}
+	now := time.Now().Second()
+	interval := &pdpb.TimeInterval{StartTimestamp: uint64(now - statistics.StoreHeartBeatReportInterval), EndTimestamp: uint64(now)}
+	newStats.Interval = interval
+	newStore := store.Clone(core.SetStoreStats(newStats))
+	mc.Set(storeID, newStats)
+	mc.PutStore(newStore)
+}
+
 // UpdateStoreStatus updates store status.
 func (mc *Cluster) UpdateStoreStatus(id uint64) {
 	leaderCount := mc.Regions.GetStoreLeaderCount(id)
@@ -492,6 +540,11 @@ func (mc *Cluster) GetMergeScheduleLimit() uint64 {
 	return mc.ScheduleOptions.GetMergeScheduleLimit()
 }
 
+// GetSplitScheduleLimit mocks method.
+func (mc *Cluster) GetSplitScheduleLimit() uint64 {
+	return mc.ScheduleOptions.GetSplitScheduleLimit()
+}
+
 // GetHotRegionScheduleLimit mocks method.
 func (mc *Cluster) GetHotRegionScheduleLimit() uint64 {
 	return mc.ScheduleOptions.GetHotRegionScheduleLimit()
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server/core"
 )
 
@@ -28,6 +29,7 @@ const (
 	defaultMaxMergeRegionKeys          = 0
 	defaultSplitMergeInterval          = 0
 	defaultMaxStoreDownTime            = 30 * time.Minute
+	defaultMaxOperatorStepWaitTime     = 10 * time.Minute
 	defaultLeaderScheduleLimit         = 4
 	defaultRegionScheduleLimit         = 64
 	defaultReplicaScheduleLimit        = 64
@@ -43,6 +45,9 @@ const (
 	defaultLeaderSchedulePolicy        = "count"
 	defaultEnablePlacementRules        = false
 	defaultKeyType                     = "table"
+	defaultRegionWeightMode            = "manual"
+	defaultOperatorHistoryRetention    = 6 * time.Hour
+	defaultMaxOperatorHistoryRecords   = 10000
 )
 
 // ScheduleOptions is a mock of ScheduleOptions
@@ -52,6 +57,7 @@ type ScheduleOptions struct {
 	LeaderScheduleLimit          uint64
 	ReplicaScheduleLimit         uint64
 	MergeScheduleLimit           uint64
+	SplitScheduleLimit           uint64
 	HotRegionScheduleLimit       uint64
 	StoreBalanceRate             float64
 	MaxSnapshotCount             uint64
@@ -64,6 +70,7 @@ type ScheduleOptions struct {
 	EnableCrossTableMerge        bool
 	KeyType                      string
 	MaxStoreDownTime             time.Duration
+	MaxOperatorStepWaitTime      time.Duration
 	MaxReplicas                  int
 	LocationLabels               []string
 	StrictlyMatchLabel           bool
@@ -78,12 +85,18 @@ type ScheduleOptions struct {
 	EnableLocationReplacement    bool
 	EnablePlacementRules         bool
 	EnableDebugMetrics           bool
+	EnableOperatorHistory        bool
+	OperatorHistoryRetention     time.Duration
+	MaxOperatorHistoryRecords    uint64
 	DisableRemoveDownReplica     bool
 	DisableReplaceOfflineReplica bool
 	DisableMakeUpReplica         bool
 	DisableRemoveExtraReplica    bool
 	DisableLocationReplacement   bool
 	LeaderSchedulePolicy         string
+	RegionWeightMode             string
+	StoreClassWeights            map[string]float64
+	ScheduleWindows              map[string][]string
 	LabelProperties              map[string][]*metapb.StoreLabel
 }
 
@@ -102,6 +115,7 @@ func NewScheduleOptions() *ScheduleOptions {
 	mso.SchedulerMaxWaitingOperator = defaultSchedulerMaxWaitingOperator
 	mso.SplitMergeInterval = defaultSplitMergeInterval
 	mso.MaxStoreDownTime = defaultMaxStoreDownTime
+	mso.MaxOperatorStepWaitTime = defaultMaxOperatorStepWaitTime
 	mso.MaxReplicas = defaultMaxReplicas
 	mso.StrictlyMatchLabel = defaultStrictlyMatchLabel
 	mso.EnablePlacementRules = defaultEnablePlacementRules
@@ -117,6 +131,9 @@ func NewScheduleOptions() *ScheduleOptions {
 	mso.EnableLocationReplacement = true
 	mso.LeaderSchedulePolicy = defaultLeaderSchedulePolicy
 	mso.KeyType = defaultKeyType
+	mso.RegionWeightMode = defaultRegionWeightMode
+	mso.OperatorHistoryRetention = defaultOperatorHistoryRetention
+	mso.MaxOperatorHistoryRecords = defaultMaxOperatorHistoryRecords
 	return mso
 }
 
@@ -135,6 +152,11 @@ func (mso *ScheduleOptions) GetReplicaScheduleLimit() uint64 {
 	return mso.ReplicaScheduleLimit
 }
 
+// GetSplitScheduleLimit mocks method
+func (mso *ScheduleOptions) GetSplitScheduleLimit() uint64 {
+	return mso.SplitScheduleLimit
+}
+
 // GetMergeScheduleLimit mocks method
 func (mso *ScheduleOptions) GetMergeScheduleLimit() uint64 {
 	return mso.MergeScheduleLimit
@@ -185,6 +207,11 @@ func (mso *ScheduleOptions) IsCrossTableMergeEnabled() bool {
 	return mso.EnableCrossTableMerge
 }
 
+// GetMaxOperatorStepWaitTime mocks method
+func (mso *ScheduleOptions) GetMaxOperatorStepWaitTime() time.Duration {
+	return mso.MaxOperatorStepWaitTime
+}
+
 // GetMaxStoreDownTime mocks method
 func (mso *ScheduleOptions) GetMaxStoreDownTime() time.Duration {
 	return mso.MaxStoreDownTime
@@ -270,11 +297,42 @@ func (mso *ScheduleOptions) IsDebugMetricsEnabled() bool {
 	return mso.EnableDebugMetrics
 }
 
+// IsOperatorHistoryEnabled mocks method
+func (mso *ScheduleOptions) IsOperatorHistoryEnabled() bool {
+	return mso.EnableOperatorHistory
+}
+
+// GetOperatorHistoryRetention mocks method
+func (mso *ScheduleOptions) GetOperatorHistoryRetention() time.Duration {
+	return mso.OperatorHistoryRetention
+}
+
+// GetMaxOperatorHistoryRecords mocks method
+func (mso *ScheduleOptions) GetMaxOperatorHistoryRecords() uint64 {
+	return mso.MaxOperatorHistoryRecords
+}
+
 // GetLeaderSchedulePolicy is to get leader schedule policy.
 func (mso *ScheduleOptions) GetLeaderSchedulePolicy() core.SchedulePolicy {
 	return core.StringToSchedulePolicy(mso.LeaderSchedulePolicy)
 }
 
+// GetRegionWeightMode mocks method
+func (mso *ScheduleOptions) GetRegionWeightMode() core.RegionWeightMode {
+	return core.StringToRegionWeightMode(mso.RegionWeightMode)
+}
+
+// GetStoreClassWeights mocks method
+func (mso *ScheduleOptions) GetStoreClassWeights() map[string]float64 {
+	return mso.StoreClassWeights
+}
+
+// IsInScheduleWindows mocks method
+func (mso *ScheduleOptions) IsInScheduleWindows(name string, t time.Time) bool {
+	windows, err := typeutil.ParseTimeWindows(mso.ScheduleWindows[name])
+	return err == nil && typeutil.IsInTimeWindows(windows, t)
+}
+
 // GetKeyType is to get key type.
 func (mso *ScheduleOptions) GetKeyType() core.KeyType {
 	return core.StringToKeyType(mso.KeyType)
@@ -0,0 +1,152 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeutil
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// TimeWindow is a period of the day on some days of the week, in which a
+// scheduler or a checker is allowed to run. It is written as
+// "[days ]HH:MM-HH:MM", for example "01:00-06:00", "Mon-Fri 01:00-06:00" or
+// "Sat,Sun 22:00-02:00". The days are every day if omitted. If the end is not
+// after the start, the window crosses midnight and the part after midnight
+// belongs to the day it starts.
+type TimeWindow struct {
+	days  [7]bool
+	start int // minutes since midnight
+	end   int
+}
+
+// ParseTimeWindow parses a time window.
+func ParseTimeWindow(s string) (*TimeWindow, error) {
+	w := &TimeWindow{}
+	fields := strings.Fields(s)
+	var period string
+	switch len(fields) {
+	case 1:
+		for i := range w.days {
+			w.days[i] = true
+		}
+		period = fields[0]
+	case 2:
+		if err := w.parseDays(fields[0]); err != nil {
+			return nil, err
+		}
+		period = fields[1]
+	default:
+		return nil, errors.Errorf("invalid time window %q", s)
+	}
+	bounds := strings.Split(period, "-")
+	if len(bounds) != 2 {
+		return nil, errors.Errorf("invalid period %q in time window %q", period, s)
+	}
+	var err error
+	if w.start, err = parseClock(bounds[0]); err != nil {
+		return nil, err
+	}
+	if w.end, err = parseClock(bounds[1]); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *TimeWindow) parseDays(s string) error {
+	for _, item := range strings.Split(s, ",") {
+		bounds := strings.Split(item, "-")
+		if len(bounds) > 2 {
+			return errors.Errorf("invalid days %q", s)
+		}
+		first, ok := weekdayNames[strings.ToLower(bounds[0])]
+		if !ok {
+			return errors.Errorf("invalid day %q", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdayNames[strings.ToLower(bounds[1])]; !ok {
+				return errors.Errorf("invalid day %q", bounds[1])
+			}
+		}
+		// A range such as "Fri-Mon" wraps around the weekend.
+		for d := first; ; d = (d + 1) % 7 {
+			w.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, errors.Errorf("invalid time %q, should be HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains returns if the time is in the window.
+func (w *TimeWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+	if w.start < w.end {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+	// The window crosses midnight.
+	if minute >= w.start {
+		return w.days[day]
+	}
+	return minute < w.end && w.days[(day+6)%7]
+}
+
+// ParseTimeWindows parses all the time windows.
+func ParseTimeWindows(windows []string) ([]*TimeWindow, error) {
+	ret := make([]*TimeWindow, 0, len(windows))
+	for _, s := range windows {
+		w, err := ParseTimeWindow(s)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, w)
+	}
+	return ret, nil
+}
+
+// IsInTimeWindows returns if the time is in any of the windows. No windows
+// means no restriction.
+func IsInTimeWindows(windows []*TimeWindow, t time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, w := range windows {
+		if w.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package typeutil
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+var _ = Suite(&testTimeWindowSuite{})
+
+type testTimeWindowSuite struct{}
+
+// 2020-06-01 is a Monday.
+func at(day int, hour int, minute int) time.Time {
+	return time.Date(2020, 6, day, hour, minute, 0, 0, time.Local)
+}
+
+func (s *testTimeWindowSuite) TestParse(c *C) {
+	for _, str := range []string{
+		"01:00-06:00",
+		"Mon-Fri 01:00-06:00",
+		"sat,sun 22:00-02:00",
+		"Fri-Mon,Wed 00:00-00:00",
+	} {
+		_, err := ParseTimeWindow(str)
+		c.Assert(err, IsNil, Commentf("window: %s", str))
+	}
+	for _, str := range []string{
+		"",
+		"01:00",
+		"01:00-24:00",
+		"Mon-Fri",
+		"Monday 01:00-06:00",
+		"Mon-Wed-Fri 01:00-06:00",
+		"Mon 01:00-06:00 extra",
+	} {
+		_, err := ParseTimeWindow(str)
+		c.Assert(err, NotNil, Commentf("window: %s", str))
+	}
+}
+
+func (s *testTimeWindowSuite) TestContains(c *C) {
+	w, err := ParseTimeWindow("Mon-Fri 01:00-06:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.Contains(at(1, 1, 0)), IsTrue)
+	c.Assert(w.Contains(at(1, 5, 59)), IsTrue)
+	c.Assert(w.Contains(at(1, 6, 0)), IsFalse)
+	c.Assert(w.Contains(at(1, 0, 59)), IsFalse)
+	c.Assert(w.Contains(at(5, 3, 0)), IsTrue)
+	c.Assert(w.Contains(at(6, 3, 0)), IsFalse)
+
+	// The window crosses midnight, 02:00 on Monday belongs to Sunday.
+	w, err = ParseTimeWindow("Sat,Sun 22:00-02:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.Contains(at(6, 23, 0)), IsTrue)
+	c.Assert(w.Contains(at(7, 1, 0)), IsTrue)
+	c.Assert(w.Contains(at(8, 1, 0)), IsTrue)
+	c.Assert(w.Contains(at(8, 2, 0)), IsFalse)
+	c.Assert(w.Contains(at(8, 23, 0)), IsFalse)
+	c.Assert(w.Contains(at(6, 1, 0)), IsFalse)
+
+	// The range of days wraps around the weekend.
+	w, err = ParseTimeWindow("Fri-Mon 00:00-00:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.Contains(at(7, 12, 0)), IsTrue)
+	c.Assert(w.Contains(at(1, 12, 0)), IsTrue)
+	c.Assert(w.Contains(at(3, 12, 0)), IsFalse)
+}
+
+func (s *testTimeWindowSuite) TestIsInTimeWindows(c *C) {
+	c.Assert(IsInTimeWindows(nil, at(1, 12, 0)), IsTrue)
+	windows, err := ParseTimeWindows([]string{"Mon-Fri 01:00-06:00", "Sat,Sun 00:00-00:00"})
+	c.Assert(err, IsNil)
+	c.Assert(windows, HasLen, 2)
+	c.Assert(IsInTimeWindows(windows, at(1, 3, 0)), IsTrue)
+	c.Assert(IsInTimeWindows(windows, at(1, 12, 0)), IsFalse)
+	c.Assert(IsInTimeWindows(windows, at(6, 12, 0)), IsTrue)
+	_, err = ParseTimeWindows([]string{"Mon-Fri 01:00-06:00", "25:00-26:00"})
+	c.Assert(err, NotNil)
+}
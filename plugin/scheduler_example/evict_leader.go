@@ -11,341 +11,130 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// The example is an external scheduler which transfers all leaders out of
+// the given stores. Run it and add it to PD by:
+//
+//	./evict-leader -addr 127.0.0.1:20190 -store-ids 1,2
+//	pd-ctl scheduler add external-scheduler user-evict-leader http://127.0.0.1:20190
 package main
 
 import (
-	"net/http"
-	"net/url"
+	"context"
+	"flag"
+	"math/rand"
+	"net"
 	"strconv"
-	"sync"
+	"strings"
 
-	"github.com/gorilla/mux"
+	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/log"
-	"github.com/pingcap/pd/v4/pkg/apiutil"
-	"github.com/pingcap/pd/v4/server/core"
-	"github.com/pingcap/pd/v4/server/schedule"
-	"github.com/pingcap/pd/v4/server/schedule/filter"
-	"github.com/pingcap/pd/v4/server/schedule/operator"
-	"github.com/pingcap/pd/v4/server/schedule/opt"
-	"github.com/pingcap/pd/v4/server/schedule/selector"
-	"github.com/pingcap/pd/v4/server/schedulers"
-	"github.com/pkg/errors"
-	"github.com/unrolled/render"
+	"github.com/pingcap/pd/v4/pkg/extscheduler"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 const (
-	// EvictLeaderName is evict leader scheduler name.
-	EvictLeaderName = "user-evict-leader-scheduler"
 	// EvictLeaderType is evict leader scheduler type.
-	EvictLeaderType        = "user-evict-leader"
-	noStoreInSchedulerInfo = "No store in user-evict-leader-scheduler-config"
+	EvictLeaderType = "user-evict-leader"
 )
 
-func init() {
-	schedule.RegisterSliceDecoderBuilder(EvictLeaderType, func(args []string) schedule.ConfigDecoder {
-		return func(v interface{}) error {
-			if len(args) != 1 {
-				return errors.New("should specify the store-id")
-			}
-			conf, ok := v.(*evictLeaderSchedulerConfig)
-			if !ok {
-				return errors.New("the config does not exist")
-			}
-
-			id, err := strconv.ParseUint(args[0], 10, 64)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			ranges, err := getKeyRanges(args[1:])
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			conf.StoreIDWitRanges[id] = ranges
-			return nil
-
-		}
-	})
-
-	schedule.RegisterScheduler(EvictLeaderType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
-		conf := &evictLeaderSchedulerConfig{StoreIDWitRanges: make(map[uint64][]core.KeyRange), storage: storage}
-		if err := decoder(conf); err != nil {
-			return nil, err
-		}
-		conf.cluster = opController.GetCluster()
-		return newEvictLeaderScheduler(opController, conf), nil
-	})
-}
-
-// SchedulerType returns the type of the scheduler
-// nolint
-func SchedulerType() string {
-	return EvictLeaderType
-}
-
-// SchedulerArgs returns the args for the scheduler
-// nolint
-func SchedulerArgs() []string {
-	args := []string{"1"}
-	return args
-}
-
-type evictLeaderSchedulerConfig struct {
-	mu               sync.RWMutex
-	storage          *core.Storage
-	StoreIDWitRanges map[uint64][]core.KeyRange `json:"store-id-ranges"`
-	cluster          opt.Cluster
-}
-
-func (conf *evictLeaderSchedulerConfig) BuildWithArgs(args []string) error {
-	if len(args) != 1 {
-		return errors.New("should specify the store-id")
-	}
-
-	id, err := strconv.ParseUint(args[0], 10, 64)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	ranges, err := getKeyRanges(args[1:])
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	conf.mu.Lock()
-	defer conf.mu.Unlock()
-	conf.StoreIDWitRanges[id] = ranges
-	return nil
-}
-
-func (conf *evictLeaderSchedulerConfig) Clone() *evictLeaderSchedulerConfig {
-	conf.mu.RLock()
-	defer conf.mu.RUnlock()
-	return &evictLeaderSchedulerConfig{
-		StoreIDWitRanges: conf.StoreIDWitRanges,
-	}
-}
-
-func (conf *evictLeaderSchedulerConfig) Persist() error {
-	name := conf.getScheduleName()
-	conf.mu.RLock()
-	defer conf.mu.RUnlock()
-	data, err := schedule.EncodeConfig(conf)
-	if err != nil {
-		return err
-	}
-	conf.storage.SaveScheduleConfig(name, data)
-	return nil
-}
-
-func (conf *evictLeaderSchedulerConfig) getScheduleName() string {
-	return EvictLeaderName
-}
-
-func (conf *evictLeaderSchedulerConfig) getRanges(id uint64) []string {
-	conf.mu.RLock()
-	defer conf.mu.RUnlock()
-	var res []string
-	for index := range conf.StoreIDWitRanges[id] {
-		res = append(res, (string)(conf.StoreIDWitRanges[id][index].StartKey))
-		res = append(res, (string)(conf.StoreIDWitRanges[id][index].EndKey))
-	}
-	return res
-}
+var (
+	addr      = flag.String("addr", "127.0.0.1:20190", "the address to serve the scheduler")
+	storeIDs  = flag.String("store-ids", "", "the comma separated ids of the stores to evict leaders from")
+	batchSize = flag.Int("batch-size", 3, "the max number of operators proposed by one scheduling")
+)
 
 type evictLeaderScheduler struct {
-	*schedulers.BaseScheduler
-	conf     *evictLeaderSchedulerConfig
-	selector *selector.RandomSelector
-	handler  http.Handler
+	storeIDs  map[uint64]struct{}
+	batchSize int
 }
 
-// newEvictLeaderScheduler creates an admin scheduler that transfers all leaders
-// out of a store.
-func newEvictLeaderScheduler(opController *schedule.OperatorController, conf *evictLeaderSchedulerConfig) schedule.Scheduler {
-	filters := []filter.Filter{
-		filter.StoreStateFilter{ActionScope: EvictLeaderName, TransferLeader: true},
+func (s *evictLeaderScheduler) Schedule(ctx context.Context, snapshot *extscheduler.Snapshot) ([]*extscheduler.OperatorProposal, error) {
+	stores := make(map[uint64]*extscheduler.StoreSnapshot, len(snapshot.Stores))
+	for _, store := range snapshot.Stores {
+		stores[store.Store.GetId()] = store
 	}
-
-	base := schedulers.NewBaseScheduler(opController)
-	handler := newEvictLeaderHandler(conf)
-	return &evictLeaderScheduler{
-		BaseScheduler: base,
-		conf:          conf,
-		selector:      selector.NewRandomSelector(filters),
-		handler:       handler,
-	}
-}
-
-func (s *evictLeaderScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	s.handler.ServeHTTP(w, r)
-}
-
-func (s *evictLeaderScheduler) GetName() string {
-	return EvictLeaderName
-}
-
-func (s *evictLeaderScheduler) GetType() string {
-	return EvictLeaderType
-}
-
-func (s *evictLeaderScheduler) EncodeConfig() ([]byte, error) {
-	s.conf.mu.RLock()
-	defer s.conf.mu.RUnlock()
-	return schedule.EncodeConfig(s.conf)
-}
-
-func (s *evictLeaderScheduler) Prepare(cluster opt.Cluster) error {
-	s.conf.mu.RLock()
-	defer s.conf.mu.RUnlock()
-	var res error
-	for id := range s.conf.StoreIDWitRanges {
-		if err := cluster.BlockStore(id); err != nil {
-			res = err
+	var proposals []*extscheduler.OperatorProposal
+	for _, region := range snapshot.Regions {
+		if len(proposals) >= s.batchSize {
+			break
 		}
-	}
-	return res
-}
-
-func (s *evictLeaderScheduler) Cleanup(cluster opt.Cluster) {
-	s.conf.mu.RLock()
-	defer s.conf.mu.RUnlock()
-	for id := range s.conf.StoreIDWitRanges {
-		cluster.UnblockStore(id)
-	}
-}
-
-func (s *evictLeaderScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
-	return s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
-}
-
-func (s *evictLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
-	var ops []*operator.Operator
-	s.conf.mu.RLock()
-	defer s.conf.mu.RUnlock()
-	for id, ranges := range s.conf.StoreIDWitRanges {
-		region := cluster.RandLeaderRegion(id, ranges, opt.HealthRegion(cluster))
-		if region == nil {
+		if _, ok := s.storeIDs[region.Leader.GetStoreId()]; !ok {
 			continue
 		}
-		target := s.selector.SelectTarget(cluster, cluster.GetFollowerStores(region))
-		if target == nil {
+		// Only schedule the healthy regions.
+		if len(region.DownPeers) != 0 || len(region.PendingPeers) != 0 {
 			continue
 		}
-		op, err := operator.CreateTransferLeaderOperator(EvictLeaderType, cluster, region, region.GetLeader().GetStoreId(), target.GetID(), operator.OpLeader)
-		if err != nil {
-			log.Debug("fail to create evict leader operator", zap.Error(err))
+		target := s.selectTarget(region, stores)
+		if target == 0 {
+			continue
+		}
+		proposals = append(proposals, &extscheduler.OperatorProposal{
+			Desc:          EvictLeaderType,
+			RegionID:      region.Region.GetId(),
+			RegionEpoch:   region.Region.GetRegionEpoch(),
+			Kind:          extscheduler.TransferLeader,
+			TargetStoreID: target,
+			HighPriority:  true,
+		})
+	}
+	return proposals, nil
+}
+
+// selectTarget randomly selects a follower which can become the leader.
+func (s *evictLeaderScheduler) selectTarget(region *extscheduler.RegionSnapshot, stores map[uint64]*extscheduler.StoreSnapshot) uint64 {
+	peers := region.Region.GetPeers()
+	for _, i := range rand.Perm(len(peers)) {
+		peer := peers[i]
+		if peer.GetIsLearner() || peer.GetStoreId() == region.Leader.GetStoreId() {
 			continue
-
 		}
-		op.SetPriorityLevel(core.HighPriority)
-		ops = append(ops, op)
+		if _, ok := s.storeIDs[peer.GetStoreId()]; ok {
+			continue
+		}
+		store, ok := stores[peer.GetStoreId()]
+		if !ok || store.Store.GetState() != metapb.StoreState_Up {
+			continue
+		}
+		return peer.GetStoreId()
 	}
-
-	return ops
-}
-
-type evictLeaderHandler struct {
-	rd     *render.Render
-	config *evictLeaderSchedulerConfig
+	return 0
 }
 
-func (handler *evictLeaderHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
-	var input map[string]interface{}
-	if err := apiutil.ReadJSONRespondError(handler.rd, w, r.Body, &input); err != nil {
-		return
-	}
-	var args []string
-	var exists bool
-	var id uint64
-	idFloat, ok := input["store_id"].(float64)
-	if ok {
-		id = (uint64)(idFloat)
-		if _, exists = handler.config.StoreIDWitRanges[id]; !exists {
-			if err := handler.config.cluster.BlockStore(id); err != nil {
-				handler.rd.JSON(w, http.StatusInternalServerError, err)
-				return
-			}
+func parseStoreIDs(s string) (map[uint64]struct{}, error) {
+	ids := make(map[uint64]struct{})
+	for _, str := range strings.Split(s, ",") {
+		if len(str) == 0 {
+			continue
 		}
-		args = append(args, strconv.FormatUint(id, 10))
-	}
-
-	ranges, ok := (input["ranges"]).([]string)
-	if ok {
-		args = append(args, ranges...)
-	} else if exists {
-		args = append(args, handler.config.getRanges(id)...)
-	}
-
-	handler.config.BuildWithArgs(args)
-	err := handler.config.Persist()
-	if err != nil {
-		handler.rd.JSON(w, http.StatusInternalServerError, err)
+		id, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids[id] = struct{}{}
 	}
-	handler.rd.JSON(w, http.StatusOK, nil)
-}
-
-func (handler *evictLeaderHandler) ListConfig(w http.ResponseWriter, r *http.Request) {
-	conf := handler.config.Clone()
-	handler.rd.JSON(w, http.StatusOK, conf)
+	return ids, nil
 }
 
-func (handler *evictLeaderHandler) DeleteConfig(w http.ResponseWriter, r *http.Request) {
-	idStr := mux.Vars(r)["store_id"]
-	id, err := strconv.ParseUint(idStr, 10, 64)
+func main() {
+	flag.Parse()
+	ids, err := parseStoreIDs(*storeIDs)
 	if err != nil {
-		handler.rd.JSON(w, http.StatusBadRequest, err.Error())
-		return
+		log.Fatal("invalid store ids", zap.String("store-ids", *storeIDs), zap.Error(err))
 	}
-
-	handler.config.mu.Lock()
-	defer handler.config.mu.Unlock()
-	_, exists := handler.config.StoreIDWitRanges[id]
-	if exists {
-		delete(handler.config.StoreIDWitRanges, id)
-		handler.config.cluster.UnblockStore(id)
-
-		handler.config.mu.Unlock()
-		handler.config.Persist()
-		handler.config.mu.Lock()
-
-		var resp interface{}
-		if len(handler.config.StoreIDWitRanges) == 0 {
-			resp = noStoreInSchedulerInfo
-		}
-		handler.rd.JSON(w, http.StatusOK, resp)
-		return
+	if len(ids) == 0 {
+		log.Fatal("should specify the store ids")
 	}
 
-	handler.rd.JSON(w, http.StatusInternalServerError, errors.New("the config does not exist"))
-}
-
-func newEvictLeaderHandler(config *evictLeaderSchedulerConfig) http.Handler {
-	h := &evictLeaderHandler{
-		config: config,
-		rd:     render.New(render.Options{IndentJSON: true}),
-	}
-	router := mux.NewRouter()
-	router.HandleFunc("/config", h.UpdateConfig).Methods("POST")
-	router.HandleFunc("/list", h.ListConfig).Methods("GET")
-	router.HandleFunc("/delete/{store_id}", h.DeleteConfig).Methods("DELETE")
-	return router
-}
-
-func getKeyRanges(args []string) ([]core.KeyRange, error) {
-	var ranges []core.KeyRange
-	for len(args) > 1 {
-		startKey, err := url.QueryUnescape(args[0])
-		if err != nil {
-			return nil, err
-		}
-		endKey, err := url.QueryUnescape(args[1])
-		if err != nil {
-			return nil, err
-		}
-		args = args[2:]
-		ranges = append(ranges, core.NewKeyRange(startKey, endKey))
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal("failed to listen", zap.String("addr", *addr), zap.Error(err))
 	}
-	if len(ranges) == 0 {
-		return []core.KeyRange{core.NewKeyRange("", "")}, nil
+	server := grpc.NewServer()
+	extscheduler.RegisterScheduler(server, &evictLeaderScheduler{storeIDs: ids, batchSize: *batchSize})
+	log.Info("serve the external scheduler", zap.String("addr", *addr))
+	if err := server.Serve(lis); err != nil {
+		log.Fatal("failed to serve", zap.Error(err))
 	}
-	return ranges, nil
 }
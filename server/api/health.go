@@ -16,9 +16,12 @@ package api
 import (
 	"net/http"
 
+	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/cluster"
+	"github.com/pingcap/pd/v4/server/member"
 	"github.com/unrolled/render"
+	"go.uber.org/zap"
 )
 
 type healthHandler struct {
@@ -32,6 +35,9 @@ type Health struct {
 	MemberID   uint64   `json:"member_id"`
 	ClientUrls []string `json:"client_urls"`
 	Health     bool     `json:"health"`
+	// HealthScore is saved by the member if the leader priority follows the
+	// health.
+	HealthScore *member.HealthScore `json:"health_score,omitempty"`
 }
 
 func newHealthHandler(svr *server.Server, rd *render.Render) *healthHandler {
@@ -50,6 +56,7 @@ func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	healthMembers := cluster.CheckHealth(members)
+	m := h.svr.GetMember()
 	healths := []Health{}
 	for _, member := range members {
 		h := Health{
@@ -61,6 +68,11 @@ func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if _, ok := healthMembers[member.GetMemberId()]; ok {
 			h.Health = true
 		}
+		score, err := m.GetMemberHealthScore(member.GetMemberId())
+		if err != nil {
+			log.Error("failed to load health score", zap.Uint64("member", member.GetMemberId()), zap.Error(err))
+		}
+		h.HealthScore = score
 		healths = append(healths, h)
 	}
 	h.rd.JSON(w, http.StatusOK, healths)
@@ -17,6 +17,7 @@ import (
 	"net/http"
 
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/statistics"
 	"github.com/unrolled/render"
 )
 
@@ -31,6 +32,9 @@ type HotStoreStats struct {
 	BytesReadStats  map[uint64]float64 `json:"bytes-read-rate,omitempty"`
 	KeysWriteStats  map[uint64]float64 `json:"keys-write-rate,omitempty"`
 	KeysReadStats   map[uint64]float64 `json:"keys-read-rate,omitempty"`
+	// StoreLoads is the load vectors of stores used by the hot region
+	// scheduler, keyed by read-leader, write-leader and write-peer.
+	StoreLoads map[string]map[uint64]*statistics.StoreLoadVector `json:"store-loads,omitempty"`
 }
 
 func newHotStatusHandler(handler *server.Handler, rd *render.Render) *hotStatusHandler {
@@ -59,6 +63,7 @@ func (h *hotStatusHandler) GetHotStores(w http.ResponseWriter, r *http.Request)
 		BytesReadStats:  bytesReadStats,
 		KeysWriteStats:  keysWriteStats,
 		KeysReadStats:   keysReadStats,
+		StoreLoads:      h.GetHotStoreLoads(),
 	}
 	h.rd.JSON(w, http.StatusOK, stats)
 }
@@ -110,6 +110,11 @@ func (h *memberHandler) DeleteByName(w http.ResponseWriter, r *http.Request) {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	err = h.svr.GetMember().DeleteMemberHealthScore(id)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 
 	// Remove member by id
 	_, err = etcdutil.RemoveEtcdMember(client, id)
@@ -134,6 +139,11 @@ func (h *memberHandler) DeleteByID(w http.ResponseWriter, r *http.Request) {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	err = h.svr.GetMember().DeleteMemberHealthScore(id)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 
 	client := h.svr.GetClient()
 	_, err = etcdutil.RemoveEtcdMember(client, id)
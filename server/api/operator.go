@@ -16,10 +16,12 @@ package api
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/unrolled/render"
 )
@@ -91,6 +93,63 @@ func (h *operatorHandler) List(w http.ResponseWriter, r *http.Request) {
 	h.r.JSON(w, http.StatusOK, results)
 }
 
+// History returns the ended operators which are persisted, filtered by the
+// finish time range [start, end) in unix seconds, the operator kind, the
+// involved store and the region. At most limit operators are returned.
+func (h *operatorHandler) History(w http.ResponseWriter, r *http.Request) {
+	var f schedule.OperatorHistoryFilter
+	query := r.URL.Query()
+	for _, param := range []struct {
+		name string
+		t    *time.Time
+	}{{"start", &f.Start}, {"end", &f.End}} {
+		if v := query.Get(param.name); v != "" {
+			ts, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				h.r.JSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			*param.t = time.Unix(ts, 0)
+		}
+	}
+	if v := query.Get("kind"); v != "" {
+		kind, err := operator.ParseOperatorKind(v)
+		if err != nil {
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		f.Kind = kind
+	}
+	for _, param := range []struct {
+		name string
+		id   *uint64
+	}{{"store", &f.StoreID}, {"region", &f.RegionID}} {
+		if v := query.Get(param.name); v != "" {
+			id, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				h.r.JSON(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			*param.id = id
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			h.r.JSON(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		f.Limit = limit
+	}
+
+	records, err := h.GetOperatorRecords(&f)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, records)
+}
+
 func (h *operatorHandler) Post(w http.ResponseWriter, r *http.Request) {
 	var input map[string]interface{}
 	if err := apiutil.ReadJSONRespondError(h.r, w, r.Body, &input); err != nil {
@@ -0,0 +1,97 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/pd/v4/pkg/apiutil"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
+	"github.com/unrolled/render"
+)
+
+type regionLabelHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newRegionLabelHandler(s *server.Server, rd *render.Render) *regionLabelHandler {
+	return &regionLabelHandler{
+		svr: s,
+		rd:  rd,
+	}
+}
+
+func (h *regionLabelHandler) GetAllRules(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	rules := cluster.GetRegionLabeler().GetAllLabelRules()
+	h.rd.JSON(w, http.StatusOK, rules)
+}
+
+func (h *regionLabelHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	id := mux.Vars(r)["id"]
+	rule := cluster.GetRegionLabeler().GetLabelRule(id)
+	if rule == nil {
+		h.rd.JSON(w, http.StatusNotFound, nil)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, rule)
+}
+
+func (h *regionLabelHandler) SetRule(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	var rule labeler.LabelRule
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &rule); err != nil {
+		return
+	}
+	if err := rule.Adjust(); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := cluster.GetRegionLabeler().SetLabelRule(&rule); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *regionLabelHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	id := mux.Vars(r)["id"]
+	if err := cluster.GetRegionLabeler().DeleteLabelRule(id); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *regionLabelHandler) GetRegionLabels(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	region := cluster.GetRegion(id)
+	if region == nil {
+		h.rd.JSON(w, http.StatusNotFound, nil)
+		return
+	}
+	labels := cluster.GetRegionLabeler().GetRegionLabels(region)
+	h.rd.JSON(w, http.StatusOK, labels)
+}
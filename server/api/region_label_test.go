@@ -0,0 +1,96 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
+)
+
+var _ = Suite(&testRegionLabelSuite{})
+
+type testRegionLabelSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testRegionLabelSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/config/region-label", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testRegionLabelSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testRegionLabelSuite) TestRules(c *C) {
+	rules := []*labeler.LabelRule{
+		{ID: "r1", StartKeyHex: hex.EncodeToString([]byte("a")), EndKeyHex: hex.EncodeToString([]byte("c")), Labels: map[string]string{labeler.MergeLabel: labeler.DenyValue}},
+		{ID: "r2", StartKeyHex: hex.EncodeToString([]byte("b")), EndKeyHex: "", Labels: map[string]string{labeler.ScheduleLabel: labeler.DenyValue}},
+	}
+	for _, rule := range rules {
+		data, err := json.Marshal(rule)
+		c.Assert(err, IsNil)
+		c.Assert(postJSON(s.urlPrefix+"/rule", data), IsNil)
+	}
+
+	var resp []*labeler.LabelRule
+	c.Assert(readJSON(s.urlPrefix+"/rules", &resp), IsNil)
+	c.Assert(resp, HasLen, 2)
+	for i := range resp {
+		c.Assert(resp[i].ID, Equals, rules[i].ID)
+		c.Assert(resp[i].Labels, DeepEquals, rules[i].Labels)
+	}
+
+	var rule labeler.LabelRule
+	c.Assert(readJSON(s.urlPrefix+"/rule/r1", &rule), IsNil)
+	c.Assert(rule.StartKeyHex, Equals, rules[0].StartKeyHex)
+
+	// invalid rule
+	data, _ := json.Marshal(&labeler.LabelRule{ID: "r3", StartKeyHex: "XX", Labels: map[string]string{"k": "v"}})
+	c.Assert(postJSON(s.urlPrefix+"/rule", data), NotNil)
+
+	region := core.NewRegionInfo(&metapb.Region{
+		Id:          1,
+		StartKey:    []byte("a"),
+		EndKey:      []byte("b"),
+		Peers:       []*metapb.Peer{{Id: 101, StoreId: 1}},
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+	}, &metapb.Peer{Id: 101, StoreId: 1})
+	mustRegionHeartbeat(c, s.svr, region)
+	var labels map[string]string
+	c.Assert(readJSON(s.urlPrefix+"/region/1", &labels), IsNil)
+	c.Assert(labels, DeepEquals, map[string]string{labeler.MergeLabel: labeler.DenyValue})
+
+	res, err := doDelete(s.urlPrefix + "/rule/r1")
+	c.Assert(err, IsNil)
+	c.Assert(res.StatusCode, Equals, http.StatusOK)
+	c.Assert(readJSON(s.urlPrefix+"/rule/r1", &rule), NotNil)
+	c.Assert(readJSON(s.urlPrefix+"/rules", &resp), IsNil)
+	c.Assert(resp, HasLen, 1)
+}
@@ -60,6 +60,7 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	operatorHandler := newOperatorHandler(handler, rd)
 	apiRouter.HandleFunc("/operators", operatorHandler.List).Methods("GET")
 	apiRouter.HandleFunc("/operators", operatorHandler.Post).Methods("POST")
+	apiRouter.HandleFunc("/operators/history", operatorHandler.History).Methods("GET")
 	apiRouter.HandleFunc("/operators/{region_id}", operatorHandler.Get).Methods("GET")
 	apiRouter.HandleFunc("/operators/{region_id}", operatorHandler.Delete).Methods("DELETE")
 
@@ -68,6 +69,8 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	apiRouter.HandleFunc("/schedulers", schedulerHandler.Post).Methods("POST")
 	apiRouter.HandleFunc("/schedulers/{name}", schedulerHandler.Delete).Methods("DELETE")
 	apiRouter.HandleFunc("/schedulers/{name}", schedulerHandler.PauseOrResume).Methods("POST")
+	apiRouter.HandleFunc("/schedulers/{name}/diagnostic", schedulerHandler.Diagnose).Methods("GET")
+	apiRouter.HandleFunc("/schedulers/{name}/windows", schedulerHandler.SetWindows).Methods("POST")
 	schedulerConfigHandler := newSchedulerConfigHandler(svr, rd)
 	rootRouter.PathPrefix(server.SchedulerConfigHandlerPath).Handler(schedulerConfigHandler)
 
@@ -95,6 +98,20 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	clusterRouter.HandleFunc("/config/rule/{group}/{id}", rulesHandler.Get).Methods("GET")
 	clusterRouter.HandleFunc("/config/rule", rulesHandler.Set).Methods("POST")
 	clusterRouter.HandleFunc("/config/rule/{group}/{id}", rulesHandler.Delete).Methods("DELETE")
+	clusterRouter.HandleFunc("/config/rules/batch", rulesHandler.Batch).Methods("POST")
+	clusterRouter.HandleFunc("/config/rules/simulate", rulesHandler.Simulate).Methods("POST")
+
+	clusterRouter.HandleFunc("/config/rule_group/{id}", rulesHandler.GetGroupConfig).Methods("GET")
+	clusterRouter.HandleFunc("/config/rule_group", rulesHandler.SetGroupConfig).Methods("POST")
+	clusterRouter.HandleFunc("/config/rule_group/{id}", rulesHandler.DeleteGroupConfig).Methods("DELETE")
+	clusterRouter.HandleFunc("/config/rule_groups", rulesHandler.GetAllGroupConfigs).Methods("GET")
+
+	regionLabelHandler := newRegionLabelHandler(svr, rd)
+	clusterRouter.HandleFunc("/config/region-label/rules", regionLabelHandler.GetAllRules).Methods("GET")
+	clusterRouter.HandleFunc("/config/region-label/rule/{id}", regionLabelHandler.GetRule).Methods("GET")
+	clusterRouter.HandleFunc("/config/region-label/rule/{id}", regionLabelHandler.DeleteRule).Methods("DELETE")
+	clusterRouter.HandleFunc("/config/region-label/rule", regionLabelHandler.SetRule).Methods("POST")
+	clusterRouter.HandleFunc("/config/region-label/region/{id}", regionLabelHandler.GetRegionLabels).Methods("GET")
 
 	storeHandler := newStoreHandler(handler, rd)
 	clusterRouter.HandleFunc("/store/{id}", storeHandler.Get).Methods("GET")
@@ -103,6 +120,7 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	clusterRouter.HandleFunc("/store/{id}/label", storeHandler.SetLabels).Methods("POST")
 	clusterRouter.HandleFunc("/store/{id}/weight", storeHandler.SetWeight).Methods("POST")
 	clusterRouter.HandleFunc("/store/{id}/limit", storeHandler.SetLimit).Methods("POST")
+	clusterRouter.HandleFunc("/store/{id}/progress", storeHandler.GetProgress).Methods("GET")
 	storesHandler := newStoresHandler(handler, rd)
 	clusterRouter.Handle("/stores", storesHandler).Methods("GET")
 	clusterRouter.HandleFunc("/stores/remove-tombstone", storesHandler.RemoveTombStone).Methods("DELETE")
@@ -143,6 +161,7 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	clusterRouter.HandleFunc("/regions/check/down-peer", regionsHandler.GetDownPeerRegions).Methods("GET")
 	clusterRouter.HandleFunc("/regions/check/offline-peer", regionsHandler.GetOfflinePeer).Methods("GET")
 	clusterRouter.HandleFunc("/regions/check/empty-region", regionsHandler.GetEmptyRegion).Methods("GET")
+	clusterRouter.HandleFunc("/regions/check/isolation-violated", regionsHandler.GetIsolationViolatedRegions).Methods("GET")
 	clusterRouter.HandleFunc("/regions/check/hist-size", regionsHandler.GetSizeHistogram).Methods("GET")
 	clusterRouter.HandleFunc("/regions/check/hist-keys", regionsHandler.GetKeysHistogram).Methods("GET")
 	clusterRouter.HandleFunc("/regions/sibling/{id}", regionsHandler.GetRegionSiblings).Methods("GET")
@@ -167,10 +186,17 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) (*mux.
 	trendHandler := newTrendHandler(svr, rd)
 	apiRouter.HandleFunc("/trend", trendHandler.Handle).Methods("GET")
 
+	serviceGCSafepointHandler := newServiceGCSafepointHandler(svr, rd)
+	clusterRouter.HandleFunc("/gc/safepoint", serviceGCSafepointHandler.List).Methods("GET")
+	clusterRouter.HandleFunc("/gc/safepoint", serviceGCSafepointHandler.Update).Methods("POST")
+
 	adminHandler := newAdminHandler(svr, rd)
 	clusterRouter.HandleFunc("/admin/cache/region/{id}", adminHandler.HandleDropCacheRegion).Methods("DELETE")
 	clusterRouter.HandleFunc("/admin/reset-ts", adminHandler.ResetTS).Methods("POST")
 
+	tsoHandler := newTSOHandler(svr, rd)
+	apiRouter.HandleFunc("/tso/status", tsoHandler.GetStatus).Methods("GET")
+
 	logHandler := newlogHandler(svr, rd)
 	apiRouter.HandleFunc("/admin/log", logHandler.Handle).Methods("POST")
 
@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/codec"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/checker"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pkg/errors"
 	"github.com/unrolled/render"
@@ -180,3 +181,152 @@ func (h *ruleHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	h.rd.JSON(w, http.StatusOK, nil)
 }
+
+func (h *ruleHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	var opts []placement.RuleOp
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &opts); err != nil {
+		return
+	}
+	if err := h.checkRuleOps(opts); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := cluster.GetRuleManager().Batch(opts); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+const defaultSimulateOperatorLimit = 16
+
+func (h *ruleHandler) Simulate(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	limit := defaultSimulateOperatorLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	// Only the regions in the range are simulated, and the scan stops after
+	// regionLimit regions, so that a large cluster is not scanned entirely.
+	startKey, endKey := r.URL.Query().Get("start_key"), r.URL.Query().Get("end_key")
+	regionLimit := maxRegionLimit
+	if limitStr := r.URL.Query().Get("region_limit"); limitStr != "" {
+		var err error
+		regionLimit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if regionLimit <= 0 || regionLimit > maxRegionLimit {
+		regionLimit = maxRegionLimit
+	}
+	var opts []placement.RuleOp
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &opts); err != nil {
+		return
+	}
+	if err := h.checkRuleOps(opts); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	dryRun, err := cluster.GetRuleManager().DryRun(opts)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	regions := cluster.ScanRegions([]byte(startKey), []byte(endKey), regionLimit)
+	res := checker.SimulateRules(cluster, dryRun, regions, limit)
+	h.rd.JSON(w, http.StatusOK, res)
+}
+
+func (h *ruleHandler) checkRuleOps(opts []placement.RuleOp) error {
+	for _, opt := range opts {
+		if opt.Rule == nil {
+			return errors.New("rule should not be empty")
+		}
+		switch opt.Action {
+		case placement.RuleOpAdd:
+			if err := h.checkRule(opt.Rule); err != nil {
+				return err
+			}
+		case placement.RuleOpDel:
+		default:
+			return errors.Errorf("unknown action type %s", opt.Action)
+		}
+	}
+	return nil
+}
+
+func (h *ruleHandler) GetGroupConfig(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	id := mux.Vars(r)["id"]
+	group := cluster.GetRuleManager().GetRuleGroup(id)
+	if group == nil {
+		h.rd.JSON(w, http.StatusNotFound, nil)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, group)
+}
+
+func (h *ruleHandler) SetGroupConfig(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	var group placement.RuleGroup
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &group); err != nil {
+		return
+	}
+	if group.ID == "" {
+		h.rd.JSON(w, http.StatusBadRequest, "group ID should not be empty")
+		return
+	}
+	if err := cluster.GetRuleManager().SetRuleGroup(&group); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *ruleHandler) DeleteGroupConfig(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if err := cluster.GetRuleManager().DeleteRuleGroup(id); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+func (h *ruleHandler) GetAllGroupConfigs(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	if !cluster.IsPlacementRulesEnabled() {
+		h.rd.JSON(w, http.StatusPreconditionFailed, errPlacementDisabled.Error())
+		return
+	}
+	groups := cluster.GetRuleManager().GetRuleGroups()
+	h.rd.JSON(w, http.StatusOK, groups)
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/pd/v4/pkg/apiutil"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/server/schedulers"
@@ -78,6 +79,11 @@ func (h *schedulerHandler) Post(w http.ResponseWriter, r *http.Request) {
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+	case schedulers.BalanceLoadName:
+		if err := h.AddBalanceLoadScheduler(); err != nil {
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	case schedulers.LabelName:
 		if err := h.AddLabelScheduler(); err != nil {
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
@@ -108,6 +114,23 @@ func (h *schedulerHandler) Post(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+	case schedulers.ExternalName:
+		var args []string
+		collector := func(v string) {
+			args = append(args, v)
+		}
+		if err := collectStringOption("scheduler_name", input, collector); err != nil {
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if err := collectStringOption("address", input, collector); err != nil {
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if err := h.AddExternalScheduler(args[0], args[1]); err != nil {
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	case schedulers.AdjacentRegionName:
 		var args []string
 		leaderLimit, ok := input["leader_limit"].(string)
@@ -257,6 +280,43 @@ func (h *schedulerHandler) PauseOrResume(w http.ResponseWriter, r *http.Request)
 	h.r.JSON(w, http.StatusOK, nil)
 }
 
+func (h *schedulerHandler) SetWindows(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Windows []string `json:"windows"`
+	}
+	if err := apiutil.ReadJSONRespondError(h.r, w, r.Body, &input); err != nil {
+		return
+	}
+	if _, err := typeutil.ParseTimeWindows(input.Windows); err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	name := mux.Vars(r)["name"]
+	switch err := h.SetScheduleWindows(name, input.Windows); err {
+	case nil:
+		h.r.JSON(w, http.StatusOK, nil)
+	case cluster.ErrSchedulerNotFound:
+		h.r.JSON(w, http.StatusNotFound, err.Error())
+	default:
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *schedulerHandler) Diagnose(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	result, err := h.GetSchedulerDiagnostic(name)
+	switch err {
+	case nil:
+		h.r.JSON(w, http.StatusOK, result)
+	case cluster.ErrSchedulerNotFound:
+		h.r.JSON(w, http.StatusNotFound, err.Error())
+	case cluster.ErrSchedulerNotDiagnosable:
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+	default:
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
 type schedulerConfigHandler struct {
 	svr *server.Server
 	rd  *render.Render
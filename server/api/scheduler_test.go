@@ -98,7 +98,47 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 		extraTestFunc func(name string, c *C)
 	}{
 		{name: "balance-leader-scheduler"},
-		{name: "balance-hot-region-scheduler"},
+		{
+			name:        "balance-hot-region-scheduler",
+			createdName: "balance-hot-region-scheduler",
+			// Test the scheduler config handler.
+			extraTestFunc: func(name string, c *C) {
+				resp := make(map[string]interface{})
+				listURL := fmt.Sprintf("%s%s%s/%s/list", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				c.Assert(readJSON(listURL, &resp), IsNil)
+				c.Assert(resp["min-hot-byte-rate"], Equals, 100.0)
+				c.Assert(resp["read-priorities"], DeepEquals, []interface{}{"byte", "key"})
+
+				updateURL := fmt.Sprintf("%s%s%s/%s/config", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				input := map[string]interface{}{
+					"min-hot-byte-rate": 200,
+					"read-priorities":   []string{"key", "byte"},
+				}
+				body, err := json.Marshal(input)
+				c.Assert(err, IsNil)
+				c.Assert(postJSON(updateURL, body), IsNil)
+				resp = make(map[string]interface{})
+				c.Assert(readJSON(listURL, &resp), IsNil)
+				c.Assert(resp["min-hot-byte-rate"], Equals, 200.0)
+				c.Assert(resp["min-hot-key-rate"], Equals, 10.0)
+				c.Assert(resp["read-priorities"], DeepEquals, []interface{}{"key", "byte"})
+
+				// invalid config should be rejected.
+				for _, input := range []map[string]interface{}{
+					{"read-priorities": []string{"byte", "byte"}},
+					{"max-peer-number": 0},
+					{"unknown-config": 1},
+				} {
+					body, err = json.Marshal(input)
+					c.Assert(err, IsNil)
+					c.Assert(postJSON(updateURL, body), NotNil)
+				}
+				resp = make(map[string]interface{})
+				c.Assert(readJSON(listURL, &resp), IsNil)
+				c.Assert(resp["min-hot-byte-rate"], Equals, 200.0)
+				c.Assert(resp["max-peer-number"], Equals, 1000.0)
+			},
+		},
 		{name: "balance-region-scheduler"},
 		{name: "shuffle-leader-scheduler"},
 		{name: "shuffle-region-scheduler"},
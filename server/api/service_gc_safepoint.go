@@ -0,0 +1,107 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/pingcap/pd/v4/pkg/apiutil"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/unrolled/render"
+)
+
+type serviceGCSafepointHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newServiceGCSafepointHandler(svr *server.Server, rd *render.Render) *serviceGCSafepointHandler {
+	return &serviceGCSafepointHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+type listServiceGCSafepoint struct {
+	ServiceGCSafepoints []*core.ServiceSafePoint `json:"service_gc_safe_points"`
+	GCSafePoint         uint64                   `json:"gc_safe_point"`
+}
+
+// List returns the GC safe point of the cluster and the unexpired safe points
+// of all services, which hold back GC until they expire.
+func (h *serviceGCSafepointHandler) List(w http.ResponseWriter, r *http.Request) {
+	storage := h.svr.GetStorage()
+	gcSafepoint, err := storage.LoadGCSafePoint()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ssps, err := storage.LoadAllServiceGCSafePoints()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	list := listServiceGCSafepoint{
+		ServiceGCSafepoints: make([]*core.ServiceSafePoint, 0, len(ssps)),
+		GCSafePoint:         gcSafepoint,
+	}
+	now, err := h.svr.TSONow()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	for _, ssp := range ssps {
+		if ssp.ExpiredAt >= now.Unix() {
+			list.ServiceGCSafepoints = append(list.ServiceGCSafepoints, ssp)
+		}
+	}
+	h.rd.JSON(w, http.StatusOK, list)
+}
+
+type updateServiceGCSafepointInput struct {
+	ServiceID string `json:"service_id"`
+	TTL       int64  `json:"ttl"`
+	SafePoint uint64 `json:"safe_point"`
+}
+
+type updateServiceGCSafepointOutput struct {
+	ServiceID    string `json:"service_id"`
+	TTL          int64  `json:"ttl"`
+	MinSafePoint uint64 `json:"min_safe_point"`
+}
+
+// Update saves the safe point of a service, which expires after ttl seconds,
+// and returns the minimum safe point across all services. A non-positive ttl
+// removes the safe point of the service.
+func (h *serviceGCSafepointHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var input updateServiceGCSafepointInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if input.ServiceID == "" {
+		h.rd.JSON(w, http.StatusBadRequest, "service_id should not be empty")
+		return
+	}
+	min, ttl, err := h.svr.UpdateServiceGCSafePoint(input.ServiceID, input.TTL, input.SafePoint)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, updateServiceGCSafepointOutput{
+		ServiceID:    min.ServiceID,
+		TTL:          ttl,
+		MinSafePoint: min.SafePoint,
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server"
+)
+
+var _ = Suite(&testServiceGCSafepointSuite{})
+
+type testServiceGCSafepointSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testServiceGCSafepointSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1/gc/safepoint", addr, apiPrefix)
+
+	mustBootstrapCluster(c, s.svr)
+}
+
+func (s *testServiceGCSafepointSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testServiceGCSafepointSuite) update(c *C, serviceID string, ttl int64, safePoint uint64) *updateServiceGCSafepointOutput {
+	input := &updateServiceGCSafepointInput{ServiceID: serviceID, TTL: ttl, SafePoint: safePoint}
+	data, err := json.Marshal(input)
+	c.Assert(err, IsNil)
+	output := &updateServiceGCSafepointOutput{}
+	err = postJSON(s.urlPrefix, data, func(res []byte, code int) {
+		c.Assert(json.Unmarshal(res, output), IsNil)
+	})
+	c.Assert(err, IsNil)
+	return output
+}
+
+func (s *testServiceGCSafepointSuite) TestUpdateServiceGCSafepoint(c *C) {
+	// The TTL is 0 rather than negative if no service holds a safe point.
+	output := s.update(c, "a", 0, 0)
+	c.Assert(output.ServiceID, Equals, "")
+	c.Assert(output.TTL, Equals, int64(0))
+
+	for _, ssp := range []struct {
+		serviceID string
+		safePoint uint64
+	}{{"a", 1}, {"b", 2}, {"c", 3}} {
+		output = s.update(c, ssp.serviceID, 1000, ssp.safePoint)
+		c.Assert(output.MinSafePoint, Equals, uint64(1))
+		c.Assert(output.ServiceID, Equals, "a")
+		c.Assert(output.TTL > 0 && output.TTL <= 1000, IsTrue)
+	}
+
+	// Update the minimum one.
+	output = s.update(c, "a", 1000, 4)
+	c.Assert(output.MinSafePoint, Equals, uint64(2))
+
+	// A safe point less than the minimum is not saved.
+	output = s.update(c, "d", 1000, 1)
+	c.Assert(output.MinSafePoint, Equals, uint64(2))
+	list := &listServiceGCSafepoint{}
+	c.Assert(readJSON(s.urlPrefix, list), IsNil)
+	c.Assert(list.ServiceGCSafepoints, HasLen, 3)
+
+	// Remove the minimum one.
+	output = s.update(c, "b", 0, 0)
+	c.Assert(output.MinSafePoint, Equals, uint64(3))
+
+	// Expired safe points are removed.
+	output = s.update(c, "c", 1, 3)
+	c.Assert(output.MinSafePoint, Equals, uint64(3))
+	time.Sleep(2 * time.Second)
+	output = s.update(c, "a", 1000, 4)
+	c.Assert(output.MinSafePoint, Equals, uint64(4))
+	c.Assert(readJSON(s.urlPrefix, list), IsNil)
+	c.Assert(list.ServiceGCSafepoints, HasLen, 1)
+
+	// An empty service ID is rejected.
+	data, err := json.Marshal(&updateServiceGCSafepointInput{TTL: 1000, SafePoint: 5})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(s.urlPrefix, data), NotNil)
+}
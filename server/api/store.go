@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
@@ -180,6 +181,23 @@ func (h *storeHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.rd.JSON(w, http.StatusOK, nil)
 }
 
+func (h *storeHandler) GetProgress(w http.ResponseWriter, r *http.Request) {
+	rc := getCluster(r.Context())
+	vars := mux.Vars(r)
+	storeID, errParse := apiutil.ParseUint64VarsField(vars, "id")
+	if errParse != nil {
+		apiutil.ErrorResp(h.rd, w, errcode.NewInvalidInputErr(errParse))
+		return
+	}
+
+	progress, err := rc.GetStoreProgress(storeID)
+	if err != nil {
+		apiutil.ErrorResp(h.rd, w, err)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, progress)
+}
+
 func (h *storeHandler) SetState(w http.ResponseWriter, r *http.Request) {
 	rc := getCluster(r.Context())
 	vars := mux.Vars(r)
@@ -307,7 +325,13 @@ func (h *storeHandler) SetLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.SetStoreLimit(storeID, rate/schedule.StoreBalanceBaseTime); err != nil {
+	keys, err := parseStoreLimitKeys(input)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.SetStoreLimit(storeID, rate/schedule.StoreBalanceBaseTime, keys...); err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -355,7 +379,13 @@ func (h *storesHandler) SetAllLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.SetAllStoresLimit(rate / schedule.StoreBalanceBaseTime); err != nil {
+	keys, err := parseStoreLimitKeys(input)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.SetAllStoresLimit(rate/schedule.StoreBalanceBaseTime, keys...); err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -373,17 +403,62 @@ func (h *storesHandler) GetAllLimit(w http.ResponseWriter, r *http.Request) {
 		Rate float64 `json:"rate"`
 		Mode string  `json:"mode"`
 	}
-	resp := make(map[uint64]*LimitResp)
-	for s, l := range limits {
-		resp[s] = &LimitResp{
-			Rate: l.Rate() * schedule.StoreBalanceBaseTime,
-			Mode: l.Mode().String(),
+	// store ID -> operator kind -> limit type -> limit
+	resp := make(map[uint64]map[string]map[string]*LimitResp)
+	for s, storeLimits := range limits {
+		resp[s] = make(map[string]map[string]*LimitResp)
+		for key, l := range storeLimits {
+			kind := "default"
+			if key.Kind != 0 {
+				kind = key.Kind.String()
+			}
+			if resp[s][kind] == nil {
+				resp[s][kind] = make(map[string]*LimitResp)
+			}
+			resp[s][kind][key.Type.String()] = &LimitResp{
+				Rate: l.Rate() * schedule.StoreBalanceBaseTime,
+				Mode: l.Mode().String(),
+			}
 		}
 	}
 
 	h.rd.JSON(w, http.StatusOK, resp)
 }
 
+// parseStoreLimitKeys parses the optional "type" and "kind" of the store
+// limit. All the types are used if the type is unset, and the default
+// buckets are used if the kind is unset.
+func parseStoreLimitKeys(input map[string]interface{}) ([]schedule.StoreLimitKey, error) {
+	types := core.StoreLimitTypes
+	if typeVal, ok := input["type"]; ok {
+		str, ok := typeVal.(string)
+		if !ok {
+			return nil, errors.New("badformat type")
+		}
+		limitType, err := core.ParseStoreLimitType(str)
+		if err != nil {
+			return nil, err
+		}
+		types = []core.StoreLimitType{limitType}
+	}
+	var kind operator.OpKind
+	if kindVal, ok := input["kind"]; ok {
+		str, ok := kindVal.(string)
+		if !ok {
+			return nil, errors.New("badformat kind")
+		}
+		var err error
+		if kind, err = schedule.ParseStoreLimitKind(str); err != nil {
+			return nil, err
+		}
+	}
+	keys := make([]schedule.StoreLimitKey, 0, len(types))
+	for _, limitType := range types {
+		keys = append(keys, schedule.StoreLimitKey{Type: limitType, Kind: kind})
+	}
+	return keys, nil
+}
+
 func (h *storesHandler) SetStoreLimitScene(w http.ResponseWriter, r *http.Request) {
 	scene := h.Handler.GetStoreLimitScene()
 	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &scene); err != nil {
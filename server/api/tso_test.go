@@ -0,0 +1,53 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server"
+)
+
+var _ = Suite(&testTSOStatusSuite{})
+
+type testTSOStatusSuite struct {
+	svr       *server.Server
+	cleanup   cleanUpFunc
+	urlPrefix string
+}
+
+func (s *testTSOStatusSuite) SetUpSuite(c *C) {
+	s.svr, s.cleanup = mustNewServer(c)
+	mustWaitLeader(c, []*server.Server{s.svr})
+
+	addr := s.svr.GetAddr()
+	s.urlPrefix = fmt.Sprintf("%s%s/api/v1", addr, apiPrefix)
+}
+
+func (s *testTSOStatusSuite) TearDownSuite(c *C) {
+	s.cleanup()
+}
+
+func (s *testTSOStatusSuite) TestGetStatus(c *C) {
+	status := &server.TSOStatus{}
+	err := readJSON(s.urlPrefix+"/tso/status", status)
+	c.Assert(err, IsNil)
+	c.Assert(status.Global, NotNil)
+	c.Assert(status.Global.Allocating, IsTrue)
+	c.Assert(status.Global.SaveWindow.Duration >= 0, IsTrue)
+	c.Assert(status.Global.SavedPhysical.Before(status.Global.Physical), IsFalse)
+	c.Assert(status.DCLocation, Equals, "")
+	c.Assert(status.Local, IsNil)
+}
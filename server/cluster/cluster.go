@@ -36,6 +36,7 @@ import (
 	syncer "github.com/pingcap/pd/v4/server/region_syncer"
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/pd/v4/server/statistics"
@@ -94,13 +95,17 @@ type RaftCluster struct {
 	hotSpotCache    *statistics.HotCache
 
 	coordinator *coordinator
+	// storeProgresses record where the progresses of removing or preparing
+	// stores start.
+	storeProgresses map[uint64]*storeProgressStart
 
 	wg           sync.WaitGroup
 	quit         chan struct{}
 	regionSyncer *syncer.RegionSyncer
 
-	ruleManager *placement.RuleManager
-	client      *clientv3.Client
+	ruleManager   *placement.RuleManager
+	regionLabeler *labeler.RegionLabeler
+	client        *clientv3.Client
 }
 
 // Status saves some state information.
@@ -209,6 +214,11 @@ func (c *RaftCluster) Start(s Server) error {
 		}
 	}
 
+	c.regionLabeler, err = labeler.NewRegionLabeler(c.storage)
+	if err != nil {
+		return err
+	}
+
 	c.coordinator = newCoordinator(c.ctx, cluster, s.GetHBStreams())
 	c.regionStats = statistics.NewRegionStatistics(c.opt)
 	c.limiter = NewStoreLimiter(c.coordinator.opController)
@@ -404,8 +414,13 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *pdpb.StoreStats) error {
 	c.storesStats.UpdateTotalBytesRate(c.core.GetStores)
 
 	// c.limiter is nil before "start" is called
-	if c.limiter != nil && c.opt.Load().StoreLimitMode == "auto" {
-		c.limiter.Collect(newStore.GetStoreStats())
+	if c.limiter != nil {
+		switch c.opt.Load().StoreLimitMode {
+		case "auto":
+			c.limiter.Collect(newStore.GetStoreStats())
+		case "adaptive":
+			c.limiter.Adapt(newStore.GetStoreStats())
+		}
 	}
 
 	return nil
@@ -917,6 +932,9 @@ func (c *RaftCluster) BuryStore(storeID uint64, force bool) error { // revive:di
 	err := c.putStoreLocked(newStore)
 	if err == nil {
 		c.coordinator.opController.RemoveStoreLimit(store.GetID())
+		if c.limiter != nil {
+			c.limiter.RemoveStore(store.GetID())
+		}
 	}
 	return err
 }
@@ -932,7 +950,7 @@ func (c *RaftCluster) UnblockStore(storeID uint64) {
 }
 
 // AttachAvailableFunc attaches an available function to a specific store.
-func (c *RaftCluster) AttachAvailableFunc(storeID uint64, f func() bool) {
+func (c *RaftCluster) AttachAvailableFunc(storeID uint64, f func(limitType core.StoreLimitType, opKind string) bool) {
 	c.core.AttachAvailableFunc(storeID, f)
 }
 
@@ -981,6 +999,7 @@ func (c *RaftCluster) putStoreLocked(store *core.StoreInfo) error {
 			return err
 		}
 	}
+	c.updateStoreProgressLocked(c.GetStore(store.GetID()), store)
 	c.core.PutStore(store)
 	c.storesStats.CreateRollingStoreStats(store.GetID())
 	return nil
@@ -1045,6 +1064,9 @@ func (c *RaftCluster) RemoveTombStoneRecords() error {
 				return err
 			}
 			c.coordinator.opController.RemoveStoreLimit(store.GetID())
+			if c.limiter != nil {
+				c.limiter.RemoveStore(store.GetID())
+			}
 			log.Info("delete store succeeded",
 				zap.Stringer("store", store.GetMeta()))
 		}
@@ -1258,6 +1280,11 @@ func (c *RaftCluster) GetReplicaScheduleLimit() uint64 {
 	return c.opt.GetReplicaScheduleLimit()
 }
 
+// GetSplitScheduleLimit returns the limit for split schedule.
+func (c *RaftCluster) GetSplitScheduleLimit() uint64 {
+	return c.opt.GetSplitScheduleLimit()
+}
+
 // GetMergeScheduleLimit returns the limit for merge schedule.
 func (c *RaftCluster) GetMergeScheduleLimit() uint64 {
 	return c.opt.GetMergeScheduleLimit()
@@ -1333,6 +1360,11 @@ func (c *RaftCluster) GetPatrolRegionInterval() time.Duration {
 	return c.opt.GetPatrolRegionInterval()
 }
 
+// GetMaxOperatorStepWaitTime returns the max duration that a step of an operator can run.
+func (c *RaftCluster) GetMaxOperatorStepWaitTime() time.Duration {
+	return c.opt.GetMaxOperatorStepWaitTime()
+}
+
 // GetMaxStoreDownTime returns the max down time of a store.
 func (c *RaftCluster) GetMaxStoreDownTime() time.Duration {
 	return c.opt.GetMaxStoreDownTime()
@@ -1373,6 +1405,22 @@ func (c *RaftCluster) GetLeaderSchedulePolicy() core.SchedulePolicy {
 	return c.opt.GetLeaderSchedulePolicy()
 }
 
+// GetRegionWeightMode returns how the region weights of stores are decided.
+func (c *RaftCluster) GetRegionWeightMode() core.RegionWeightMode {
+	return c.opt.GetRegionWeightMode()
+}
+
+// GetStoreClassWeights returns the weights of the store classes.
+func (c *RaftCluster) GetStoreClassWeights() map[string]float64 {
+	return c.opt.GetStoreClassWeights()
+}
+
+// IsInScheduleWindows returns if the time is in the schedule windows of a
+// scheduler or a checker.
+func (c *RaftCluster) IsInScheduleWindows(name string, t time.Time) bool {
+	return c.opt.IsInScheduleWindows(name, t)
+}
+
 // GetKeyType is to get key type.
 func (c *RaftCluster) GetKeyType() core.KeyType {
 	return c.opt.GetKeyType()
@@ -1403,6 +1451,21 @@ func (c *RaftCluster) IsDebugMetricsEnabled() bool {
 	return c.opt.IsDebugMetricsEnabled()
 }
 
+// IsOperatorHistoryEnabled returns if the ended operators are persisted.
+func (c *RaftCluster) IsOperatorHistoryEnabled() bool {
+	return c.opt.IsOperatorHistoryEnabled()
+}
+
+// GetOperatorHistoryRetention returns how long the persisted operators are kept.
+func (c *RaftCluster) GetOperatorHistoryRetention() time.Duration {
+	return c.opt.GetOperatorHistoryRetention()
+}
+
+// GetMaxOperatorHistoryRecords returns the max number of the persisted operators.
+func (c *RaftCluster) GetMaxOperatorHistoryRecords() uint64 {
+	return c.opt.GetMaxOperatorHistoryRecords()
+}
+
 // CheckLabelProperty is used to check label property.
 func (c *RaftCluster) CheckLabelProperty(typ string, labels []*metapb.StoreLabel) bool {
 	return c.opt.CheckLabelProperty(typ, labels)
@@ -1486,11 +1549,28 @@ func (c *RaftCluster) GetRuleManager() *placement.RuleManager {
 	return c.ruleManager
 }
 
+// GetRegionLabeler returns the region labeler. It does not take the lock
+// since the labeler is only set when the cluster starts, before it is used,
+// and it is checked for every scheduled region.
+func (c *RaftCluster) GetRegionLabeler() *labeler.RegionLabeler {
+	return c.regionLabeler
+}
+
 // FitRegion tries to fit the region with placement rules.
 func (c *RaftCluster) FitRegion(region *core.RegionInfo) *placement.RegionFit {
 	return c.GetRuleManager().FitRegion(c, region)
 }
 
+// ObserveRuleFit records the statistics of a region by its fit computed by
+// the rule checker, so that the fit is not computed with the lock held.
+func (c *RaftCluster) ObserveRuleFit(region *core.RegionInfo, fit *placement.RegionFit) {
+	c.Lock()
+	defer c.Unlock()
+	if c.regionStats != nil {
+		c.regionStats.ObserveRuleFit(region, fit)
+	}
+}
+
 type prepareChecker struct {
 	reactiveRegions map[uint64]int
 	start           time.Time
@@ -1551,6 +1631,14 @@ func (c *RaftCluster) GetHotReadRegions() *statistics.StoreHotPeersInfos {
 	return co.getHotReadRegions()
 }
 
+// GetHotStoreLoads gets the load vectors of stores used by the hot region scheduler.
+func (c *RaftCluster) GetHotStoreLoads() map[string]map[uint64]*statistics.StoreLoadVector {
+	c.RLock()
+	co := c.coordinator
+	c.RUnlock()
+	return co.getHotStoreLoads()
+}
+
 // GetSchedulers gets all schedulers.
 func (c *RaftCluster) GetSchedulers() map[string]*scheduleController {
 	c.RLock()
@@ -1579,6 +1667,20 @@ func (c *RaftCluster) PauseOrResumeScheduler(name string, t int64) error {
 	return c.coordinator.pauseOrResumeScheduler(name, t)
 }
 
+// SetScheduleWindows sets the time windows of a scheduler or a checker.
+func (c *RaftCluster) SetScheduleWindows(name string, windows []string) error {
+	c.RLock()
+	defer c.RUnlock()
+	return c.coordinator.setScheduleWindows(name, windows)
+}
+
+// DiagnoseScheduler returns the decision trace of the last round of a scheduler.
+func (c *RaftCluster) DiagnoseScheduler(name string) (*schedule.DiagnosticResult, error) {
+	c.RLock()
+	defer c.RUnlock()
+	return c.coordinator.diagnoseScheduler(name)
+}
+
 // GetStoreLimiter returns the dynamic adjusting limiter
 func (c *RaftCluster) GetStoreLimiter() *StoreLimiter {
 	return c.limiter
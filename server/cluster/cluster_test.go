@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/id"
 	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 )
 
@@ -636,6 +637,7 @@ func newTestCluster(opt *config.ScheduleOption) *testCluster {
 func newTestRaftCluster(id id.Allocator, opt *config.ScheduleOption, storage *core.Storage, basicCluster *core.BasicCluster) *RaftCluster {
 	rc := &RaftCluster{}
 	rc.InitCluster(id, opt, storage, basicCluster)
+	rc.regionLabeler, _ = labeler.NewRegionLabeler(storage)
 	return rc
 }
 
@@ -22,8 +22,10 @@ import (
 
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/pkg/logutil"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/checker"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedulers"
@@ -53,6 +55,8 @@ var (
 	ErrSchedulerExisted = errors.New("scheduler existed")
 	// ErrSchedulerNotFound is error info for scheduler is not found.
 	ErrSchedulerNotFound = errors.New("scheduler not found")
+	// ErrSchedulerNotDiagnosable is error info for scheduler does not record diagnostic.
+	ErrSchedulerNotDiagnosable = errors.New("scheduler does not support diagnostic")
 )
 
 // coordinator is used to manage all schedulers and checkers to decide if the region needs to be scheduled.
@@ -75,6 +79,7 @@ type coordinator struct {
 func newCoordinator(ctx context.Context, cluster *RaftCluster, hbStreams opt.HeartbeatStreams) *coordinator {
 	ctx, cancel := context.WithCancel(ctx)
 	opController := schedule.NewOperatorController(ctx, cluster, hbStreams)
+	opController.SetHistoryStorage(schedule.NewOperatorHistoryStorage(cluster.storage))
 	return &coordinator{
 		ctx:             ctx,
 		cancel:          cancel,
@@ -332,6 +337,7 @@ type hasHotStatus interface {
 	GetHotWriteStatus() *statistics.StoreHotPeersInfos
 	GetWritePendingInfluence() map[uint64]schedulers.Influence
 	GetReadPendingInfluence() map[uint64]schedulers.Influence
+	GetStoreLoadVectors() map[string]map[uint64]*statistics.StoreLoadVector
 }
 
 func (c *coordinator) getHotWriteRegions() *statistics.StoreHotPeersInfos {
@@ -360,6 +366,19 @@ func (c *coordinator) getHotReadRegions() *statistics.StoreHotPeersInfos {
 	return nil
 }
 
+func (c *coordinator) getHotStoreLoads() map[string]map[uint64]*statistics.StoreLoadVector {
+	c.RLock()
+	defer c.RUnlock()
+	s, ok := c.schedulers[schedulers.HotRegionName]
+	if !ok {
+		return nil
+	}
+	if h, ok := s.Scheduler.(hasHotStatus); ok {
+		return h.GetStoreLoadVectors()
+	}
+	return nil
+}
+
 func (c *coordinator) getSchedulers() map[string]*scheduleController {
 	c.RLock()
 	defer c.RUnlock()
@@ -535,6 +554,56 @@ func (c *coordinator) pauseOrResumeScheduler(name string, t int64) error {
 	return err
 }
 
+// setScheduleWindows sets the time windows of a scheduler or a checker and
+// persists them.
+func (c *coordinator) setScheduleWindows(name string, windows []string) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.cluster == nil {
+		return ErrNotBootstrapped
+	}
+	if _, ok := c.schedulers[name]; !ok && name != checker.ReplicaCheckerName && name != checker.MergeCheckerName {
+		return ErrSchedulerNotFound
+	}
+	if _, err := typeutil.ParseTimeWindows(windows); err != nil {
+		return err
+	}
+	opt := c.cluster.opt
+	opt.SetScheduleWindows(name, windows)
+	return opt.Persist(c.cluster.storage)
+}
+
+func (c *coordinator) diagnoseScheduler(name string) (*schedule.DiagnosticResult, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.cluster == nil {
+		return nil, ErrNotBootstrapped
+	}
+	sc, ok := c.schedulers[name]
+	if !ok {
+		return nil, ErrSchedulerNotFound
+	}
+	ds, ok := sc.Scheduler.(schedule.DiagnosableScheduler)
+	if !ok {
+		return nil, ErrSchedulerNotDiagnosable
+	}
+	result := ds.GetDiagnosticRecorder().GetLastResult()
+	if result == nil {
+		result = &schedule.DiagnosticResult{Name: name, Status: schedule.DiagnosticStatusPending}
+	}
+	switch {
+	case sc.IsPaused():
+		result.Status = schedule.DiagnosticStatusPaused
+	case !sc.IsInScheduleWindow():
+		result.Status = schedule.DiagnosticStatusOutOfWindow
+	case !sc.Scheduler.IsScheduleAllowed(c.cluster):
+		result.Status = schedule.DiagnosticStatusDisallow
+	case result.Status == "":
+		result.Status = schedule.DiagnosticStatusNormal
+	}
+	return result, nil
+}
+
 func (c *coordinator) runScheduler(s *scheduleController) {
 	defer logutil.LogPanic()
 	defer c.wg.Done()
@@ -615,7 +684,12 @@ func (s *scheduleController) GetInterval() time.Duration {
 
 // AllowSchedule returns if a scheduler is allowed to schedule.
 func (s *scheduleController) AllowSchedule() bool {
-	return s.Scheduler.IsScheduleAllowed(s.cluster) && !s.IsPaused()
+	return s.Scheduler.IsScheduleAllowed(s.cluster) && !s.IsPaused() && s.IsInScheduleWindow()
+}
+
+// IsInScheduleWindow returns if it is in the schedule windows of the scheduler.
+func (s *scheduleController) IsInScheduleWindow() bool {
+	return s.cluster.IsInScheduleWindows(s.GetName(), time.Now())
 }
 
 // isPaused returns if a schedueler is paused.
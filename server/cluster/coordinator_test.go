@@ -15,6 +15,7 @@ package cluster
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
 	"sync"
 	"testing"
@@ -31,6 +32,7 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/checker"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedulers"
@@ -357,6 +359,7 @@ func (s *testCoordinatorSuite) TestCheckRegion(c *C) {
 func (s *testCoordinatorSuite) TestCheckerIsBusy(c *C) {
 	tc, co, cleanup := prepare(func(cfg *config.ScheduleConfig) {
 		cfg.ReplicaScheduleLimit = 0 // ensure replica checker is busy
+		cfg.SplitScheduleLimit = 0   // ensure split checker is busy
 		cfg.MergeScheduleLimit = 10
 	}, nil, func(co *coordinator) { co.run() }, c)
 	defer cleanup()
@@ -387,6 +390,121 @@ func (s *testCoordinatorSuite) TestCheckerIsBusy(c *C) {
 	s.checkRegion(c, tc, co, num, true, 0)
 }
 
+func (s *testCoordinatorSuite) TestScheduleWindows(c *C) {
+	tc, co, cleanup := prepare(nil, nil, func(co *coordinator) { co.run() }, c)
+	defer cleanup()
+
+	// A window two hours later never contains now.
+	later := (time.Now().Hour() + 2) % 24
+	outOfWindow := []string{fmt.Sprintf("%02d:00-%02d:30", later, later)}
+	allDay := []string{"00:00-00:00"}
+
+	c.Assert(co.setScheduleWindows("unknown-scheduler", allDay), Equals, ErrSchedulerNotFound)
+	c.Assert(co.setScheduleWindows(schedulers.BalanceLeaderName, []string{"01:00"}), NotNil)
+
+	sc := co.schedulers[schedulers.BalanceLeaderName]
+	c.Assert(sc.IsInScheduleWindow(), IsTrue)
+	c.Assert(co.setScheduleWindows(schedulers.BalanceLeaderName, outOfWindow), IsNil)
+	c.Assert(tc.opt.GetScheduleWindows(schedulers.BalanceLeaderName), DeepEquals, outOfWindow)
+	c.Assert(sc.IsInScheduleWindow(), IsFalse)
+	c.Assert(sc.AllowSchedule(), IsFalse)
+	result, err := co.diagnoseScheduler(schedulers.BalanceLeaderName)
+	c.Assert(err, IsNil)
+	c.Assert(result.Status, Equals, schedule.DiagnosticStatusOutOfWindow)
+	c.Assert(co.setScheduleWindows(schedulers.BalanceLeaderName, allDay), IsNil)
+	c.Assert(sc.IsInScheduleWindow(), IsTrue)
+	c.Assert(co.setScheduleWindows(schedulers.BalanceLeaderName, nil), IsNil)
+	c.Assert(tc.opt.GetScheduleWindows(schedulers.BalanceLeaderName), IsNil)
+
+	// The windows are persisted.
+	c.Assert(co.setScheduleWindows(checker.ReplicaCheckerName, outOfWindow), IsNil)
+	cfg := &config.Config{}
+	_, err = tc.storage.LoadConfig(cfg)
+	c.Assert(err, IsNil)
+	c.Assert(cfg.Schedule.ScheduleWindows[checker.ReplicaCheckerName], DeepEquals, outOfWindow)
+
+	// The replica checker does not remove the extra replica out of its
+	// windows, but still makes up the missing replica.
+	c.Assert(tc.addRegionStore(4, 4), IsNil)
+	c.Assert(tc.addRegionStore(3, 3), IsNil)
+	c.Assert(tc.addRegionStore(2, 2), IsNil)
+	c.Assert(tc.addRegionStore(1, 1), IsNil)
+	c.Assert(tc.addLeaderRegion(1, 1, 2, 3, 4), IsNil)
+	s.checkRegion(c, tc, co, 1, false, 0)
+	c.Assert(tc.addLeaderRegion(2, 2, 3), IsNil)
+	s.checkRegion(c, tc, co, 2, false, 1)
+	c.Assert(co.setScheduleWindows(checker.ReplicaCheckerName, allDay), IsNil)
+	s.checkRegion(c, tc, co, 1, false, 1)
+}
+
+func (s *testCoordinatorSuite) TestStoreProgress(c *C) {
+	tc, co, cleanup := prepare(nil, nil, nil, c)
+	defer cleanup()
+	tc.RaftCluster.coordinator = co
+
+	c.Assert(tc.addRegionStore(1, 2), IsNil)
+	c.Assert(tc.addRegionStore(2, 2), IsNil)
+	c.Assert(tc.addRegionStore(3, 2), IsNil)
+	c.Assert(tc.addLeaderRegion(1, 2, 1, 3), IsNil)
+	c.Assert(tc.addLeaderRegion(2, 2, 1, 3), IsNil)
+
+	// The regions are stuck since there is no other store.
+	c.Assert(tc.setStoreOffline(1), IsNil)
+	progress, err := tc.GetStoreProgress(1)
+	c.Assert(err, IsNil)
+	c.Assert(progress.Action, Equals, StoreProgressRemoving)
+	c.Assert(progress.StartRegionCount, Equals, 2)
+	c.Assert(progress.RemainingRegionCount, Equals, 2)
+	c.Assert(progress.StuckRegions, HasLen, 2)
+	c.Assert(progress.EstimatedFinishTime, IsNil)
+
+	c.Assert(tc.addRegionStore(4, 0), IsNil)
+	progress, err = tc.GetStoreProgress(1)
+	c.Assert(err, IsNil)
+	c.Assert(progress.StuckRegions, HasLen, 0)
+	progress, err = tc.GetStoreProgress(4)
+	c.Assert(err, IsNil)
+	c.Assert(progress.Action, Equals, StoreProgressPreparing)
+	c.Assert(progress.TargetRegionCount, Equals, 1)
+	c.Assert(progress.RemainingRegionCount, Equals, 1)
+
+	// Move a region from store 1 to store 4.
+	region := tc.GetRegion(1)
+	op := operator.NewOperator("test", "test", 1, region.GetRegionEpoch(), operator.OpRegion,
+		operator.AddPeer{ToStore: 4, PeerID: 100},
+		operator.RemovePeer{FromStore: 1},
+	)
+	c.Assert(co.opController.AddOperator(op), IsTrue)
+	region = region.Clone(core.WithAddPeer(&metapb.Peer{Id: 100, StoreId: 4}), core.WithRemoveStorePeer(1))
+	c.Assert(tc.putRegion(region), IsNil)
+	co.opController.Dispatch(region, schedule.DispatchFromHeartBeat)
+	c.Assert(op.Status(), Equals, operator.SUCCESS)
+
+	progress, err = tc.GetStoreProgress(1)
+	c.Assert(err, IsNil)
+	c.Assert(progress.CurrentRegionCount, Equals, 1)
+	c.Assert(progress.RemainingRegionCount, Equals, 1)
+	c.Assert(progress.Rate, Greater, float64(0))
+	c.Assert(progress.EstimatedFinishTime, NotNil)
+	// The preparing progress stops when the store is filled up.
+	_, err = tc.GetStoreProgress(4)
+	c.Assert(err, NotNil)
+	c.Assert(tc.storeProgresses, Not(HasKey), uint64(4))
+
+	// The preparing progress stops after a bounded time.
+	c.Assert(tc.addRegionStore(5, 0), IsNil)
+	_, err = tc.GetStoreProgress(5)
+	c.Assert(err, IsNil)
+	tc.storeProgresses[5].time = time.Now().Add(-maxStorePreparingTime - time.Minute)
+	_, err = tc.GetStoreProgress(5)
+	c.Assert(err, NotNil)
+
+	// The progress stops when the store is up again.
+	c.Assert(tc.SetStoreState(1, metapb.StoreState_Up), IsNil)
+	_, err = tc.GetStoreProgress(1)
+	c.Assert(err, NotNil)
+}
+
 func (s *testCoordinatorSuite) TestReplica(c *C) {
 	tc, co, cleanup := prepare(func(cfg *config.ScheduleConfig) {
 		// Turn off balance.
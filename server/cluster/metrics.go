@@ -71,6 +71,14 @@ var (
 			Name:      "cluster_state_current",
 			Help:      "Current state of the cluster",
 		}, []string{"state"})
+
+	storeLimitAdaptiveRateGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "store_limit_adaptive_rate",
+			Help:      "Limit of the store adjusted in the adaptive mode, in operators per minute",
+		}, []string{"store"})
 )
 
 func init() {
@@ -81,4 +89,5 @@ func init() {
 	prometheus.MustRegister(patrolCheckRegionsHistogram)
 	prometheus.MustRegister(clusterStateCPUGuage)
 	prometheus.MustRegister(clusterStateCurrent)
+	prometheus.MustRegister(storeLimitAdaptiveRateGauge)
 }
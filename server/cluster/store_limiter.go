@@ -14,7 +14,9 @@
 package cluster
 
 import (
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
@@ -22,6 +24,26 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// adaptiveCongestedSnapCount is the number of the snapshots being sent,
+	// received or applied by a store, above which the store is considered
+	// falling behind.
+	adaptiveCongestedSnapCount = 4
+	// adaptiveIdleSnapCount is the number of the snapshots being sent,
+	// received or applied by a store, below which the store is considered
+	// keeping up.
+	adaptiveIdleSnapCount = 1
+	// adaptiveSlowAddLearnerDuration is the duration of adding a learner to a
+	// store, above which the store is considered falling behind.
+	adaptiveSlowAddLearnerDuration = 30 * time.Second
+	// adaptiveIncreaseStep is the number of operators per minute added to the
+	// limit of a store which keeps up.
+	adaptiveIncreaseStep = 2
+	// adaptiveDecreaseRatio is the ratio the limit of a store falling behind
+	// is multiplied by.
+	adaptiveDecreaseRatio = 0.5
+)
+
 // StoreLimiter adjust the store limit dynamically
 type StoreLimiter struct {
 	m       sync.RWMutex
@@ -29,6 +51,9 @@ type StoreLimiter struct {
 	scene   *schedule.StoreLimitScene
 	state   *State
 	current LoadState
+	// rates are the limits of the stores in the adaptive mode, in operators
+	// per minute.
+	rates map[uint64]float64
 }
 
 // NewStoreLimiter builds a store limiter object using the operator controller
@@ -38,6 +63,7 @@ func NewStoreLimiter(c *schedule.OperatorController) *StoreLimiter {
 		state:   NewState(),
 		scene:   schedule.DefaultStoreLimitScene(),
 		current: LoadStateNone,
+		rates:   make(map[uint64]float64),
 	}
 }
 
@@ -64,12 +90,66 @@ func (s *StoreLimiter) Collect(stats *pdpb.StoreStats) {
 
 	if rate > 0 {
 		s.oc.SetAllStoresLimitAuto(rate)
+		// The adapted limits are replaced, so they are set again if the mode
+		// turns to adaptive.
+		if len(s.rates) > 0 {
+			s.rates = make(map[uint64]float64)
+		}
 		log.Info("change store limit for cluster", zap.Stringer("state", state), zap.Float64("rate", rate))
 		s.current = state
 		collectClusterStateCurrent(state)
 	}
 }
 
+// Adapt adjusts the limit of the store in the heartbeat. The limit increases
+// while the store keeps up with the snapshots, and backs off when the
+// snapshots pile up or adding learners to the store is slow. The limit is
+// bounded by the limits of the high and idle scenes.
+func (s *StoreLimiter) Adapt(stats *pdpb.StoreStats) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	storeID := stats.GetStoreId()
+	rate, ok := s.rates[storeID]
+	if !ok {
+		rate = float64(s.scene.Normal)
+	}
+	snapCount := stats.GetSendingSnapCount()
+	if count := stats.GetReceivingSnapCount() + stats.GetApplyingSnapCount(); count > snapCount {
+		snapCount = count
+	}
+	duration := s.oc.GetAddLearnerDuration(storeID)
+	switch {
+	case snapCount > adaptiveCongestedSnapCount || duration > adaptiveSlowAddLearnerDuration:
+		rate *= adaptiveDecreaseRatio
+	case snapCount <= adaptiveIdleSnapCount && duration <= adaptiveSlowAddLearnerDuration/2:
+		rate += adaptiveIncreaseStep
+	}
+	if upper := float64(s.scene.Idle); rate > upper {
+		rate = upper
+	}
+	if lower := float64(s.scene.High); rate < lower {
+		rate = lower
+	}
+
+	if old, ok := s.rates[storeID]; ok && old == rate {
+		return
+	}
+	log.Debug("adapt store limit", zap.Uint64("store-id", storeID), zap.Uint32("snap-count", snapCount), zap.Duration("add-learner-duration", duration), zap.Float64("rate", rate))
+	s.rates[storeID] = rate
+	s.oc.SetStoreLimitAuto(storeID, rate/schedule.StoreBalanceBaseTime)
+	storeLimitAdaptiveRateGauge.WithLabelValues(strconv.FormatUint(storeID, 10)).Set(rate)
+}
+
+// RemoveStore forgets the adapted limit of a store which is buried or
+// removed.
+func (s *StoreLimiter) RemoveStore(storeID uint64) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	delete(s.rates, storeID)
+	storeLimitAdaptiveRateGauge.DeleteLabelValues(strconv.FormatUint(storeID, 10))
+}
+
 func collectClusterStateCurrent(state LoadState) {
 	for i := LoadStateNone; i <= LoadStateHigh; i++ {
 		if i == state {
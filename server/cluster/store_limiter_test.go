@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
 )
 
@@ -37,6 +38,8 @@ func (s *testStoreLimiterSuite) SetUpSuite(c *C) {
 	// Create a server for testing
 	opt := mockoption.NewScheduleOptions()
 	cluster := mockcluster.NewCluster(opt)
+	cluster.AddLeaderStore(1, 0)
+	cluster.AddLeaderStore(2, 0)
 	s.oc = schedule.NewOperatorController(ctx, cluster, nil)
 }
 func (s *testStoreLimiterSuite) TearDownSuite(c *C) {
@@ -63,3 +66,46 @@ func (s *testStoreLimiterSuite) TestReplaceStoreLimitScene(c *C) {
 
 	c.Assert(limiter.scene, DeepEquals, scene)
 }
+
+func (s *testStoreLimiterSuite) TestAdapt(c *C) {
+	limiter := NewStoreLimiter(s.oc)
+	scene := &schedule.StoreLimitScene{Idle: 20, Low: 15, Normal: 10, High: 4}
+	limiter.ReplaceStoreLimitScene(scene)
+
+	// The limit increases while the store keeps up.
+	limiter.Adapt(&pdpb.StoreStats{StoreId: 1})
+	c.Assert(limiter.rates[1], Equals, float64(scene.Normal+adaptiveIncreaseStep))
+	for i := 0; i < 10; i++ {
+		limiter.Adapt(&pdpb.StoreStats{StoreId: 1, ReceivingSnapCount: 1})
+	}
+	c.Assert(limiter.rates[1], Equals, float64(scene.Idle))
+
+	// The limit holds if there are some snapshots in flight, and its bucket is
+	// not refilled.
+	key := schedule.StoreLimitKey{Type: core.StoreLimitAddPeer}
+	limit := s.oc.GetAllStoresLimit()[1][key]
+	limiter.Adapt(&pdpb.StoreStats{StoreId: 1, SendingSnapCount: 3})
+	c.Assert(limiter.rates[1], Equals, float64(scene.Idle))
+	c.Assert(s.oc.GetAllStoresLimit()[1][key], Equals, limit)
+
+	// The limit backs off when the snapshots pile up, and the tokens taken
+	// from its bucket are kept.
+	limit.Take(limit.Available())
+	limiter.Adapt(&pdpb.StoreStats{StoreId: 1, ReceivingSnapCount: 3, ApplyingSnapCount: 3})
+	c.Assert(limiter.rates[1], Equals, float64(scene.Idle)*adaptiveDecreaseRatio)
+	c.Assert(s.oc.GetAllStoresLimit()[1][key].Available() <= limit.Available(), IsTrue)
+	for i := 0; i < 10; i++ {
+		limiter.Adapt(&pdpb.StoreStats{StoreId: 1, SendingSnapCount: 5})
+	}
+	c.Assert(limiter.rates[1], Equals, float64(scene.High))
+
+	// The limits of the stores are adjusted separately.
+	limiter.Adapt(&pdpb.StoreStats{StoreId: 2})
+	c.Assert(limiter.rates[2], Equals, float64(scene.Normal+adaptiveIncreaseStep))
+
+	// The limit of a removed store is forgotten.
+	limiter.RemoveStore(2)
+	_, ok := limiter.rates[2]
+	c.Assert(ok, IsFalse)
+	c.Assert(limiter.rates[1], Equals, float64(scene.High))
+}
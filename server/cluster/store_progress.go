@@ -0,0 +1,271 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	"github.com/pingcap/errcode"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
+	"github.com/pkg/errors"
+)
+
+const (
+	// StoreProgressRemoving is the action of moving all regions out of an
+	// offline store.
+	StoreProgressRemoving = "removing"
+	// StoreProgressPreparing is the action of filling up a new store.
+	StoreProgressPreparing = "preparing"
+
+	// storeProgressRateWindow is the window to calculate the recent rate of
+	// moving regions, which is not longer than the keep time of the operator
+	// history.
+	storeProgressRateWindow = 5 * time.Minute
+	// maxStuckRegionCount is the max number of the stuck regions reported.
+	maxStuckRegionCount = 64
+	// maxStuckCheckRegionCount is the max number of the regions of a store
+	// checked for being stuck, which are sampled if the store has more.
+	maxStuckCheckRegionCount = 1024
+	// maxStorePreparingTime is the max time to track a preparing store, after
+	// which it is considered filled up even if it holds fewer regions than
+	// the average, e.g. since its capacity is smaller than the others.
+	maxStorePreparingTime = 24 * time.Hour
+	storeProgressScope    = "store-progress"
+)
+
+// storeProgressStart records where the progress of a store starts.
+type storeProgressStart struct {
+	action      string
+	time        time.Time
+	regionCount int
+	regionSize  int64
+}
+
+// StoreProgress is the progress of removing an offline store or filling up a
+// new store.
+type StoreProgress struct {
+	StoreID          uint64    `json:"store_id"`
+	Action           string    `json:"action"`
+	StartTime        time.Time `json:"start_time"`
+	StartRegionCount int       `json:"start_region_count"`
+	StartRegionSize  int64     `json:"start_region_size"`
+	// The target is empty for removing, and the average of the up stores
+	// for preparing.
+	TargetRegionCount    int   `json:"target_region_count"`
+	TargetRegionSize     int64 `json:"target_region_size"`
+	CurrentRegionCount   int   `json:"current_region_count"`
+	CurrentRegionSize    int64 `json:"current_region_size"`
+	RemainingRegionCount int   `json:"remaining_region_count"`
+	RemainingRegionSize  int64 `json:"remaining_region_size"`
+	// Rate is the number of regions moved out of or into the store per
+	// second recently.
+	Rate float64 `json:"rate"`
+	// The estimation is unset if no region is moved recently.
+	LeftSeconds         float64    `json:"left_seconds,omitempty"`
+	EstimatedFinishTime *time.Time `json:"estimated_finish_time,omitempty"`
+	// StuckRegions are the regions of a removing store which cannot be moved
+	// since no store fits them.
+	StuckRegions []uint64 `json:"stuck_regions,omitempty"`
+}
+
+// updateStoreProgressLocked starts recording the progress when a store is
+// added or set offline, and stops when the store is up again or buried, or
+// has been prepared for too long.
+func (c *RaftCluster) updateStoreProgressLocked(old, store *core.StoreInfo) {
+	if c.storeProgresses == nil {
+		c.storeProgresses = make(map[uint64]*storeProgressStart)
+	}
+	storeID := store.GetID()
+	switch {
+	case old == nil:
+		c.storeProgresses[storeID] = &storeProgressStart{
+			action: StoreProgressPreparing,
+			time:   time.Now(),
+		}
+	case store.IsOffline() && !old.IsOffline():
+		c.storeProgresses[storeID] = &storeProgressStart{
+			action:      StoreProgressRemoving,
+			time:        time.Now(),
+			regionCount: c.core.GetStoreRegionCount(storeID),
+			regionSize:  c.core.GetStoreRegionSize(storeID),
+		}
+	case store.IsTombstone() || (store.IsUp() && old.IsOffline()):
+		delete(c.storeProgresses, storeID)
+	default:
+		if start, ok := c.storeProgresses[storeID]; ok && start.isPreparingExpired() {
+			delete(c.storeProgresses, storeID)
+		}
+	}
+}
+
+func (s *storeProgressStart) isPreparingExpired() bool {
+	return s.action == StoreProgressPreparing && time.Since(s.time) > maxStorePreparingTime
+}
+
+// finishStoreProgress stops recording the progress of a store if it has not
+// been restarted.
+func (c *RaftCluster) finishStoreProgress(storeID uint64, start *storeProgressStart) {
+	c.Lock()
+	defer c.Unlock()
+	if c.storeProgresses[storeID] == start {
+		delete(c.storeProgresses, storeID)
+	}
+}
+
+// GetStoreProgress returns the progress of removing an offline store or
+// filling up a new store.
+func (c *RaftCluster) GetStoreProgress(storeID uint64) (*StoreProgress, error) {
+	store := c.GetStore(storeID)
+	if store == nil {
+		return nil, core.NewStoreNotFoundErr(storeID)
+	}
+	c.RLock()
+	start, ok := c.storeProgresses[storeID]
+	c.RUnlock()
+	if ok && start.isPreparingExpired() {
+		c.finishStoreProgress(storeID, start)
+		ok = false
+	}
+	if !ok {
+		// The progress is lost after the leader of PD changes, so it starts
+		// from now for an offline store.
+		if !store.IsOffline() {
+			return nil, errcode.NewNotFoundErr(errors.Errorf("store %d is neither being removed nor being prepared", storeID))
+		}
+		start = &storeProgressStart{
+			action:      StoreProgressRemoving,
+			time:        time.Now(),
+			regionCount: c.core.GetStoreRegionCount(storeID),
+			regionSize:  c.core.GetStoreRegionSize(storeID),
+		}
+	}
+
+	progress := &StoreProgress{
+		StoreID:            storeID,
+		Action:             start.action,
+		StartTime:          start.time,
+		StartRegionCount:   start.regionCount,
+		StartRegionSize:    start.regionSize,
+		CurrentRegionCount: c.core.GetStoreRegionCount(storeID),
+		CurrentRegionSize:  c.core.GetStoreRegionSize(storeID),
+	}
+	if start.action == StoreProgressRemoving {
+		progress.RemainingRegionCount = progress.CurrentRegionCount
+		progress.RemainingRegionSize = progress.CurrentRegionSize
+		progress.StuckRegions = c.getStuckRegions(store)
+	} else {
+		progress.TargetRegionCount, progress.TargetRegionSize = c.getAverageUpStoreRegions()
+		// The store is filled up once it holds as many regions as the others.
+		if progress.CurrentRegionCount >= progress.TargetRegionCount {
+			c.finishStoreProgress(storeID, start)
+			return nil, errcode.NewNotFoundErr(errors.Errorf("store %d is neither being removed nor being prepared", storeID))
+		}
+		progress.RemainingRegionCount = progress.TargetRegionCount - progress.CurrentRegionCount
+		if remaining := progress.TargetRegionSize - progress.CurrentRegionSize; remaining > 0 {
+			progress.RemainingRegionSize = remaining
+		}
+	}
+
+	progress.Rate = c.getStoreMoveRate(storeID, start)
+	if progress.Rate > 0 {
+		progress.LeftSeconds = float64(progress.RemainingRegionCount) / progress.Rate
+		finish := time.Now().Add(time.Duration(progress.LeftSeconds * float64(time.Second)))
+		progress.EstimatedFinishTime = &finish
+	}
+	return progress, nil
+}
+
+// getStoreMoveRate returns the number of regions moved out of a removing
+// store or into a preparing store per second recently.
+func (c *RaftCluster) getStoreMoveRate(storeID uint64, start *storeProgressStart) float64 {
+	now := time.Now()
+	since := now.Add(-storeProgressRateWindow)
+	if start.time.After(since) {
+		since = start.time
+	}
+	elapsed := now.Sub(since).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	var count int
+	for _, h := range c.coordinator.opController.GetHistory(since) {
+		if h.Kind != core.RegionKind {
+			continue
+		}
+		if (start.action == StoreProgressRemoving && h.From == storeID) ||
+			(start.action == StoreProgressPreparing && h.To == storeID) {
+			count++
+		}
+	}
+	return float64(count) / elapsed
+}
+
+// getAverageUpStoreRegions returns the average region count and size of the
+// up stores, which a new store is expected to hold after balanced.
+func (c *RaftCluster) getAverageUpStoreRegions() (int, int64) {
+	var count, totalCount int
+	var totalSize int64
+	for _, s := range c.GetStores() {
+		if !s.IsUp() {
+			continue
+		}
+		count++
+		totalCount += c.core.GetStoreRegionCount(s.GetID())
+		totalSize += c.core.GetStoreRegionSize(s.GetID())
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return totalCount / count, totalSize / int64(count)
+}
+
+// getStuckRegions returns the regions of the removing store which have no
+// operator and cannot be moved since no store fits them. Only a sample of the
+// regions is checked if the store has too many.
+func (c *RaftCluster) getStuckRegions(store *core.StoreInfo) []uint64 {
+	var regions []*core.RegionInfo
+	if c.core.GetStoreRegionCount(store.GetID()) <= maxStuckCheckRegionCount {
+		regions = c.core.GetStoreRegions(store.GetID())
+	} else {
+		regions = c.core.RandStoreRegions(store.GetID(), maxStuckCheckRegionCount)
+	}
+	var stuck []uint64
+	checked := make(map[uint64]struct{}, len(regions))
+	stores := c.GetStores()
+	for _, region := range regions {
+		if _, ok := checked[region.GetID()]; ok {
+			continue
+		}
+		checked[region.GetID()] = struct{}{}
+		if c.coordinator.opController.GetOperator(region.GetID()) != nil {
+			continue
+		}
+		filters := []filter.Filter{
+			filter.NewStateFilter(storeProgressScope),
+			filter.NewExcludedFilter(storeProgressScope, nil, region.GetStoreIds()),
+			filter.NewStorageThresholdFilter(storeProgressScope),
+		}
+		if c.IsPlacementRulesEnabled() {
+			filters = append(filters, filter.NewRuleFitFilter(storeProgressScope, c, region, store.GetID()))
+		}
+		if len(filter.SelectTargetStores(stores, filters, c)) == 0 {
+			stuck = append(stuck, region.GetID())
+			if len(stuck) >= maxStuckRegionCount {
+				break
+			}
+		}
+	}
+	return stuck
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pingcap/pd/v4/pkg/metricutil"
 	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/embed"
@@ -67,6 +68,13 @@ type Config struct {
 	// Etcd only supports seconds TTL, so here is second too.
 	LeaderLease int64 `toml:"lease" json:"lease"`
 
+	// EnableAutoLeaderPriority makes the leader priority follow the health
+	// of the members, which is scored by the disk, etcd and TSO latency. The
+	// leader steps down if its score stays below LeaderHealthScoreThreshold,
+	// and an unhealthy member does not take over the leadership by priority.
+	EnableAutoLeaderPriority   bool    `toml:"enable-auto-leader-priority" json:"enable-auto-leader-priority"`
+	LeaderHealthScoreThreshold float64 `toml:"leader-health-score-threshold" json:"leader-health-score-threshold"`
+
 	// Log related config.
 	Log log.Config `toml:"log" json:"log"`
 
@@ -77,6 +85,11 @@ type Config struct {
 	// TsoSaveInterval is the interval to save timestamp.
 	TsoSaveInterval typeutil.Duration `toml:"tso-save-interval" json:"tso-save-interval"`
 
+	// DCLocation is the data center where the member is deployed. The members
+	// in the same data center elect a local TSO allocator, which serves the
+	// local timestamps of the data center. Empty means no local allocator.
+	DCLocation string `toml:"dc-location" json:"dc-location"`
+
 	Metric metricutil.MetricConfig `toml:"metric" json:"metric"`
 
 	Schedule ScheduleConfig `toml:"schedule" json:"schedule"`
@@ -198,6 +211,7 @@ const (
 	defaultHeartbeatStreamRebindInterval = time.Minute
 
 	defaultLeaderPriorityCheckInterval = time.Minute
+	defaultLeaderHealthScoreThreshold  = 60
 
 	defaultUseRegionStorage = true
 	defaultMaxResetTsGap    = 24 * time.Hour
@@ -410,6 +424,7 @@ func (c *Config) Adjust(meta *toml.MetaData) error {
 	}
 
 	adjustInt64(&c.LeaderLease, defaultLeaderLease)
+	adjustFloat64(&c.LeaderHealthScoreThreshold, defaultLeaderHealthScoreThreshold)
 
 	adjustDuration(&c.TsoSaveInterval, time.Duration(defaultLeaderLease)*time.Second)
 
@@ -502,6 +517,10 @@ type ScheduleConfig struct {
 	// MaxStoreDownTime is the max duration after which
 	// a store will be considered to be down if it hasn't reported heartbeats.
 	MaxStoreDownTime typeutil.Duration `toml:"max-store-down-time" json:"max-store-down-time"`
+	// MaxOperatorStepWaitTime is the max duration that a step of an operator
+	// can run. The operator will be considered timeout if any of its steps
+	// runs longer than it. 0 means no limit, which needs to be set explicitly.
+	MaxOperatorStepWaitTime typeutil.Duration `toml:"max-operator-step-wait-time" json:"max-operator-step-wait-time"`
 	// LeaderScheduleLimit is the max coexist leader schedules.
 	LeaderScheduleLimit uint64 `toml:"leader-schedule-limit" json:"leader-schedule-limit"`
 	// LeaderSchedulePolicy is the option to balance leader, there are some policies supported: ["count", "size"], default: "count"
@@ -512,6 +531,9 @@ type ScheduleConfig struct {
 	ReplicaScheduleLimit uint64 `toml:"replica-schedule-limit" json:"replica-schedule-limit"`
 	// MergeScheduleLimit is the max coexist merge schedules.
 	MergeScheduleLimit uint64 `toml:"merge-schedule-limit" json:"merge-schedule-limit"`
+	// SplitScheduleLimit is the max coexist split schedules of the regions
+	// which are too hot to be balanced. 0 disables it, which is the default.
+	SplitScheduleLimit uint64 `toml:"split-schedule-limit" json:"split-schedule-limit"`
 	// HotRegionScheduleLimit is the max coexist hot region schedules.
 	HotRegionScheduleLimit uint64 `toml:"hot-region-schedule-limit" json:"hot-region-schedule-limit"`
 	// HotRegionCacheHitThreshold is the cache hits threshold of the hot region.
@@ -572,6 +594,14 @@ type ScheduleConfig struct {
 	EnableLocationReplacement bool `toml:"enable-location-replacement" json:"enable-location-replacement,string"`
 	// EnableDebugMetrics is the option to enable debug metrics.
 	EnableDebugMetrics bool `toml:"enable-debug-metrics" json:"enable-debug-metrics,string"`
+	// EnableOperatorHistory is the option to persist the ended operators,
+	// which can be queried by the operator history API.
+	EnableOperatorHistory bool `toml:"enable-operator-history" json:"enable-operator-history,string"`
+	// OperatorHistoryRetention is how long the persisted operators are kept.
+	OperatorHistoryRetention typeutil.Duration `toml:"operator-history-retention" json:"operator-history-retention"`
+	// MaxOperatorHistoryRecords is the max number of the persisted operators,
+	// beyond which the oldest ones are removed.
+	MaxOperatorHistoryRecords uint64 `toml:"max-operator-history-records" json:"max-operator-history-records"`
 
 	// Schedulers support for loading customized schedulers
 	Schedulers SchedulerConfigs `toml:"schedulers" json:"schedulers-v2"` // json v2 is for the sake of compatible upgrade
@@ -579,19 +609,53 @@ type ScheduleConfig struct {
 	// Only used to display
 	SchedulersPayload map[string]string `toml:"schedulers-payload" json:"schedulers-payload"`
 
-	// StoreLimitMode can be auto or manual, when set to auto,
+	// StoreLimitMode can be auto, adaptive or manual, when set to auto,
 	// PD tries to change the store limit values according to
-	// the load state of the cluster dynamically. User can
+	// the load state of the cluster dynamically. When set to adaptive,
+	// PD adjusts the limit of each store according to the snapshots
+	// in its heartbeats and how long adding learners to it takes,
+	// bounded by the limits of the high and idle scenes. User can
 	// overwrite the auto-tuned value by pd-ctl, when the value
 	// is overwritten, the value is fixed until it is deleted.
 	// Default: manual
 	StoreLimitMode string `toml:"store-limit-mode" json:"store-limit-mode"`
+
+	// RegionWeightMode can be manual or capacity, when set to capacity,
+	// balance-region derives the region weights of stores from their
+	// capacities, so that stores with larger disks hold more regions.
+	// Default: manual
+	RegionWeightMode string `toml:"region-weight-mode" json:"region-weight-mode"`
+	// StoreClassWeights is the weight of each store class, which is the value
+	// of the `store-class` label. It only takes effect when the region weight
+	// mode is capacity. A store class without weight is regarded as 1.
+	StoreClassWeights map[string]float64 `toml:"store-class-weights" json:"store-class-weights"`
+
+	// ScheduleWindows are the time windows in which a scheduler or a checker
+	// is allowed to run, keyed by the scheduler name, `merge-checker` or
+	// `replica-checker`. A window is like "Mon-Fri 01:00-06:00" in the local
+	// time zone of the PD leader, so the PD servers are expected to share
+	// the same time zone. The ones without windows can run at any time.
+	ScheduleWindows map[string][]string `toml:"schedule-windows" json:"schedule-windows"`
 }
 
 // Clone returns a cloned scheduling configuration.
 func (c *ScheduleConfig) Clone() *ScheduleConfig {
 	schedulers := make(SchedulerConfigs, len(c.Schedulers))
 	copy(schedulers, c.Schedulers)
+	var storeClassWeights map[string]float64
+	if c.StoreClassWeights != nil {
+		storeClassWeights = make(map[string]float64, len(c.StoreClassWeights))
+		for class, weight := range c.StoreClassWeights {
+			storeClassWeights[class] = weight
+		}
+	}
+	var scheduleWindows map[string][]string
+	if c.ScheduleWindows != nil {
+		scheduleWindows = make(map[string][]string, len(c.ScheduleWindows))
+		for name, windows := range c.ScheduleWindows {
+			scheduleWindows[name] = append([]string(nil), windows...)
+		}
+	}
 	return &ScheduleConfig{
 		MaxSnapshotCount:             c.MaxSnapshotCount,
 		MaxPendingPeerCount:          c.MaxPendingPeerCount,
@@ -600,11 +664,13 @@ func (c *ScheduleConfig) Clone() *ScheduleConfig {
 		SplitMergeInterval:           c.SplitMergeInterval,
 		PatrolRegionInterval:         c.PatrolRegionInterval,
 		MaxStoreDownTime:             c.MaxStoreDownTime,
+		MaxOperatorStepWaitTime:      c.MaxOperatorStepWaitTime,
 		LeaderScheduleLimit:          c.LeaderScheduleLimit,
 		LeaderSchedulePolicy:         c.LeaderSchedulePolicy,
 		RegionScheduleLimit:          c.RegionScheduleLimit,
 		ReplicaScheduleLimit:         c.ReplicaScheduleLimit,
 		MergeScheduleLimit:           c.MergeScheduleLimit,
+		SplitScheduleLimit:           c.SplitScheduleLimit,
 		EnableOneWayMerge:            c.EnableOneWayMerge,
 		EnableCrossTableMerge:        c.EnableCrossTableMerge,
 		HotRegionScheduleLimit:       c.HotRegionScheduleLimit,
@@ -626,7 +692,13 @@ func (c *ScheduleConfig) Clone() *ScheduleConfig {
 		EnableRemoveExtraReplica:     c.EnableRemoveExtraReplica,
 		EnableLocationReplacement:    c.EnableLocationReplacement,
 		EnableDebugMetrics:           c.EnableDebugMetrics,
+		EnableOperatorHistory:        c.EnableOperatorHistory,
+		OperatorHistoryRetention:     c.OperatorHistoryRetention,
+		MaxOperatorHistoryRecords:    c.MaxOperatorHistoryRecords,
 		StoreLimitMode:               c.StoreLimitMode,
+		RegionWeightMode:             c.RegionWeightMode,
+		StoreClassWeights:            storeClassWeights,
+		ScheduleWindows:              scheduleWindows,
 		Schedulers:                   schedulers,
 	}
 }
@@ -655,6 +727,12 @@ const (
 	defaultSchedulerMaxWaitingOperator = 5
 	defaultLeaderSchedulePolicy        = "count"
 	defaultStoreLimitMode              = "manual"
+	defaultRegionWeightMode            = "manual"
+	defaultOperatorHistoryRetention    = 6 * time.Hour
+	defaultMaxOperatorHistoryRecords   = 10000
+	// defaultMaxOperatorStepWaitTime is long enough for a step to send a
+	// large snapshot, so that only the stuck operators are canceled.
+	defaultMaxOperatorStepWaitTime = 10 * time.Minute
 )
 
 func (c *ScheduleConfig) adjust(meta *configMetaData) error {
@@ -673,6 +751,13 @@ func (c *ScheduleConfig) adjust(meta *configMetaData) error {
 	adjustDuration(&c.SplitMergeInterval, defaultSplitMergeInterval)
 	adjustDuration(&c.PatrolRegionInterval, defaultPatrolRegionInterval)
 	adjustDuration(&c.MaxStoreDownTime, defaultMaxStoreDownTime)
+	if !meta.IsDefined("max-operator-step-wait-time") {
+		adjustDuration(&c.MaxOperatorStepWaitTime, defaultMaxOperatorStepWaitTime)
+	}
+	adjustDuration(&c.OperatorHistoryRetention, defaultOperatorHistoryRetention)
+	if !meta.IsDefined("max-operator-history-records") {
+		adjustUint64(&c.MaxOperatorHistoryRecords, defaultMaxOperatorHistoryRecords)
+	}
 	if !meta.IsDefined("leader-schedule-limit") {
 		adjustUint64(&c.LeaderScheduleLimit, defaultLeaderScheduleLimit)
 	}
@@ -703,10 +788,21 @@ func (c *ScheduleConfig) adjust(meta *configMetaData) error {
 	if !meta.IsDefined("store-limit-mode") {
 		adjustString(&c.StoreLimitMode, defaultStoreLimitMode)
 	}
+	if !meta.IsDefined("region-weight-mode") {
+		adjustString(&c.RegionWeightMode, defaultRegionWeightMode)
+	}
 	adjustFloat64(&c.StoreBalanceRate, defaultStoreBalanceRate)
 	adjustFloat64(&c.LowSpaceRatio, defaultLowSpaceRatio)
 	adjustFloat64(&c.HighSpaceRatio, defaultHighSpaceRatio)
 	adjustSchedulers(&c.Schedulers, defaultSchedulers)
+	// The config manager can only update the items that exist, so an empty
+	// map is kept rather than nil.
+	if c.StoreClassWeights == nil {
+		c.StoreClassWeights = make(map[string]float64)
+	}
+	if c.ScheduleWindows == nil {
+		c.ScheduleWindows = make(map[string][]string)
+	}
 
 	for k, b := range c.migrateConfigurationMap() {
 		v, err := c.parseDeprecatedFlag(meta, k, *b[0], *b[1])
@@ -774,6 +870,19 @@ func (c *ScheduleConfig) Validate() error {
 	if c.LowSpaceRatio <= c.HighSpaceRatio {
 		return errors.New("low-space-ratio should be larger than high-space-ratio")
 	}
+	if c.RegionWeightMode != core.ManualRegionWeight.String() && c.RegionWeightMode != core.CapacityRegionWeight.String() {
+		return errors.Errorf("region-weight-mode should be %s or %s", core.ManualRegionWeight, core.CapacityRegionWeight)
+	}
+	for class, weight := range c.StoreClassWeights {
+		if weight <= 0 {
+			return errors.Errorf("the weight of store class %s should be positive", class)
+		}
+	}
+	for name, windows := range c.ScheduleWindows {
+		if _, err := typeutil.ParseTimeWindows(windows); err != nil {
+			return errors.Errorf("the schedule windows of %s are invalid: %v", name, err)
+		}
+	}
 	for _, scheduleConfig := range c.Schedulers {
 		if !schedule.IsSchedulerRegistered(scheduleConfig.Type) {
 			return errors.Errorf("create func of %v is not registered, maybe misspelled", scheduleConfig.Type)
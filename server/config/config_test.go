@@ -99,6 +99,27 @@ func (s *testConfigSuite) TestValidation(c *C) {
 	c.Assert(cfg.Schedule.Validate(), IsNil)
 	cfg.Schedule.TolerantSizeRatio = -0.6
 	c.Assert(cfg.Schedule.Validate(), NotNil)
+	cfg.Schedule.TolerantSizeRatio = 0
+	c.Assert(cfg.Schedule.RegionWeightMode, Equals, "manual")
+	cfg.Schedule.RegionWeightMode = "size"
+	c.Assert(cfg.Schedule.Validate(), NotNil)
+	cfg.Schedule.RegionWeightMode = "capacity"
+	c.Assert(cfg.Schedule.Validate(), IsNil)
+	cfg.Schedule.StoreClassWeights = map[string]float64{"hdd": 0}
+	c.Assert(cfg.Schedule.Validate(), NotNil)
+	cfg.Schedule.StoreClassWeights["hdd"] = 0.5
+	c.Assert(cfg.Schedule.Validate(), IsNil)
+	cfg.Schedule.ScheduleWindows = map[string][]string{"merge-checker": {"Mon-Fri 25:00-06:00"}}
+	c.Assert(cfg.Schedule.Validate(), NotNil)
+	cfg.Schedule.ScheduleWindows["merge-checker"] = []string{"Mon-Fri 22:00-06:00"}
+	c.Assert(cfg.Schedule.Validate(), IsNil)
+	opt := NewScheduleOption(cfg)
+	// 2020-06-01 is a Monday.
+	c.Assert(opt.IsInScheduleWindows("merge-checker", time.Date(2020, 6, 1, 23, 0, 0, 0, time.Local)), IsTrue)
+	c.Assert(opt.IsInScheduleWindows("merge-checker", time.Date(2020, 6, 1, 12, 0, 0, 0, time.Local)), IsFalse)
+	c.Assert(opt.IsInScheduleWindows("replica-checker", time.Date(2020, 6, 1, 12, 0, 0, 0, time.Local)), IsTrue)
+	opt.SetScheduleWindows("merge-checker", nil)
+	c.Assert(opt.IsInScheduleWindows("merge-checker", time.Date(2020, 6, 1, 12, 0, 0, 0, time.Local)), IsTrue)
 	// check quota
 	c.Assert(cfg.QuotaBackendBytes, Equals, defaultQuotaBackendBytes)
 }
@@ -134,6 +155,7 @@ leader-schedule-limit = 0
 	// When undefined, use default values.
 	c.Assert(cfg.PreVote, IsTrue)
 	c.Assert(cfg.Schedule.MaxMergeRegionKeys, Equals, uint64(defaultMaxMergeRegionKeys))
+	c.Assert(cfg.Schedule.MaxOperatorStepWaitTime.Duration, Equals, defaultMaxOperatorStepWaitTime)
 	c.Assert(cfg.PDServerCfg.MetricStorage, Equals, "http://127.0.0.1:9090")
 
 	// Check undefined config fields
@@ -193,6 +215,18 @@ address = "localhost:9090"
 
 	c.Assert(cfg.Metric.PushInterval.Duration, Equals, 35*time.Second)
 	c.Assert(cfg.Metric.PushAddress, Equals, "localhost:9090")
+
+	// The step wait time can be disabled explicitly.
+	cfgData = `
+[schedule]
+max-operator-step-wait-time = "0s"
+`
+	cfg = NewConfig()
+	meta, err = toml.Decode(cfgData, &cfg)
+	c.Assert(err, IsNil)
+	err = cfg.Adjust(&meta)
+	c.Assert(err, IsNil)
+	c.Assert(cfg.Schedule.MaxOperatorStepWaitTime.Duration, Equals, time.Duration(0))
 }
 
 func (s *testConfigSuite) TestMigrateFlags(c *C) {
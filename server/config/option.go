@@ -37,6 +37,10 @@ type ScheduleOption struct {
 	clusterVersion unsafe.Pointer
 	pdServerConfig atomic.Value
 	logConfig      atomic.Value
+
+	// scheduleWindows are the parsed schedule windows of the schedule
+	// configurations, which are checked on the hot paths.
+	scheduleWindows atomic.Value
 }
 
 // NewScheduleOption creates a new ScheduleOption.
@@ -58,9 +62,25 @@ func (o *ScheduleOption) Load() *ScheduleConfig {
 
 // Store sets scheduling configurations.
 func (o *ScheduleOption) Store(cfg *ScheduleConfig) {
+	o.scheduleWindows.Store(parseScheduleWindows(cfg.ScheduleWindows))
 	o.schedule.Store(cfg)
 }
 
+// parseScheduleWindows parses the schedule windows once when the
+// configurations are set. The invalid windows are rejected when they are set,
+// and are ignored here.
+func parseScheduleWindows(cfg map[string][]string) map[string][]*typeutil.TimeWindow {
+	ret := make(map[string][]*typeutil.TimeWindow, len(cfg))
+	for name, windows := range cfg {
+		for _, s := range windows {
+			if w, err := typeutil.ParseTimeWindow(s); err == nil {
+				ret[name] = append(ret[name], w)
+			}
+		}
+	}
+	return ret
+}
+
 // GetReplication returns replication configurations.
 func (o *ScheduleOption) GetReplication() *Replication {
 	return o.replication
@@ -151,6 +171,11 @@ func (o *ScheduleOption) GetPatrolRegionInterval() time.Duration {
 	return o.Load().PatrolRegionInterval.Duration
 }
 
+// GetMaxOperatorStepWaitTime returns the max duration that a step of an operator can run.
+func (o *ScheduleOption) GetMaxOperatorStepWaitTime() time.Duration {
+	return o.Load().MaxOperatorStepWaitTime.Duration
+}
+
 // GetMaxStoreDownTime returns the max down time of a store.
 func (o *ScheduleOption) GetMaxStoreDownTime() time.Duration {
 	return o.Load().MaxStoreDownTime.Duration
@@ -171,6 +196,11 @@ func (o *ScheduleOption) GetReplicaScheduleLimit() uint64 {
 	return o.Load().ReplicaScheduleLimit
 }
 
+// GetSplitScheduleLimit returns the limit for split schedule.
+func (o *ScheduleOption) GetSplitScheduleLimit() uint64 {
+	return o.Load().SplitScheduleLimit
+}
+
 // GetMergeScheduleLimit returns the limit for merge schedule.
 func (o *ScheduleOption) GetMergeScheduleLimit() uint64 {
 	return o.Load().MergeScheduleLimit
@@ -211,6 +241,44 @@ func (o *ScheduleOption) GetLeaderSchedulePolicy() core.SchedulePolicy {
 	return core.StringToSchedulePolicy(o.Load().LeaderSchedulePolicy)
 }
 
+// GetRegionWeightMode returns how the region weights of stores are decided.
+func (o *ScheduleOption) GetRegionWeightMode() core.RegionWeightMode {
+	return core.StringToRegionWeightMode(o.Load().RegionWeightMode)
+}
+
+// GetStoreClassWeights returns the weights of the store classes.
+func (o *ScheduleOption) GetStoreClassWeights() map[string]float64 {
+	return o.Load().StoreClassWeights
+}
+
+// GetScheduleWindows returns the time windows in which the scheduler or the
+// checker is allowed to run.
+func (o *ScheduleOption) GetScheduleWindows(name string) []string {
+	return o.Load().ScheduleWindows[name]
+}
+
+// IsInScheduleWindows returns if the time is in the schedule windows of the
+// scheduler or the checker.
+func (o *ScheduleOption) IsInScheduleWindows(name string, t time.Time) bool {
+	windows := o.scheduleWindows.Load().(map[string][]*typeutil.TimeWindow)
+	return typeutil.IsInTimeWindows(windows[name], t)
+}
+
+// SetScheduleWindows sets the time windows of a scheduler or a checker. Empty
+// windows remove the restriction.
+func (o *ScheduleOption) SetScheduleWindows(name string, windows []string) {
+	v := o.Load().Clone()
+	if len(windows) == 0 {
+		delete(v.ScheduleWindows, name)
+	} else {
+		if v.ScheduleWindows == nil {
+			v.ScheduleWindows = make(map[string][]string)
+		}
+		v.ScheduleWindows[name] = windows
+	}
+	o.Store(v)
+}
+
 // GetKeyType is to get key type.
 func (o *ScheduleOption) GetKeyType() core.KeyType {
 	return core.StringToKeyType(o.LoadPDServerConfig().KeyType)
@@ -246,6 +314,21 @@ func (o *ScheduleOption) IsDebugMetricsEnabled() bool {
 	return o.Load().EnableDebugMetrics
 }
 
+// IsOperatorHistoryEnabled returns if the ended operators are persisted.
+func (o *ScheduleOption) IsOperatorHistoryEnabled() bool {
+	return o.Load().EnableOperatorHistory
+}
+
+// GetOperatorHistoryRetention returns how long the persisted operators are kept.
+func (o *ScheduleOption) GetOperatorHistoryRetention() time.Duration {
+	return o.Load().OperatorHistoryRetention.Duration
+}
+
+// GetMaxOperatorHistoryRecords returns the max number of the persisted operators.
+func (o *ScheduleOption) GetMaxOperatorHistoryRecords() uint64 {
+	return o.Load().MaxOperatorHistoryRecords
+}
+
 // GetSchedulers gets the scheduler configurations.
 func (o *ScheduleOption) GetSchedulers() SchedulerConfigs {
 	return o.Load().Schedulers
@@ -291,6 +374,7 @@ func (o *ScheduleOption) RemoveSchedulerCfg(ctx context.Context, name string) er
 				v.Schedulers[i] = schedulerCfg
 			} else {
 				v.Schedulers = append(v.Schedulers[:i], v.Schedulers[i+1:]...)
+				delete(v.ScheduleWindows, name)
 			}
 			o.Store(v)
 			return nil
@@ -141,7 +141,7 @@ func (bc *BasicCluster) UnblockStore(storeID uint64) {
 }
 
 // AttachAvailableFunc attaches an available function to a specific store.
-func (bc *BasicCluster) AttachAvailableFunc(storeID uint64, f func() bool) {
+func (bc *BasicCluster) AttachAvailableFunc(storeID uint64, f func(limitType StoreLimitType, opKind string) bool) {
 	bc.Lock()
 	defer bc.Unlock()
 	bc.Stores.AttachAvailableFunc(storeID, f)
@@ -188,6 +188,22 @@ func (bc *BasicCluster) RandLearnerRegion(storeID uint64, ranges []KeyRange, opt
 	return bc.selectRegion(regions, opts...)
 }
 
+// RandStoreRegions returns n random regions that have a leader or a follower
+// on the store, in proportion to the leader and follower counts. The regions
+// may be duplicated.
+func (bc *BasicCluster) RandStoreRegions(storeID uint64, n int) []*RegionInfo {
+	bc.RLock()
+	defer bc.RUnlock()
+	leaderCount := bc.Regions.GetStoreLeaderCount(storeID)
+	total := leaderCount + bc.Regions.GetStoreFollowerCount(storeID)
+	if total == 0 {
+		return nil
+	}
+	leaders := n * leaderCount / total
+	regions := bc.Regions.RandLeaderRegions(storeID, nil, leaders)
+	return append(regions, bc.Regions.RandFollowerRegions(storeID, nil, n-leaders)...)
+}
+
 func (bc *BasicCluster) selectRegion(regions []*RegionInfo, opts ...RegionOption) *RegionInfo {
 	for _, r := range regions {
 		if r == nil {
@@ -396,7 +412,7 @@ type StoreSetController interface {
 	BlockStore(id uint64) error
 	UnblockStore(id uint64)
 
-	AttachAvailableFunc(id uint64, f func() bool)
+	AttachAvailableFunc(id uint64, f func(limitType StoreLimitType, opKind string) bool)
 }
 
 // KeyRange is a key range.
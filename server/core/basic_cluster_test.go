@@ -0,0 +1,56 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+)
+
+var _ = Suite(&testBasicClusterSuite{})
+
+type testBasicClusterSuite struct{}
+
+func (s *testBasicClusterSuite) TestRandStoreRegions(c *C) {
+	bc := NewBasicCluster()
+	// Store 1 has 4 leaders and 6 followers.
+	for i := uint64(0); i < 10; i++ {
+		peers := []*metapb.Peer{{Id: i*10 + 1, StoreId: 1}, {Id: i*10 + 2, StoreId: 2}}
+		leader := peers[0]
+		if i >= 4 {
+			leader = peers[1]
+		}
+		region := NewRegionInfo(&metapb.Region{
+			Id:       i + 1,
+			StartKey: []byte(fmt.Sprintf("%02d", i)),
+			EndKey:   []byte(fmt.Sprintf("%02d", i+1)),
+			Peers:    peers,
+		}, leader)
+		bc.PutRegion(region)
+	}
+
+	regions := bc.RandStoreRegions(1, 20)
+	c.Assert(regions, HasLen, 20)
+	var leaders int
+	for _, region := range regions {
+		c.Assert(region.GetStorePeer(1), NotNil)
+		if region.GetLeader().GetStoreId() == 1 {
+			leaders++
+		}
+	}
+	c.Assert(leaders, Equals, 8)
+	c.Assert(bc.RandStoreRegions(3, 20), HasLen, 0)
+}
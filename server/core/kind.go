@@ -13,6 +13,8 @@
 
 package core
 
+import "github.com/pkg/errors"
+
 // PriorityLevel lower level means higher priority
 type PriorityLevel int
 
@@ -91,6 +93,73 @@ func StringToSchedulePolicy(input string) SchedulePolicy {
 	}
 }
 
+// RegionWeightMode distinguishes how the region weights of stores are decided.
+type RegionWeightMode int
+
+const (
+	// ManualRegionWeight indicates that the region weight is set by user
+	ManualRegionWeight RegionWeightMode = iota
+	// CapacityRegionWeight indicates that the region weight is derived from the store capacity
+	CapacityRegionWeight
+)
+
+func (m RegionWeightMode) String() string {
+	switch m {
+	case ManualRegionWeight:
+		return "manual"
+	case CapacityRegionWeight:
+		return "capacity"
+	default:
+		return "unknown"
+	}
+}
+
+// StringToRegionWeightMode creates a region weight mode with string.
+func StringToRegionWeightMode(input string) RegionWeightMode {
+	switch input {
+	case ManualRegionWeight.String():
+		return ManualRegionWeight
+	case CapacityRegionWeight.String():
+		return CapacityRegionWeight
+	default:
+		panic("invalid region weight mode: " + input)
+	}
+}
+
+// StoreLimitType distinguishes the buckets of the store limit.
+type StoreLimitType int
+
+const (
+	// StoreLimitAddPeer limits the peers added to a store.
+	StoreLimitAddPeer StoreLimitType = iota
+	// StoreLimitRemovePeer limits the peers removed from a store.
+	StoreLimitRemovePeer
+)
+
+// StoreLimitTypes is the list of all the store limit types.
+var StoreLimitTypes = []StoreLimitType{StoreLimitAddPeer, StoreLimitRemovePeer}
+
+func (t StoreLimitType) String() string {
+	switch t {
+	case StoreLimitAddPeer:
+		return "add-peer"
+	case StoreLimitRemovePeer:
+		return "remove-peer"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseStoreLimitType converts string to StoreLimitType.
+func ParseStoreLimitType(input string) (StoreLimitType, error) {
+	for _, t := range StoreLimitTypes {
+		if t.String() == input {
+			return t, nil
+		}
+	}
+	return 0, errors.Errorf("invalid store limit type: %s", input)
+}
+
 // KeyType distinguishes different kinds of key types
 type KeyType int
 
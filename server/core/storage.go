@@ -23,6 +23,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/gogo/protobuf/proto"
@@ -33,11 +34,13 @@ import (
 )
 
 const (
-	clusterPath  = "raft"
-	configPath   = "config"
-	schedulePath = "schedule"
-	gcPath       = "gc"
-	rulesPath    = "rules"
+	clusterPath     = "raft"
+	configPath      = "config"
+	schedulePath    = "schedule"
+	gcPath          = "gc"
+	rulesPath       = "rules"
+	ruleGroupPath   = "rule_group"
+	regionLabelPath = "region_label"
 
 	customScheduleConfigPath = "scheduler_config"
 	componentsConfigPath     = "components_config"
@@ -236,19 +239,81 @@ func (s *Storage) DeleteRule(ruleKey string) error {
 	return s.Base.Remove(path.Join(rulesPath, ruleKey))
 }
 
+// SaveRules saves and removes the rules in a transaction. The rules to save
+// are keyed by their rule keys.
+func (s *Storage) SaveRules(saves map[string]interface{}, deletes []string) error {
+	ops := make([]kv.Op, 0, len(saves)+len(deletes))
+	for _, ruleKey := range deletes {
+		ops = append(ops, kv.OpRemove(path.Join(rulesPath, ruleKey)))
+	}
+	for ruleKey, rule := range saves {
+		value, err := json.Marshal(rule)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ops = append(ops, kv.OpSave(path.Join(rulesPath, ruleKey), string(value)))
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return s.Batch(ops...)
+}
+
 // LoadRules loads placement rules from storage.
 func (s *Storage) LoadRules(f func(v string) error) (bool, error) {
-	// Range is ['rule/\x00', 'rule0'). 'rule0' is the upper bound of all rules because '0' is next char of '/' in
-	// ascii order.
-	nextKey := path.Join(rulesPath, "\x00")
-	endKey := rulesPath + "0"
+	return s.loadRangeByPrefix(rulesPath+"/", f)
+}
+
+// SaveRuleGroup stores a rule group config to storage.
+func (s *Storage) SaveRuleGroup(groupID string, group interface{}) error {
+	value, err := json.Marshal(group)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(path.Join(ruleGroupPath, groupID), string(value))
+}
+
+// DeleteRuleGroup removes a rule group from storage.
+func (s *Storage) DeleteRuleGroup(groupID string) error {
+	return s.Base.Remove(path.Join(ruleGroupPath, groupID))
+}
+
+// LoadRuleGroups loads all rule groups from storage.
+func (s *Storage) LoadRuleGroups(f func(v string) error) (bool, error) {
+	return s.loadRangeByPrefix(ruleGroupPath+"/", f)
+}
+
+// SaveRegionLabelRule stores a region label rule to storage.
+func (s *Storage) SaveRegionLabelRule(ruleKey string, rule interface{}) error {
+	value, err := json.Marshal(rule)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(path.Join(regionLabelPath, ruleKey), string(value))
+}
+
+// DeleteRegionLabelRule removes a region label rule from storage.
+func (s *Storage) DeleteRegionLabelRule(ruleKey string) error {
+	return s.Base.Remove(path.Join(regionLabelPath, ruleKey))
+}
+
+// LoadRegionLabelRules loads all region label rules from storage.
+func (s *Storage) LoadRegionLabelRules(f func(v string) error) (bool, error) {
+	return s.loadRangeByPrefix(regionLabelPath+"/", f)
+}
+
+// loadRangeByPrefix iterates all key-value pairs in the storage that has the prefix.
+func (s *Storage) loadRangeByPrefix(prefix string, f func(v string) error) (bool, error) {
+	// Range is [prefix+'\x00', prefixEnd). prefixEnd is the upper bound of all keys with the prefix.
+	nextKey := prefix + "\x00"
+	endKey := clientv3.GetPrefixRangeEnd(prefix)
 	for {
 		keys, values, err := s.LoadRange(nextKey, endKey, minKVRangeLimit)
 		if err != nil {
 			return false, err
 		}
 		if len(values) == 0 {
-			return false, nil
+			return nextKey != prefix+"\x00", nil
 		}
 		for _, v := range values {
 			if err := f(v); err != nil {
@@ -361,6 +426,78 @@ func (s *Storage) LoadGCSafePoint() (uint64, error) {
 	return safePoint, nil
 }
 
+// ServiceSafePoint is the safe point for a specific service.
+type ServiceSafePoint struct {
+	ServiceID string `json:"service_id"`
+	ExpiredAt int64  `json:"expired_at"`
+	SafePoint uint64 `json:"safe_point"`
+}
+
+func (s *Storage) serviceGCSafePointPath(serviceID string) string {
+	return path.Join(gcPath, "safe_point", "service", serviceID)
+}
+
+// SaveServiceGCSafePoint saves a GC safe point for the service to storage.
+func (s *Storage) SaveServiceGCSafePoint(ssp *ServiceSafePoint) error {
+	if ssp.ServiceID == "" {
+		return errors.New("service id of service safepoint cannot be empty")
+	}
+	value, err := json.Marshal(ssp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(s.serviceGCSafePointPath(ssp.ServiceID), string(value))
+}
+
+// RemoveServiceGCSafePoint removes a GC safe point for the service.
+func (s *Storage) RemoveServiceGCSafePoint(serviceID string) error {
+	return s.Remove(s.serviceGCSafePointPath(serviceID))
+}
+
+// LoadAllServiceGCSafePoints loads all services' GC safe points, including
+// the expired ones.
+func (s *Storage) LoadAllServiceGCSafePoints() ([]*ServiceSafePoint, error) {
+	var ssps []*ServiceSafePoint
+	_, err := s.loadRangeByPrefix(s.serviceGCSafePointPath("")+"/", func(v string) error {
+		ssp := &ServiceSafePoint{}
+		if err := json.Unmarshal([]byte(v), ssp); err != nil {
+			return errors.WithStack(err)
+		}
+		ssps = append(ssps, ssp)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ssps, nil
+}
+
+// LoadMinServiceGCSafePoint returns the minimum safe point across all
+// services. The expired safe points are removed from storage. It returns an
+// empty ServiceSafePoint if no service safe point exists.
+func (s *Storage) LoadMinServiceGCSafePoint(now time.Time) (*ServiceSafePoint, error) {
+	ssps, err := s.LoadAllServiceGCSafePoints()
+	if err != nil {
+		return nil, err
+	}
+	min := &ServiceSafePoint{SafePoint: math.MaxUint64}
+	for _, ssp := range ssps {
+		if ssp.ExpiredAt < now.Unix() {
+			if err := s.RemoveServiceGCSafePoint(ssp.ServiceID); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if ssp.SafePoint < min.SafePoint {
+			min = ssp
+		}
+	}
+	if min.SafePoint == math.MaxUint64 {
+		return &ServiceSafePoint{}, nil
+	}
+	return min, nil
+}
+
 // LoadAllScheduleConfig loads all schedulers' config.
 func (s *Storage) LoadAllScheduleConfig() ([]string, []string, error) {
 	keys, values, err := s.LoadRange(customScheduleConfigPath, clientv3.GetPrefixRangeEnd(customScheduleConfigPath), 1000)
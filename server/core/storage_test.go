@@ -16,6 +16,7 @@ package core
 import (
 	"fmt"
 	"math"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -194,6 +195,45 @@ func (s *testKVSuite) TestLoadGCSafePoint(c *C) {
 	}
 }
 
+func (s *testKVSuite) TestLoadMinServiceGCSafePoint(c *C) {
+	storage := NewStorage(kv.NewMemoryKV())
+	now := time.Now()
+	min, err := storage.LoadMinServiceGCSafePoint(now)
+	c.Assert(err, IsNil)
+	c.Assert(*min, DeepEquals, ServiceSafePoint{})
+
+	ssps := []*ServiceSafePoint{
+		{ServiceID: "1", ExpiredAt: now.Unix() - 10, SafePoint: 1},
+		{ServiceID: "2", ExpiredAt: now.Unix() + 10, SafePoint: 2},
+		{ServiceID: "3", ExpiredAt: now.Unix() + 20, SafePoint: 3},
+	}
+	for _, ssp := range ssps {
+		c.Assert(storage.SaveServiceGCSafePoint(ssp), IsNil)
+	}
+	c.Assert(storage.SaveServiceGCSafePoint(&ServiceSafePoint{}), NotNil)
+	// The cluster-wide safe point is not affected by service safe points.
+	c.Assert(storage.SaveGCSafePoint(100), IsNil)
+
+	min, err = storage.LoadMinServiceGCSafePoint(now)
+	c.Assert(err, IsNil)
+	c.Assert(min, DeepEquals, ssps[1])
+	all, err := storage.LoadAllServiceGCSafePoints()
+	c.Assert(err, IsNil)
+	c.Assert(all, DeepEquals, ssps[1:])
+
+	min, err = storage.LoadMinServiceGCSafePoint(now.Add(15 * time.Second))
+	c.Assert(err, IsNil)
+	c.Assert(min, DeepEquals, ssps[2])
+
+	c.Assert(storage.RemoveServiceGCSafePoint("3"), IsNil)
+	min, err = storage.LoadMinServiceGCSafePoint(now)
+	c.Assert(err, IsNil)
+	c.Assert(*min, DeepEquals, ServiceSafePoint{})
+	safePoint, err := storage.LoadGCSafePoint()
+	c.Assert(err, IsNil)
+	c.Assert(safePoint, Equals, uint64(100))
+}
+
 type KVWithMaxRangeLimit struct {
 	kv.Base
 	rangeLimit int
@@ -44,7 +44,7 @@ type StoreInfo struct {
 	lastPersistTime  time.Time
 	leaderWeight     float64
 	regionWeight     float64
-	available        func() bool
+	available        func(limitType StoreLimitType, opKind string) bool
 }
 
 // NewStoreInfo creates StoreInfo with meta data.
@@ -90,12 +90,14 @@ func (s *StoreInfo) IsBlocked() bool {
 	return s.blocked
 }
 
-// IsAvailable returns if the store bucket of limitation is available
-func (s *StoreInfo) IsAvailable() bool {
+// IsAvailable returns if the store bucket of limitation is available. The
+// opKind is the name of the operator kind, whose bucket is used if it has
+// one, otherwise the default bucket of the store is used.
+func (s *StoreInfo) IsAvailable(limitType StoreLimitType, opKind string) bool {
 	if s.available == nil {
 		return true
 	}
-	return s.available()
+	return s.available(limitType, opKind)
 }
 
 // IsUp checks if the store's state is Up.
@@ -314,6 +316,37 @@ func (s *StoreInfo) RegionScore(highSpaceRatio, lowSpaceRatio float64, delta int
 	return score / math.Max(s.GetRegionWeight(), minWeight)
 }
 
+// StoreClassLabel is the label key which groups stores of the same hardware class.
+const StoreClassLabel = "store-class"
+
+// CapacityRegionWeights derives the region weights of the stores from their
+// capacities. The weight of a store is its capacity relative to the average
+// capacity of the stores, multiplied by the weight of its store class and the
+// region weight set by user. Stores which have not reported the capacity keep
+// the region weight set by user.
+func CapacityRegionWeights(stores []*StoreInfo, classWeights map[string]float64) map[uint64]float64 {
+	var totalCapacity float64
+	var count int
+	for _, s := range stores {
+		if s.GetCapacity() > 0 {
+			totalCapacity += float64(s.GetCapacity())
+			count++
+		}
+	}
+	weights := make(map[uint64]float64, len(stores))
+	for _, s := range stores {
+		weight := s.GetRegionWeight()
+		if s.GetCapacity() > 0 {
+			weight *= float64(s.GetCapacity()) * float64(count) / totalCapacity
+		}
+		if classWeight, ok := classWeights[s.GetLabelValue(StoreClassLabel)]; ok {
+			weight *= classWeight
+		}
+		weights[s.GetID()] = weight
+	}
+	return weights
+}
+
 // StorageSize returns store's used storage size reported from tikv.
 func (s *StoreInfo) StorageSize() uint64 {
 	return s.GetUsedSize()
@@ -559,7 +592,7 @@ func (s *StoresInfo) UnblockStore(storeID uint64) {
 }
 
 // AttachAvailableFunc attaches f to a specific store.
-func (s *StoresInfo) AttachAvailableFunc(storeID uint64, f func() bool) {
+func (s *StoresInfo) AttachAvailableFunc(storeID uint64, f func(limitType StoreLimitType, opKind string) bool) {
 	if store, ok := s.stores[storeID]; ok {
 		s.stores[storeID] = store.Clone(SetAvailableFunc(f))
 	}
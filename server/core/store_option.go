@@ -157,7 +157,7 @@ func SetStoreStats(stats *pdpb.StoreStats) StoreCreateOption {
 }
 
 // SetAvailableFunc sets a customize function for the store. The function f returns true if the store limit is not exceeded.
-func SetAvailableFunc(f func() bool) StoreCreateOption {
+func SetAvailableFunc(f func(limitType StoreLimitType, opKind string) bool) StoreCreateOption {
 	return func(store *StoreInfo) {
 		store.available = f
 	}
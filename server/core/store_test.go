@@ -19,6 +19,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
 )
 
 var _ = Suite(&testDistinctScoreSuite{})
@@ -59,6 +60,34 @@ func (s *testDistinctScoreSuite) TestDistinctScore(c *C) {
 	c.Assert(DistinctScore(labels, stores, store), Equals, float64(0))
 }
 
+func (s *testDistinctScoreSuite) TestCapacityRegionWeights(c *C) {
+	newStore := func(id uint64, capacity uint64, class string) *StoreInfo {
+		store := NewStoreInfoWithLabel(id, 0, map[string]string{StoreClassLabel: class})
+		return store.Clone(SetStoreStats(&pdpb.StoreStats{Capacity: capacity, Available: capacity}))
+	}
+	stores := []*StoreInfo{
+		newStore(1, 1024, "ssd"),
+		newStore(2, 1024, "ssd"),
+		newStore(3, 4096, "ssd"),
+		newStore(4, 4096, "ssd"),
+		newStore(5, 0, "ssd"),
+	}
+	weights := CapacityRegionWeights(stores, nil)
+	c.Assert(weights[1], Equals, 0.4)
+	c.Assert(weights[2], Equals, 0.4)
+	c.Assert(weights[3], Equals, 1.6)
+	c.Assert(weights[4], Equals, 1.6)
+	// The store which has not reported the capacity keeps its weight.
+	c.Assert(weights[5], Equals, 1.0)
+
+	stores[2] = newStore(3, 4096, "hdd")
+	stores[3] = stores[3].Clone(SetRegionWeight(2))
+	weights = CapacityRegionWeights(stores, map[string]float64{"hdd": 0.5})
+	c.Assert(weights[1], Equals, 0.4)
+	c.Assert(weights[3], Equals, 0.8)
+	c.Assert(weights[4], Equals, 3.2)
+}
+
 var _ = Suite(&testConcurrencySuite{})
 
 type testConcurrencySuite struct{}
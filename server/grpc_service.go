@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pkg/errors"
@@ -41,6 +42,9 @@ var (
 	// TODO: work as proxy.
 	ErrNotLeader  = status.Errorf(codes.Unavailable, "not leader")
 	ErrNotStarted = status.Errorf(codes.Unavailable, "server not started")
+	// ErrStaleFollower is returned when a follower is allowed to serve the
+	// request but the regions synced from the leader are too stale.
+	ErrStaleFollower = status.Errorf(codes.Unavailable, "follower regions are stale")
 )
 
 // GetMembers implements gRPC PDServer.
@@ -72,6 +76,7 @@ func (s *Server) GetMembers(context.Context, *pdpb.GetMembersRequest) (*pdpb.Get
 
 // Tso implements gRPC PDServer.
 func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
+	dcLocation := grpcutil.GetDCLocation(stream.Context())
 	for {
 		request, err := stream.Recv()
 		if err == io.EOF {
@@ -89,7 +94,7 @@ func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
 			return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, request.GetHeader().GetClusterId())
 		}
 		count := request.GetCount()
-		ts, err := s.tso.GetRespTS(count)
+		ts, err := s.getRespTS(dcLocation, count)
 		if err != nil {
 			return status.Errorf(codes.Unknown, err.Error())
 		}
@@ -110,6 +115,18 @@ func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
 	}
 }
 
+// getRespTS gets the global timestamps, or the local timestamps if the data
+// center is specified, which can only be served by its local allocator leader.
+func (s *Server) getRespTS(dcLocation string, count uint32) (pdpb.Timestamp, error) {
+	if dcLocation == "" {
+		return s.tso.GetRespTS(count)
+	}
+	if s.localTSO == nil || s.localTSO.GetDCLocation() != dcLocation {
+		return pdpb.Timestamp{}, errors.Errorf("not in the data center %s", dcLocation)
+	}
+	return s.localTSO.GetRespTS(count)
+}
+
 // Bootstrap implements gRPC PDServer.
 func (s *Server) Bootstrap(ctx context.Context, request *pdpb.BootstrapRequest) (*pdpb.BootstrapResponse, error) {
 	if err := s.validateRequest(request.GetHeader()); err != nil {
@@ -168,9 +185,13 @@ func (s *Server) AllocID(ctx context.Context, request *pdpb.AllocIDRequest) (*pd
 
 // GetStore implements gRPC PDServer.
 func (s *Server) GetStore(ctx context.Context, request *pdpb.GetStoreRequest) (*pdpb.GetStoreResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
+	follower, err := s.validateRequestAllowFollower(ctx, request.GetHeader())
+	if err != nil {
 		return nil, err
 	}
+	if follower {
+		return s.getStoreFromFollower(request.GetStoreId())
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -408,9 +429,13 @@ func (s *Server) RegionHeartbeat(stream pdpb.PD_RegionHeartbeatServer) error {
 
 // GetRegion implements gRPC PDServer.
 func (s *Server) GetRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
+	follower, err := s.validateRegionRequest(ctx, request.GetHeader())
+	if err != nil {
 		return nil, err
 	}
+	if follower {
+		return s.regionResponse(s.GetBasicCluster().SearchRegion(request.GetRegionKey())), nil
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -426,9 +451,13 @@ func (s *Server) GetRegion(ctx context.Context, request *pdpb.GetRegionRequest)
 
 // GetPrevRegion implements gRPC PDServer
 func (s *Server) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
+	follower, err := s.validateRegionRequest(ctx, request.GetHeader())
+	if err != nil {
 		return nil, err
 	}
+	if follower {
+		return s.regionResponse(s.GetBasicCluster().SearchPrevRegion(request.GetRegionKey())), nil
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -445,9 +474,13 @@ func (s *Server) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionReque
 
 // GetRegionByID implements gRPC PDServer.
 func (s *Server) GetRegionByID(ctx context.Context, request *pdpb.GetRegionByIDRequest) (*pdpb.GetRegionResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
+	follower, err := s.validateRegionRequest(ctx, request.GetHeader())
+	if err != nil {
 		return nil, err
 	}
+	if follower {
+		return s.regionResponse(s.GetBasicCluster().GetRegion(request.GetRegionId())), nil
+	}
 
 	rc := s.GetRaftCluster()
 	if rc == nil {
@@ -464,15 +497,21 @@ func (s *Server) GetRegionByID(ctx context.Context, request *pdpb.GetRegionByIDR
 
 // ScanRegions implements gRPC PDServer.
 func (s *Server) ScanRegions(ctx context.Context, request *pdpb.ScanRegionsRequest) (*pdpb.ScanRegionsResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
+	follower, err := s.validateRegionRequest(ctx, request.GetHeader())
+	if err != nil {
 		return nil, err
 	}
 
-	rc := s.GetRaftCluster()
-	if rc == nil {
-		return &pdpb.ScanRegionsResponse{Header: s.notBootstrappedHeader()}, nil
+	var regions []*core.RegionInfo
+	if follower {
+		regions = s.GetBasicCluster().ScanRange(request.GetStartKey(), request.GetEndKey(), int(request.GetLimit()))
+	} else {
+		rc := s.GetRaftCluster()
+		if rc == nil {
+			return &pdpb.ScanRegionsResponse{Header: s.notBootstrappedHeader()}, nil
+		}
+		regions = rc.ScanRegions(request.GetStartKey(), request.GetEndKey(), int(request.GetLimit()))
 	}
-	regions := rc.ScanRegions(request.GetStartKey(), request.GetEndKey(), int(request.GetLimit()))
 	resp := &pdpb.ScanRegionsResponse{Header: s.header()}
 	for _, r := range regions {
 		leader := r.GetLeader()
@@ -701,6 +740,9 @@ func (s *Server) UpdateGCSafePoint(ctx context.Context, request *pdpb.UpdateGCSa
 		return &pdpb.UpdateGCSafePointResponse{Header: s.notBootstrappedHeader()}, nil
 	}
 
+	s.serviceSafePointLock.Lock()
+	defer s.serviceSafePointLock.Unlock()
+
 	oldSafePoint, err := s.storage.LoadGCSafePoint()
 	if err != nil {
 		return nil, err
@@ -708,6 +750,23 @@ func (s *Server) UpdateGCSafePoint(ctx context.Context, request *pdpb.UpdateGCSa
 
 	newSafePoint := request.SafePoint
 
+	// The safe point can not pass the minimum safe point held by services.
+	now, err := s.TSONow()
+	if err != nil {
+		return nil, err
+	}
+	min, err := s.storage.LoadMinServiceGCSafePoint(now)
+	if err != nil {
+		return nil, err
+	}
+	if min.ServiceID != "" && newSafePoint > min.SafePoint {
+		log.Info("gc safe point is held by service",
+			zap.String("service-id", min.ServiceID),
+			zap.Uint64("service-safe-point", min.SafePoint),
+			zap.Uint64("safe-point", newSafePoint))
+		newSafePoint = min.SafePoint
+	}
+
 	// Only save the safe point if it's greater than the previous one
 	if newSafePoint > oldSafePoint {
 		if err := s.storage.SaveGCSafePoint(newSafePoint); err != nil {
@@ -771,6 +830,70 @@ func (s *Server) validateRequest(header *pdpb.RequestHeader) error {
 	return nil
 }
 
+// validateRequestAllowFollower is like validateRequest, but it also passes on a
+// follower if the client allows the request to be served by a follower. It
+// returns true if the request should be served as a follower.
+func (s *Server) validateRequestAllowFollower(ctx context.Context, header *pdpb.RequestHeader) (bool, error) {
+	if s.IsClosed() {
+		return false, errors.WithStack(ErrNotLeader)
+	}
+	if s.member.IsLeader() {
+		return false, s.validateRequest(header)
+	}
+	if _, ok := grpcutil.GetFollowerHandle(ctx); !ok {
+		return false, errors.WithStack(ErrNotLeader)
+	}
+	if header.GetClusterId() != s.clusterID {
+		return false, status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, header.GetClusterId())
+	}
+	return true, nil
+}
+
+// validateRegionRequest validates a request which reads regions. A follower
+// can serve it only if it has synced the regions from the leader recently
+// enough for the max staleness of the request.
+func (s *Server) validateRegionRequest(ctx context.Context, header *pdpb.RequestHeader) (bool, error) {
+	follower, err := s.validateRequestAllowFollower(ctx, header)
+	if err != nil || !follower {
+		return follower, err
+	}
+	maxStaleness, _ := grpcutil.GetFollowerHandle(ctx)
+	lastSync := s.cluster.GetRegionSyncer().GetLastSyncTime()
+	if lastSync.IsZero() || time.Since(lastSync) > maxStaleness {
+		return false, errors.WithStack(ErrStaleFollower)
+	}
+	return true, nil
+}
+
+// regionResponse returns the response of a region served by a follower. The
+// leader of the region is left empty since the follower does not sync it.
+func (s *Server) regionResponse(region *core.RegionInfo) *pdpb.GetRegionResponse {
+	if region == nil {
+		return &pdpb.GetRegionResponse{Header: s.header()}
+	}
+	return &pdpb.GetRegionResponse{
+		Header: s.header(),
+		Region: region.GetMeta(),
+	}
+}
+
+// getStoreFromFollower loads the store from the storage, which is consistent
+// with the leader. The stats of the store are only known by the leader.
+func (s *Server) getStoreFromFollower(storeID uint64) (*pdpb.GetStoreResponse, error) {
+	store := &metapb.Store{}
+	ok, err := s.storage.LoadStore(storeID, store)
+	if err != nil {
+		return nil, status.Errorf(codes.Unknown, err.Error())
+	}
+	if !ok {
+		return nil, status.Errorf(codes.Unknown, "invalid store ID %d, not found", storeID)
+	}
+	return &pdpb.GetStoreResponse{
+		Header: s.header(),
+		Store:  store,
+	}, nil
+}
+
 func (s *Server) header() *pdpb.ResponseHeader {
 	return &pdpb.ResponseHeader{ClusterId: s.clusterID}
 }
@@ -35,6 +35,7 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedulers"
 	"github.com/pingcap/pd/v4/server/statistics"
+	"github.com/pingcap/pd/v4/server/tso"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -203,6 +204,15 @@ func (h *Handler) GetHotKeysReadStores() map[uint64]float64 {
 	return rc.GetStoresKeysReadStat()
 }
 
+// GetHotStoreLoads gets the load vectors of stores used by the hot region scheduler.
+func (h *Handler) GetHotStoreLoads() map[string]map[uint64]*statistics.StoreLoadVector {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil
+	}
+	return c.GetHotStoreLoads()
+}
+
 // AddScheduler adds a scheduler.
 func (h *Handler) AddScheduler(name string, args ...string) error {
 	c, err := h.GetRaftCluster()
@@ -253,6 +263,28 @@ func (h *Handler) PauseOrResumeScheduler(name string, t int64) error {
 	return err
 }
 
+// SetScheduleWindows sets the time windows in which a scheduler or a checker
+// is allowed to run. Empty windows remove the restriction.
+func (h *Handler) SetScheduleWindows(name string, windows []string) error {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return err
+	}
+	if err = c.SetScheduleWindows(name, windows); err != nil {
+		log.Error("can not set schedule windows", zap.String("name", name), zap.Strings("windows", windows), zap.Error(err))
+	}
+	return err
+}
+
+// GetSchedulerDiagnostic returns the decision trace of the last round of a scheduler.
+func (h *Handler) GetSchedulerDiagnostic(name string) (*schedule.DiagnosticResult, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	return c.DiagnoseScheduler(name)
+}
+
 // AddBalanceLeaderScheduler adds a balance-leader-scheduler.
 func (h *Handler) AddBalanceLeaderScheduler() error {
 	return h.AddScheduler(schedulers.BalanceLeaderType)
@@ -268,6 +300,11 @@ func (h *Handler) AddBalanceHotRegionScheduler() error {
 	return h.AddScheduler(schedulers.HotRegionType)
 }
 
+// AddBalanceLoadScheduler adds a balance-load-scheduler.
+func (h *Handler) AddBalanceLoadScheduler() error {
+	return h.AddScheduler(schedulers.BalanceLoadType)
+}
+
 // AddLabelScheduler adds a label-scheduler.
 func (h *Handler) AddLabelScheduler() error {
 	return h.AddScheduler(schedulers.LabelType)
@@ -278,6 +315,11 @@ func (h *Handler) AddScatterRangeScheduler(args ...string) error {
 	return h.AddScheduler(schedulers.ScatterRangeType, args...)
 }
 
+// AddExternalScheduler adds an external-scheduler.
+func (h *Handler) AddExternalScheduler(name, address string) error {
+	return h.AddScheduler(schedulers.ExternalType, name, address)
+}
+
 // AddAdjacentRegionScheduler adds a balance-adjacent-region-scheduler.
 func (h *Handler) AddAdjacentRegionScheduler(args ...string) error {
 	return h.AddScheduler(schedulers.AdjacentRegionType, args...)
@@ -416,18 +458,28 @@ func (h *Handler) GetHistory(start time.Time) ([]operator.OpHistory, error) {
 	return c.GetHistory(start), nil
 }
 
-// SetAllStoresLimit is used to set limit of all stores.
-func (h *Handler) SetAllStoresLimit(rate float64) error {
+// GetOperatorRecords returns the ended operators in storage which match the filter.
+func (h *Handler) GetOperatorRecords(f *schedule.OperatorHistoryFilter) ([]*schedule.OperatorRecord, error) {
+	c, err := h.GetOperatorController()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetHistoryRecords(f)
+}
+
+// SetAllStoresLimit is used to set limit of all stores. It sets the default
+// buckets of all types if no key is given.
+func (h *Handler) SetAllStoresLimit(rate float64, keys ...schedule.StoreLimitKey) error {
 	c, err := h.GetOperatorController()
 	if err != nil {
 		return err
 	}
-	c.SetAllStoresLimit(rate, schedule.StoreLimitManual)
+	c.SetAllStoresLimit(rate, schedule.StoreLimitManual, keys...)
 	return nil
 }
 
 // GetAllStoresLimit is used to get limit of all stores.
-func (h *Handler) GetAllStoresLimit() (map[uint64]*schedule.StoreLimit, error) {
+func (h *Handler) GetAllStoresLimit() (map[uint64]map[schedule.StoreLimitKey]*schedule.StoreLimit, error) {
 	c, err := h.GetOperatorController()
 	if err != nil {
 		return nil, err
@@ -435,13 +487,14 @@ func (h *Handler) GetAllStoresLimit() (map[uint64]*schedule.StoreLimit, error) {
 	return c.GetAllStoresLimit(), nil
 }
 
-// SetStoreLimit is used to set the limit of a store.
-func (h *Handler) SetStoreLimit(storeID uint64, rate float64) error {
+// SetStoreLimit is used to set the limit of a store. It sets the default
+// buckets of all types if no key is given.
+func (h *Handler) SetStoreLimit(storeID uint64, rate float64, keys ...schedule.StoreLimitKey) error {
 	c, err := h.GetOperatorController()
 	if err != nil {
 		return err
 	}
-	c.SetStoreLimit(storeID, rate, schedule.StoreLimitManual)
+	c.SetStoreLimit(storeID, rate, schedule.StoreLimitManual, keys...)
 	return nil
 }
 
@@ -830,6 +883,16 @@ func (h *Handler) GetEmptyRegion() ([]*core.RegionInfo, error) {
 	return c.GetRegionStatsByType(statistics.EmptyRegion), nil
 }
 
+// GetIsolationViolatedRegions gets the regions whose peers violate the
+// isolation level of placement rules.
+func (h *Handler) GetIsolationViolatedRegions() ([]*core.RegionInfo, error) {
+	c := h.s.GetRaftCluster()
+	if c == nil {
+		return nil, cluster.ErrNotBootstrapped
+	}
+	return c.GetRegionStatsByType(statistics.IsolationViolated), nil
+}
+
 // ResetTS resets the ts with specified tso.
 func (h *Handler) ResetTS(ts uint64) error {
 	tsoServer := h.s.tso
@@ -839,6 +902,33 @@ func (h *Handler) ResetTS(ts uint64) error {
 	return tsoServer.ResetUserTimestamp(ts)
 }
 
+// TSOStatus is the status of the TSO service of a server.
+type TSOStatus struct {
+	Global *tso.Status `json:"global"`
+	// DCLocation and Local are the status of the local allocator, which are
+	// empty if dc-location is not set.
+	DCLocation string      `json:"dc_location,omitempty"`
+	Local      *tso.Status `json:"local,omitempty"`
+	ClockJumps []ClockJump `json:"clock_jumps"`
+}
+
+// GetTSOStatus returns the status of the TSO service.
+func (h *Handler) GetTSOStatus() (*TSOStatus, error) {
+	tsoServer := h.s.tso
+	if tsoServer == nil {
+		return nil, ErrServerNotStarted
+	}
+	status := &TSOStatus{
+		Global:     tsoServer.GetStatus(),
+		ClockJumps: h.s.GetClockJumps(),
+	}
+	if localTSO := h.s.GetLocalTSOAllocator(); localTSO != nil {
+		status.DCLocation = localTSO.GetDCLocation()
+		status.Local = localTSO.GetStatus()
+	}
+	return status, nil
+}
+
 // SetStoreLimitScene sets the limit values for differents scenes
 func (h *Handler) SetStoreLimitScene(scene *schedule.StoreLimitScene) {
 	cluster := h.s.GetRaftCluster()
@@ -112,6 +112,32 @@ func (kv *etcdKVBase) Remove(key string) error {
 	return nil
 }
 
+func (kv *etcdKVBase) Batch(ops ...Op) error {
+	if len(ops) > MaxBatchOps {
+		return errors.Errorf("too many ops in a batch: %d > %d", len(ops), MaxBatchOps)
+	}
+	etcdOps := make([]clientv3.Op, 0, len(ops))
+	for _, op := range ops {
+		key := path.Join(kv.rootPath, op.Key)
+		if op.Remove {
+			etcdOps = append(etcdOps, clientv3.OpDelete(key))
+		} else {
+			etcdOps = append(etcdOps, clientv3.OpPut(key, op.Value))
+		}
+	}
+
+	txn := NewSlowLogTxn(kv.client)
+	resp, err := txn.Then(etcdOps...).Commit()
+	if err != nil {
+		log.Error("batch to etcd meet error", zap.Error(err))
+		return errors.WithStack(err)
+	}
+	if !resp.Succeeded {
+		return errors.WithStack(errTxnFailed)
+	}
+	return nil
+}
+
 // SlowLogTxn wraps etcd transaction and log slow one.
 type SlowLogTxn struct {
 	clientv3.Txn
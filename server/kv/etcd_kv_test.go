@@ -87,6 +87,15 @@ func (s *testEtcdKVSuite) TestEtcdKV(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v, Equals, "")
 
+	c.Assert(kv.Batch(OpSave(keys[1], vals[1]), OpRemove(keys[2])), IsNil)
+	v, err = kv.Load(keys[1])
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "val2")
+	v, err = kv.Load(keys[2])
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "")
+	c.Assert(kv.Batch(make([]Op, MaxBatchOps+1)...), NotNil)
+
 	etcd.Close()
 	cleanConfig(cfg)
 }
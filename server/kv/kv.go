@@ -19,4 +19,28 @@ type Base interface {
 	LoadRange(key, endKey string, limit int) (keys []string, values []string, err error)
 	Save(key, value string) error
 	Remove(key string) error
+	// Batch applies the ops atomically. The number of ops should not exceed
+	// MaxBatchOps.
+	Batch(ops ...Op) error
+}
+
+// MaxBatchOps is the max number of ops in a batch, which is the default limit
+// of the operations in an etcd transaction.
+const MaxBatchOps = 128
+
+// Op is a save or a remove in a batch.
+type Op struct {
+	Key    string
+	Value  string
+	Remove bool
+}
+
+// OpSave returns an op which saves the key-value pair.
+func OpSave(key, value string) Op {
+	return Op{Key: key, Value: value}
+}
+
+// OpRemove returns an op which removes the key.
+func OpRemove(key string) Op {
+	return Op{Key: key, Remove: true}
 }
@@ -17,6 +17,7 @@ import (
 	"sync"
 
 	"github.com/google/btree"
+	"github.com/pkg/errors"
 )
 
 type memoryKV struct {
@@ -76,3 +77,19 @@ func (kv *memoryKV) Remove(key string) error {
 	kv.tree.Delete(memoryKVItem{key, ""})
 	return nil
 }
+
+func (kv *memoryKV) Batch(ops ...Op) error {
+	if len(ops) > MaxBatchOps {
+		return errors.Errorf("too many ops in a batch: %d > %d", len(ops), MaxBatchOps)
+	}
+	kv.Lock()
+	defer kv.Unlock()
+	for _, op := range ops {
+		if op.Remove {
+			kv.tree.Delete(memoryKVItem{op.Key, ""})
+		} else {
+			kv.tree.ReplaceOrInsert(memoryKVItem{op.Key, op.Value})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package member
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/failpoint"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/etcdutil"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// healthProbeFile is written and synced in the data directory to probe
+	// the disk, which is shared with the etcd WAL.
+	healthProbeFile  = "health_probe"
+	diskProbeTimeout = 5 * time.Second
+	// maxUnhealthyChecks is the number of consecutive checks in which the
+	// leader is unhealthy before it steps down, so a transient spike does not
+	// move the leadership.
+	maxUnhealthyChecks = 3
+	// staleHealthChecks is the number of check intervals after which the
+	// score of a member is stale and the member is not chosen as the next
+	// leader.
+	staleHealthChecks = 3
+)
+
+// latencyPenalty deducts nothing from the score if the latency is not longer
+// than good, and deducts max if it is not shorter than bad, linearly between.
+type latencyPenalty struct {
+	good time.Duration
+	bad  time.Duration
+	max  float64
+}
+
+// The max penalties sum up to 100, and the disk related ones are the heaviest
+// since a slow disk slows down all the writes of etcd.
+var (
+	diskProbePenalty = latencyPenalty{good: 20 * time.Millisecond, bad: time.Second, max: 40}
+	etcdWritePenalty = latencyPenalty{good: 100 * time.Millisecond, bad: 2 * time.Second, max: 30}
+	peerRTTPenalty   = latencyPenalty{good: 50 * time.Millisecond, bad: time.Second, max: 15}
+	tsoSavePenalty   = latencyPenalty{good: 50 * time.Millisecond, bad: time.Second, max: 15}
+)
+
+func (p latencyPenalty) of(latency time.Duration) float64 {
+	switch {
+	case latency <= p.good:
+		return 0
+	case latency >= p.bad:
+		return p.max
+	}
+	return p.max * float64(latency-p.good) / float64(p.bad-p.good)
+}
+
+// HealthScore is the health of a member scored from 0 to 100 by its disk and
+// etcd latency. The peer RTT and TSO save latency are only measured on the
+// leader.
+type HealthScore struct {
+	Score            float64           `json:"score"`
+	DiskProbeLatency typeutil.Duration `json:"disk_probe_latency"`
+	// DiskError is set if the disk probe fails, which scores 0.
+	DiskError string `json:"disk_error,omitempty"`
+	// EtcdWriteLatency is the latency of saving the score last time.
+	EtcdWriteLatency typeutil.Duration `json:"etcd_write_latency"`
+	// PeerRTT is the average latency of sending raft messages to the
+	// followers.
+	PeerRTT        typeutil.Duration `json:"peer_rtt"`
+	TSOSaveLatency typeutil.Duration `json:"tso_save_latency"`
+	UpdateTime     time.Time         `json:"update_time"`
+}
+
+func (s *HealthScore) calculate() float64 {
+	if s.DiskError != "" {
+		return 0
+	}
+	return 100 - diskProbePenalty.of(s.DiskProbeLatency.Duration) -
+		etcdWritePenalty.of(s.EtcdWriteLatency.Duration) -
+		peerRTTPenalty.of(s.PeerRTT.Duration) -
+		tsoSavePenalty.of(s.TSOSaveLatency.Duration)
+}
+
+// healthChecker keeps the state between the health checks of a member.
+type healthChecker struct {
+	// probing is 1 if a disk probe is running, which may hang on a dying
+	// disk.
+	probing          int64
+	lastWriteLatency time.Duration
+	unhealthyChecks  int
+	unhealthy        bool
+}
+
+// CheckHealth scores the health of the member and saves the score in etcd. If
+// the member is the leader and its score stays below the threshold, it steps
+// down and transfers the leadership to the healthiest member. The interval is
+// the interval of the checks.
+func (m *Member) CheckHealth(ctx context.Context, dataDir string, tsoSaveLatency time.Duration, threshold float64, interval time.Duration) *HealthScore {
+	score := &HealthScore{
+		EtcdWriteLatency: typeutil.NewDuration(m.health.lastWriteLatency),
+		PeerRTT:          typeutil.NewDuration(m.getPeerRTT()),
+		TSOSaveLatency:   typeutil.NewDuration(tsoSaveLatency),
+		UpdateTime:       time.Now(),
+	}
+	if latency, err := m.probeDisk(dataDir); err != nil {
+		log.Error("failed to probe disk", zap.Error(err))
+		score.DiskError = err.Error()
+	} else {
+		score.DiskProbeLatency = typeutil.NewDuration(latency)
+	}
+	score.Score = score.calculate()
+	failpoint.Inject("unhealthyMember", func(val failpoint.Value) {
+		if name, ok := val.(string); ok && name == m.member.GetName() {
+			score.Score = 0
+		}
+	})
+
+	start := time.Now()
+	if err := m.setMemberHealthScore(m.ID(), score); err != nil {
+		log.Error("failed to save health score", zap.Error(err))
+		m.health.lastWriteLatency = etcdWritePenalty.bad
+	} else {
+		m.health.lastWriteLatency = time.Since(start)
+	}
+
+	m.health.unhealthy = score.Score < threshold
+	if !m.health.unhealthy {
+		m.health.unhealthyChecks = 0
+		return score
+	}
+	m.health.unhealthyChecks++
+	log.Warn("member is unhealthy", zap.Float64("score", score.Score), zap.Float64("threshold", threshold), zap.Int("checks", m.health.unhealthyChecks))
+	if m.GetEtcdLeader() != m.ID() || m.health.unhealthyChecks < maxUnhealthyChecks {
+		return score
+	}
+	next := m.getHealthiestMember(threshold, interval)
+	if next == "" {
+		log.Warn("leader is unhealthy but no healthy member to transfer to", zap.Float64("score", score.Score))
+		return score
+	}
+	log.Warn("leader is unhealthy, step down", zap.Float64("score", score.Score), zap.String("next-leader", next))
+	if err := m.ResignLeader(ctx, m.member.GetName(), next); err != nil {
+		log.Error("failed to resign unhealthy leader", zap.Error(err))
+		return score
+	}
+	m.health.unhealthyChecks = 0
+	return score
+}
+
+// IsUnhealthy returns if the score of the member is below the threshold in the
+// last health check.
+func (m *Member) IsUnhealthy() bool {
+	return m.health.unhealthy
+}
+
+// probeDisk writes and syncs a file in the data directory, and returns the
+// latency. A probe that does not finish in time is considered failed.
+func (m *Member) probeDisk(dataDir string) (time.Duration, error) {
+	if !atomic.CompareAndSwapInt64(&m.health.probing, 0, 1) {
+		return 0, errors.New("the last disk probe is not finished")
+	}
+	type result struct {
+		latency time.Duration
+		err     error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		defer atomic.StoreInt64(&m.health.probing, 0)
+		start := time.Now()
+		err := writeProbeFile(filepath.Join(dataDir, healthProbeFile))
+		ch <- result{latency: time.Since(start), err: err}
+	}()
+	select {
+	case r := <-ch:
+		return r.latency, r.err
+	case <-time.After(diskProbeTimeout):
+		return 0, errors.Errorf("disk probe does not finish in %v", diskProbeTimeout)
+	}
+}
+
+func writeProbeFile(name string) error {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer f.Close()
+	if _, err = f.WriteString(time.Now().String()); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(f.Sync())
+}
+
+// getPeerRTT returns the average latency of the etcd leader sending raft
+// messages to the followers, or 0 if the member is not the etcd leader.
+func (m *Member) getPeerRTT() time.Duration {
+	data := m.etcd.Server.LeaderStats()
+	if len(data) == 0 {
+		return 0
+	}
+	var stats struct {
+		Followers map[string]struct {
+			Latency struct {
+				// Current is in milliseconds.
+				Current float64 `json:"current"`
+			} `json:"latency"`
+		} `json:"followers"`
+	}
+	if err := json.Unmarshal(data, &stats); err != nil || len(stats.Followers) == 0 {
+		return 0
+	}
+	var total float64
+	for _, f := range stats.Followers {
+		total += f.Latency.Current
+	}
+	return time.Duration(total / float64(len(stats.Followers)) * float64(time.Millisecond))
+}
+
+// getHealthiestMember returns the name of the member with the highest score,
+// which is not stale and not below the threshold.
+func (m *Member) getHealthiestMember(threshold float64, interval time.Duration) string {
+	res, err := etcdutil.ListEtcdMembers(m.client)
+	if err != nil {
+		log.Error("failed to list etcd members", zap.Error(err))
+		return ""
+	}
+	var next string
+	best := threshold
+	for _, member := range res.Members {
+		if member.ID == m.ID() {
+			continue
+		}
+		score, err := m.GetMemberHealthScore(member.ID)
+		if err != nil {
+			log.Error("failed to load health score", zap.Uint64("member", member.ID), zap.Error(err))
+			continue
+		}
+		if score == nil || time.Since(score.UpdateTime) > staleHealthChecks*interval {
+			continue
+		}
+		if score.Score >= best {
+			next, best = member.Name, score.Score
+		}
+	}
+	return next
+}
+
+func (m *Member) getMemberHealthScorePath(id uint64) string {
+	return path.Join(m.rootPath, fmt.Sprintf("member/%d/health_score", id))
+}
+
+func (m *Member) setMemberHealthScore(id uint64, score *HealthScore) error {
+	key := m.getMemberHealthScorePath(id)
+	value, err := json.Marshal(score)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ctx, cancel := context.WithTimeout(m.client.Ctx(), etcdutil.DefaultRequestTimeout)
+	defer cancel()
+	_, err = m.client.Put(ctx, key, string(value))
+	return errors.WithStack(err)
+}
+
+// DeleteMemberHealthScore removes a member's health score.
+func (m *Member) DeleteMemberHealthScore(id uint64) error {
+	key := m.getMemberHealthScorePath(id)
+	ctx, cancel := context.WithTimeout(m.client.Ctx(), etcdutil.DefaultRequestTimeout)
+	defer cancel()
+	_, err := m.client.Delete(ctx, key)
+	return errors.WithStack(err)
+}
+
+// GetMemberHealthScore loads a member's health score, or nil if the member
+// has not been checked.
+func (m *Member) GetMemberHealthScore(id uint64) (*HealthScore, error) {
+	key := m.getMemberHealthScorePath(id)
+	res, err := etcdutil.EtcdKVGet(m.client, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.Kvs) == 0 {
+		return nil, nil
+	}
+	score := &HealthScore{}
+	if err := json.Unmarshal(res.Kvs[0].Value, score); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return score, nil
+}
@@ -58,6 +58,8 @@ type Member struct {
 	// etcd leader key when the PD node is successfully elected as the leader
 	// of the cluster. Every write will use it to check leadership.
 	memberValue string
+	// health is used only if the leader priority follows the health.
+	health healthChecker
 }
 
 // NewMember create a new Member.
@@ -157,10 +159,11 @@ func (m *Member) CheckLeader(name string) (*pdpb.Member, int64, bool) {
 	return leader, rev, false
 }
 
-// CheckPriority if the leader will be moved according to the priority.
+// CheckPriority if the leader will be moved according to the priority. An
+// unhealthy member does not take over the leadership.
 func (m *Member) CheckPriority(ctx context.Context) {
 	etcdLeader := m.GetEtcdLeader()
-	if etcdLeader == m.ID() || etcdLeader == 0 {
+	if etcdLeader == m.ID() || etcdLeader == 0 || m.IsUnhealthy() {
 		return
 	}
 	myPriority, err := m.GetMemberLeaderPriority(m.ID())
@@ -24,6 +24,14 @@ var (
 			Help:      "Counter of system time jumps backward.",
 		})
 
+	timeJumpForwardCounter = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "monitor",
+			Name:      "time_jump_forward_total",
+			Help:      "Counter of system time jumps forward.",
+		})
+
 	regionHeartbeatCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: "pd",
@@ -57,6 +65,14 @@ var (
 			Help:      "Etcd raft states.",
 		}, []string{"type"})
 
+	healthScoreGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "server",
+			Name:      "health_score",
+			Help:      "The health score of the member, with which the leader steps down automatically.",
+		})
+
 	tsoHandleDuration = prometheus.NewHistogram(
 		prometheus.HistogramOpts{
 			Namespace: "pd",
@@ -69,9 +85,11 @@ var (
 
 func init() {
 	prometheus.MustRegister(timeJumpBackCounter)
+	prometheus.MustRegister(timeJumpForwardCounter)
 	prometheus.MustRegister(regionHeartbeatCounter)
 	prometheus.MustRegister(regionHeartbeatLatency)
 	prometheus.MustRegister(metadataGauge)
 	prometheus.MustRegister(etcdStateGauge)
+	prometheus.MustRegister(healthScoreGauge)
 	prometheus.MustRegister(tsoHandleDuration)
 }
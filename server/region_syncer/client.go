@@ -15,6 +15,7 @@ package syncer
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -43,6 +44,7 @@ func (s *RegionSyncer) StopSyncWithLeader() {
 	s.closed = make(chan struct{})
 	s.Unlock()
 	s.wg.Wait()
+	atomic.StoreInt64(&s.lastSyncTime, 0)
 }
 
 func (s *RegionSyncer) reset() {
@@ -189,7 +191,19 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 						s.history.Record(core.NewRegionInfo(r, nil))
 					}
 				}
+				atomic.StoreInt64(&s.lastSyncTime, time.Now().UnixNano())
 			}
 		}
 	}()
 }
+
+// GetLastSyncTime returns the time when the follower receives the last
+// response from the leader, which is updated at least every keepalive
+// interval. It returns the zero time if it is not syncing with the leader.
+func (s *RegionSyncer) GetLastSyncTime() time.Time {
+	t := atomic.LoadInt64(&s.lastSyncTime)
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, t)
+}
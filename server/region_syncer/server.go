@@ -76,6 +76,9 @@ type RegionSyncer struct {
 	history            *historyBuffer
 	limit              *ratelimit.Bucket
 	securityConfig     *grpcutil.SecurityConfig
+	// lastSyncTime is the unix nano time when the follower receives the last
+	// response from the leader, 0 if it is not syncing with the leader.
+	lastSyncTime int64
 }
 
 // NewRegionSyncer returns a region syncer.
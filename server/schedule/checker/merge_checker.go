@@ -28,6 +28,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// MergeCheckerName is the name of the merge checker.
+const MergeCheckerName = "merge-checker"
+
 // MergeChecker ensures region to merge with adjacent region when size is small
 type MergeChecker struct {
 	cluster     opt.Cluster
@@ -103,6 +106,12 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 		return nil
 	}
 
+	// skip region labeled to deny merge
+	if m.cluster.GetRegionLabeler().IsMergeDenied(region) {
+		checkerCounter.WithLabelValues("merge_checker", "merge-denied").Inc()
+		return nil
+	}
+
 	prev, next := m.cluster.GetAdjacentRegions(region)
 
 	var target *core.RegionInfo
@@ -152,6 +161,9 @@ func (m *MergeChecker) allowMerge(region *core.RegionInfo, adjacent *core.Region
 	if m.cluster.IsPlacementRulesEnabled() && len(m.ruleManager.GetSplitKeys(start, end)) > 0 {
 		return false
 	}
+	if m.cluster.GetRegionLabeler().IsMergeDenied(adjacent) {
+		return false
+	}
 	policy := m.cluster.GetKeyType()
 	switch policy {
 	case core.Table:
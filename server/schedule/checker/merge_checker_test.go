@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
@@ -185,6 +186,28 @@ func (s *testMergeCheckerSuite) TestBasic(c *C) {
 	c.Assert(ops[1].RegionID(), Equals, s.regions[1].GetID())
 	s.mc.ruleManager.DeleteRule("test", "test")
 
+	// merge cannot involve regions labeled to deny merge.
+	regionLabeler := s.cluster.GetRegionLabeler()
+	regionLabeler.SetLabelRule(&labeler.LabelRule{
+		ID:          "test",
+		StartKeyHex: hex.EncodeToString([]byte("x")),
+		EndKeyHex:   "",
+		Labels:      map[string]string{labeler.MergeLabel: labeler.DenyValue},
+	})
+	ops = s.mc.Check(s.regions[2])
+	c.Assert(ops, NotNil)
+	c.Assert(ops[0].RegionID(), Equals, s.regions[2].GetID())
+	c.Assert(ops[1].RegionID(), Equals, s.regions[1].GetID())
+	regionLabeler.SetLabelRule(&labeler.LabelRule{
+		ID:          "test",
+		StartKeyHex: hex.EncodeToString([]byte("t")),
+		EndKeyHex:   hex.EncodeToString([]byte("x")),
+		Labels:      map[string]string{labeler.MergeLabel: labeler.DenyValue},
+	})
+	ops = s.mc.Check(s.regions[2])
+	c.Assert(ops, IsNil)
+	regionLabeler.DeleteLabelRule("test")
+
 	// Skip recently split regions.
 	s.cluster.ScheduleOptions.SplitMergeInterval = time.Hour
 	s.mc.RecordRegionSplit([]uint64{s.regions[2].GetID()})
@@ -27,7 +27,8 @@ import (
 )
 
 const (
-	replicaCheckerName = "replica-checker"
+	// ReplicaCheckerName is the name of the replica checker.
+	ReplicaCheckerName = "replica-checker"
 )
 
 const (
@@ -48,12 +49,12 @@ type ReplicaChecker struct {
 
 // NewReplicaChecker creates a replica checker.
 func NewReplicaChecker(cluster opt.Cluster, n ...string) *ReplicaChecker {
-	name := replicaCheckerName
+	name := ReplicaCheckerName
 	if len(n) != 0 {
 		name = n[0]
 	}
 	filters := []filter.Filter{
-		filter.NewStoreLimitFilter(name),
+		filter.NewStoreLimitFilter(name, operator.OpReplica.String()),
 		filter.NewHealthFilter(name),
 		filter.NewSnapshotCountFilter(name),
 		filter.NewPendingPeerCountFilter(name),
@@ -67,11 +68,14 @@ func NewReplicaChecker(cluster opt.Cluster, n ...string) *ReplicaChecker {
 }
 
 // Check verifies a region's replicas, creating an operator.Operator if need.
+// The operators repairing down peers and missing replicas are urgent, since
+// the regions may lose the majority otherwise.
 func (r *ReplicaChecker) Check(region *core.RegionInfo) *operator.Operator {
 	checkerCounter.WithLabelValues("replica_checker", "check").Inc()
 	if op := r.checkDownPeer(region); op != nil {
 		checkerCounter.WithLabelValues("replica_checker", "new-operator").Inc()
 		op.SetPriorityLevel(core.HighPriority)
+		op.SetUrgent()
 		return op
 	}
 	if op := r.checkOfflinePeer(region); op != nil {
@@ -93,6 +97,7 @@ func (r *ReplicaChecker) Check(region *core.RegionInfo) *operator.Operator {
 			log.Debug("create make-up-replica operator fail", zap.Error(err))
 			return nil
 		}
+		op.SetUrgent()
 		return op
 	}
 
@@ -137,4 +137,5 @@ func (s *testReplicaCheckerSuite) TestOfflineWithOneReplica(c *C) {
 	op := s.rc.Check(r)
 	c.Assert(op, NotNil)
 	c.Assert(op.Desc(), Equals, "replace-offline-replica")
+	c.Assert(op.IsUrgent(), IsFalse)
 }
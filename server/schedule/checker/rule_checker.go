@@ -49,6 +49,7 @@ func (c *RuleChecker) Check(region *core.RegionInfo) *operator.Operator {
 	checkerCounter.WithLabelValues("rule_checker", "check").Inc()
 
 	fit := c.cluster.FitRegion(region)
+	c.cluster.ObserveRuleFit(region, fit)
 	if len(fit.RuleFits) == 0 {
 		checkerCounter.WithLabelValues("rule_checker", "fix-range").Inc()
 		// If the region matches no rules, the most possible reason is it spans across
@@ -70,6 +71,14 @@ func (c *RuleChecker) Check(region *core.RegionInfo) *operator.Operator {
 		log.Debug("fail to fix orphan peer", zap.Error(err))
 		return nil
 	}
+	if op != nil {
+		return op
+	}
+	op, err = c.fixLeaderPreference(region, fit)
+	if err != nil {
+		log.Debug("fail to fix leader preference", zap.Error(err))
+		return nil
+	}
 	return op
 }
 
@@ -82,15 +91,15 @@ func (c *RuleChecker) fixRange(region *core.RegionInfo) *operator.Operator {
 }
 
 func (c *RuleChecker) fixRulePeer(region *core.RegionInfo, fit *placement.RegionFit, rf *placement.RuleFit) (*operator.Operator, error) {
-	// make up peers.
+	// make up peers, which is urgent as repairing down peers.
 	if len(rf.Peers) < rf.Rule.Count {
-		return c.addRulePeer(region, rf)
+		return urgent(c.addRulePeer(region, rf))
 	}
 	// fix down/offline peers.
 	for _, peer := range rf.Peers {
 		if c.isDownPeer(region, peer) {
 			checkerCounter.WithLabelValues("rule_checker", "replace-down").Inc()
-			return c.replaceRulePeer(region, fit, rf, peer, downStatus)
+			return urgent(c.replaceRulePeer(region, fit, rf, peer, downStatus))
 		}
 		if c.isOfflinePeer(region, peer) {
 			checkerCounter.WithLabelValues("rule_checker", "replace-offline").Inc()
@@ -107,9 +116,24 @@ func (c *RuleChecker) fixRulePeer(region *core.RegionInfo, fit *placement.Region
 			return op, nil
 		}
 	}
+	// fix peers that violate the isolation level.
+	if !rf.IsIsolationSatisfied() {
+		op, err := c.fixIsolation(region, fit, rf)
+		if op != nil || err != nil {
+			return op, err
+		}
+	}
 	return c.fixBetterLocation(region, fit, rf)
 }
 
+// urgent marks the operator repairing a down peer or a missing replica.
+func urgent(op *operator.Operator, err error) (*operator.Operator, error) {
+	if op != nil {
+		op.SetUrgent()
+	}
+	return op, err
+}
+
 func (c *RuleChecker) addRulePeer(region *core.RegionInfo, rf *placement.RuleFit) (*operator.Operator, error) {
 	checkerCounter.WithLabelValues("rule_checker", "add-rule-peer").Inc()
 	store := SelectStoreToAddPeerByRule(c.name, c.cluster, region, rf)
@@ -175,13 +199,17 @@ func (c *RuleChecker) fixBetterLocation(region *core.RegionInfo, fit *placement.
 		return nil, nil
 	}
 	stores := getRuleFitStores(c.cluster, rf)
-	s := selector.NewReplicaSelector(stores, rf.Rule.LocationLabels, filter.StoreStateFilter{ActionScope: "rule-checker", MoveRegion: true})
+	s := selector.NewReplicaSelector(stores, rf.Rule.LocationLabels, filter.StoreStateFilter{ActionScope: "rule-checker", MoveRegion: true, OpKind: operator.OpReplica.String()})
 	oldPeerStore := s.SelectSource(c.cluster, stores)
 	if oldPeerStore == nil {
 		return nil, nil
 	}
 	oldPeer := region.GetStorePeer(oldPeerStore.GetID())
 	newPeerStore := SelectStoreToReplacePeerByRule("rule-checker", c.cluster, region, fit, rf, oldPeer)
+	if newPeerStore == nil {
+		log.Debug("no replacement store", zap.Uint64("region-id", region.GetID()))
+		return nil, nil
+	}
 	stores = getRuleFitStores(c.cluster, removePeerFromRuleFit(rf, oldPeer))
 	oldScore := core.DistinctScore(rf.Rule.LocationLabels, stores, oldPeerStore)
 	newScore := core.DistinctScore(rf.Rule.LocationLabels, stores, newPeerStore)
@@ -194,6 +222,33 @@ func (c *RuleChecker) fixBetterLocation(region *core.RegionInfo, fit *placement.
 	return operator.CreateMovePeerOperator("move-to-better-location", c.cluster, region, operator.OpReplica, oldPeer.GetStoreId(), newPeer)
 }
 
+func (c *RuleChecker) fixIsolation(region *core.RegionInfo, fit *placement.RegionFit, rf *placement.RuleFit) (*operator.Operator, error) {
+	checkerCounter.WithLabelValues("rule_checker", "fix-isolation").Inc()
+	for _, peer := range rf.Peers {
+		store := c.cluster.GetStore(peer.GetStoreId())
+		if store == nil {
+			continue
+		}
+		others := getRuleFitStores(c.cluster, removePeerFromRuleFit(rf, peer))
+		f := filter.NewIsolationFilter(c.name, rf.Rule.IsolationLevel, rf.Rule.LocationLabels, others)
+		if !f.Target(c.cluster, store) {
+			continue
+		}
+		// The peer shares location with another peer at the isolation level,
+		// so it is only moved to a store which satisfies the isolation level.
+		newPeerStore := SelectStoreToReplacePeerByRule(c.name, c.cluster, region, fit, rf, peer, f)
+		if newPeerStore == nil {
+			continue
+		}
+		newPeer := &metapb.Peer{StoreId: newPeerStore.GetID(), IsLearner: peer.IsLearner}
+		return operator.CreateMovePeerOperator("fix-isolation-level", c.cluster, region, operator.OpReplica, peer.GetStoreId(), newPeer)
+	}
+	// The isolation level can not be satisfied for now, try to improve the
+	// location instead.
+	checkerCounter.WithLabelValues("rule_checker", "no-store-isolation").Inc()
+	return nil, nil
+}
+
 func (c *RuleChecker) fixOrphanPeers(region *core.RegionInfo, fit *placement.RegionFit) (*operator.Operator, error) {
 	if len(fit.OrphanPeers) == 0 {
 		return nil, nil
@@ -203,6 +258,32 @@ func (c *RuleChecker) fixOrphanPeers(region *core.RegionInfo, fit *placement.Reg
 	return operator.CreateRemovePeerOperator("remove-orphan-peer", c.cluster, 0, region, peer.StoreId)
 }
 
+func (c *RuleChecker) fixLeaderPreference(region *core.RegionInfo, fit *placement.RegionFit) (*operator.Operator, error) {
+	prefs := fit.GetLeaderPreferences()
+	if len(prefs) == 0 {
+		return nil, nil
+	}
+	leaderStoreID := region.GetLeader().GetStoreId()
+	bestRank := len(prefs)
+	if leaderStore := c.cluster.GetStore(leaderStoreID); leaderStore != nil {
+		bestRank = placement.LeaderPreferenceRank(leaderStore, prefs)
+	}
+	var target *metapb.Peer
+	for _, p := range region.GetPeers() {
+		if p.GetStoreId() == leaderStoreID || !c.allowLeader(fit, p) {
+			continue
+		}
+		if rank := placement.LeaderPreferenceRank(c.cluster.GetStore(p.GetStoreId()), prefs); rank < bestRank {
+			target, bestRank = p, rank
+		}
+	}
+	if target == nil {
+		return nil, nil
+	}
+	checkerCounter.WithLabelValues("rule_checker", "fix-leader-preference").Inc()
+	return operator.CreateTransferLeaderOperator("fix-leader-preference", c.cluster, region, leaderStoreID, target.GetStoreId(), 0)
+}
+
 func (c *RuleChecker) isDownPeer(region *core.RegionInfo, peer *metapb.Peer) bool {
 	for _, stats := range region.GetDownPeers() {
 		if stats.GetPeer().GetId() != peer.GetId() {
@@ -237,15 +318,22 @@ func (c *RuleChecker) isOfflinePeer(region *core.RegionInfo, peer *metapb.Peer)
 // SelectStoreToAddPeerByRule selects a store to add peer in order to fit the placement rule.
 func SelectStoreToAddPeerByRule(scope string, cluster opt.Cluster, region *core.RegionInfo, rf *placement.RuleFit, filters ...filter.Filter) *core.StoreInfo {
 	fs := []filter.Filter{
-		filter.StoreStateFilter{ActionScope: scope, MoveRegion: true},
+		filter.StoreStateFilter{ActionScope: scope, MoveRegion: true, OpKind: operator.OpReplica.String()},
 		filter.NewStorageThresholdFilter(scope),
 		filter.NewLabelConstaintFilter(scope, rf.Rule.LabelConstraints),
 		filter.NewExcludedFilter(scope, nil, region.GetStoreIds()),
 	}
 	fs = append(fs, filters...)
-	store := selector.NewReplicaSelector(getRuleFitStores(cluster, rf), rf.Rule.LocationLabels).
-		SelectTarget(cluster, cluster.GetStores(), fs...)
-	return store
+	s := selector.NewReplicaSelector(getRuleFitStores(cluster, rf), rf.Rule.LocationLabels)
+	// The stores which satisfy the isolation level are preferred. If there is
+	// no such store, the peer is still placed as isolated as possible.
+	if rf.Rule.IsolationLevel != "" {
+		isolationFilter := filter.NewIsolationFilter(scope, rf.Rule.IsolationLevel, rf.Rule.LocationLabels, getRuleFitStores(cluster, rf))
+		if store := s.SelectTarget(cluster, cluster.GetStores(), append(fs, isolationFilter)...); store != nil {
+			return store
+		}
+	}
+	return s.SelectTarget(cluster, cluster.GetStores(), fs...)
 }
 
 // SelectStoreToReplacePeerByRule selects a store to replace a region peer in order to fit the placement rule.
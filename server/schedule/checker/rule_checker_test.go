@@ -70,6 +70,7 @@ func (s *testRuleCheckerSuite) TestAddRulePeer(c *C) {
 	op := s.rc.Check(s.cluster.GetRegion(1))
 	c.Assert(op, NotNil)
 	c.Assert(op.Desc(), Equals, "add-rule-peer")
+	c.Assert(op.IsUrgent(), IsTrue)
 	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(3))
 }
 
@@ -87,6 +88,7 @@ func (s *testRuleCheckerSuite) TestFixPeer(c *C) {
 	op = s.rc.Check(r)
 	c.Assert(op, NotNil)
 	c.Assert(op.Desc(), Equals, "replace-rule-down-peer")
+	c.Assert(op.IsUrgent(), IsTrue)
 	var add operator.AddLearner
 	c.Assert(op.Step(0), FitsTypeOf, add)
 	s.cluster.SetStoreUp(2)
@@ -94,6 +96,7 @@ func (s *testRuleCheckerSuite) TestFixPeer(c *C) {
 	op = s.rc.Check(s.cluster.GetRegion(1))
 	c.Assert(op, NotNil)
 	c.Assert(op.Desc(), Equals, "replace-rule-offline-peer")
+	c.Assert(op.IsUrgent(), IsFalse)
 	c.Assert(op.Step(0), FitsTypeOf, add)
 }
 
@@ -174,8 +177,101 @@ func (s *testRuleCheckerSuite) TestBetterReplacement(c *C) {
 	op := s.rc.Check(s.cluster.GetRegion(1))
 	c.Assert(op, NotNil)
 	c.Assert(op.Desc(), Equals, "move-to-better-location")
+	c.Assert(op.IsUrgent(), IsFalse)
 	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(4))
 	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 3, 4)
 	op = s.rc.Check(s.cluster.GetRegion(1))
 	c.Assert(op, IsNil)
 }
+
+func (s *testRuleCheckerSuite) TestFixIsolationLevel(c *C) {
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1", "host": "h1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z1", "host": "h2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z2", "host": "h3"})
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2, 3)
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID:        "pd",
+		ID:             "test",
+		Index:          100,
+		Override:       true,
+		Role:           placement.Voter,
+		Count:          3,
+		LocationLabels: []string{"zone", "host"},
+		IsolationLevel: "zone",
+	})
+	// No store is available in a third zone.
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, IsNil)
+
+	s.cluster.AddLabelsStore(4, 1, map[string]string{"zone": "z1", "host": "h4"})
+	op = s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, IsNil)
+
+	s.cluster.AddLabelsStore(5, 1, map[string]string{"zone": "z3", "host": "h5"})
+	op = s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "fix-isolation-level")
+	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(5))
+
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 3, 5)
+	op = s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, IsNil)
+}
+
+func (s *testRuleCheckerSuite) TestFixIsolationLevelFallback(c *C) {
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1", "rack": "r1", "host": "h1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z1", "rack": "r1", "host": "h2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z2", "rack": "r1", "host": "h3"})
+	s.cluster.AddLabelsStore(4, 1, map[string]string{"zone": "z1", "rack": "r2", "host": "h4"})
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2, 3)
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID:        "pd",
+		ID:             "test",
+		Index:          100,
+		Override:       true,
+		Role:           placement.Voter,
+		Count:          3,
+		LocationLabels: []string{"zone", "rack", "host"},
+		IsolationLevel: "zone",
+	})
+	// The isolation level can not be satisfied, but the location can be
+	// improved.
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "move-to-better-location")
+	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(4))
+}
+
+func (s *testRuleCheckerSuite) TestFixLeaderPreference(c *C) {
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z3"})
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 3, 1, 2)
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID:        "pd",
+		ID:             "test",
+		Index:          100,
+		Override:       true,
+		Role:           placement.Voter,
+		Count:          3,
+		LocationLabels: []string{"zone"},
+		LeaderPreferences: [][]placement.LabelConstraint{
+			{{Key: "zone", Op: "in", Values: []string{"z1"}}},
+			{{Key: "zone", Op: "in", Values: []string{"z2"}}},
+		},
+	})
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "fix-leader-preference")
+	c.Assert(op.Step(0).(operator.TransferLeader).ToStore, Equals, uint64(1))
+
+	// Fall back to the next preference when the most preferred store is unavailable.
+	s.cluster.SetStoreDisconnect(1)
+	op = s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Step(0).(operator.TransferLeader).ToStore, Equals, uint64(2))
+
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 2, 1, 3)
+	op = s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, IsNil)
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"sync/atomic"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
+)
+
+// maxSimulateRounds is the max number of operators applied to a region when
+// simulating. A region that is not satisfied after it is regarded as stuck.
+const maxSimulateRounds = 8
+
+// RuleSimulationResult is the result of simulating placement rules changes.
+type RuleSimulationResult struct {
+	// RegionCount is the number of simulated regions.
+	RegionCount int `json:"region_count"`
+	// UnsatisfiedCount is the number of regions that do not satisfy the new rules.
+	UnsatisfiedCount int `json:"unsatisfied_count"`
+	// NewlyUnsatisfiedCount is the number of regions that satisfy the current
+	// rules but do not satisfy the new rules.
+	NewlyUnsatisfiedCount int `json:"newly_unsatisfied_count"`
+	// SplitCount is the number of regions that need to be split.
+	SplitCount int `json:"split_count"`
+	// StuckCount is the number of regions that cannot be fixed by RuleChecker.
+	StuckCount int `json:"stuck_count"`
+	// StorePeerDelta is the number of peers that each store gains (positive)
+	// or loses (negative).
+	StorePeerDelta map[uint64]int `json:"store_peer_delta"`
+	// StoreLeaderDelta is the number of leaders that each store gains
+	// (positive) or loses (negative).
+	StoreLeaderDelta map[uint64]int `json:"store_leader_delta"`
+	// Operators is a sample of operators that RuleChecker would create.
+	Operators []*operator.Operator `json:"operators"`
+}
+
+// simulatedIDBase is the first ID allocated by simulatedCluster. It is large
+// enough to not conflict with the IDs allocated by the live cluster.
+const simulatedIDBase = uint64(1) << 63
+
+// simulatedCluster fits regions against the simulated rules and allocates
+// fake IDs, so that the live cluster is not affected.
+type simulatedCluster struct {
+	opt.Cluster
+	ruleManager *placement.RuleManager
+	id          uint64
+}
+
+func (c *simulatedCluster) FitRegion(region *core.RegionInfo) *placement.RegionFit {
+	return c.ruleManager.FitRegion(c, region)
+}
+
+// ObserveRuleFit ignores the simulated fits, which are not the statistics of
+// the live cluster.
+func (c *simulatedCluster) ObserveRuleFit(region *core.RegionInfo, fit *placement.RegionFit) {}
+
+func (c *simulatedCluster) AllocID() (uint64, error) {
+	return atomic.AddUint64(&c.id, 1), nil
+}
+
+// SimulateRules simulates how the regions would be scheduled by RuleChecker
+// if the rules of the ruleManager are applied. The ruleManager is usually
+// created by placement.RuleManager.DryRun. At most sampleLimit operators are
+// returned in the result.
+func SimulateRules(cluster opt.Cluster, ruleManager *placement.RuleManager, regions []*core.RegionInfo, sampleLimit int) *RuleSimulationResult {
+	sc := &simulatedCluster{Cluster: cluster, ruleManager: ruleManager, id: simulatedIDBase}
+	rc := NewRuleChecker(sc, ruleManager)
+	res := &RuleSimulationResult{
+		RegionCount:      len(regions),
+		StorePeerDelta:   make(map[uint64]int),
+		StoreLeaderDelta: make(map[uint64]int),
+	}
+	for _, region := range regions {
+		if sc.FitRegion(region).IsSatisfied() {
+			continue
+		}
+		res.UnsatisfiedCount++
+		if cluster.FitRegion(region).IsSatisfied() {
+			res.NewlyUnsatisfiedCount++
+		}
+		res.simulateRegion(sc, rc, region, sampleLimit)
+	}
+	return res
+}
+
+func (res *RuleSimulationResult) simulateRegion(sc *simulatedCluster, rc *RuleChecker, region *core.RegionInfo, sampleLimit int) {
+	for i := 0; i < maxSimulateRounds; i++ {
+		op := rc.Check(region)
+		if op == nil {
+			break
+		}
+		if len(res.Operators) < sampleLimit {
+			res.Operators = append(res.Operators, op)
+		}
+		if _, ok := op.Step(0).(operator.SplitRegion); ok {
+			res.SplitCount++
+			return
+		}
+		region = res.applyOperator(region, op)
+	}
+	if !sc.FitRegion(region).IsSatisfied() {
+		res.StuckCount++
+	}
+}
+
+// applyOperator returns the region after all steps of the operator are
+// finished, and records the changes of the stores.
+func (res *RuleSimulationResult) applyOperator(region *core.RegionInfo, op *operator.Operator) *core.RegionInfo {
+	for i := 0; i < op.Len(); i++ {
+		switch step := op.Step(i).(type) {
+		case operator.TransferLeader:
+			region = region.Clone(core.WithLeader(region.GetStorePeer(step.ToStore)))
+			res.StoreLeaderDelta[step.FromStore]--
+			res.StoreLeaderDelta[step.ToStore]++
+		case operator.AddPeer:
+			region = region.Clone(core.WithAddPeer(&metapb.Peer{Id: step.PeerID, StoreId: step.ToStore}))
+			res.StorePeerDelta[step.ToStore]++
+		case operator.AddLightPeer:
+			region = region.Clone(core.WithAddPeer(&metapb.Peer{Id: step.PeerID, StoreId: step.ToStore}))
+			res.StorePeerDelta[step.ToStore]++
+		case operator.AddLearner:
+			region = region.Clone(core.WithAddPeer(&metapb.Peer{Id: step.PeerID, StoreId: step.ToStore, IsLearner: true}))
+			res.StorePeerDelta[step.ToStore]++
+		case operator.AddLightLearner:
+			region = region.Clone(core.WithAddPeer(&metapb.Peer{Id: step.PeerID, StoreId: step.ToStore, IsLearner: true}))
+			res.StorePeerDelta[step.ToStore]++
+		case operator.PromoteLearner:
+			region = region.Clone(core.WithPromoteLearner(step.PeerID))
+		case operator.RemovePeer:
+			region = region.Clone(core.WithRemoveStorePeer(step.FromStore))
+			res.StorePeerDelta[step.FromStore]--
+		}
+	}
+	return region
+}
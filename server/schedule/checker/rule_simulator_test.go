@@ -0,0 +1,77 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
+)
+
+var _ = Suite(&testRuleSimulatorSuite{})
+
+type testRuleSimulatorSuite struct{}
+
+func (s *testRuleSimulatorSuite) TestSimulateRules(c *C) {
+	cfg := mockoption.NewScheduleOptions()
+	cfg.EnablePlacementRules = true
+	tc := mockcluster.NewCluster(cfg)
+	for i := uint64(1); i <= 4; i++ {
+		tc.AddLeaderStore(i, 1)
+	}
+	tc.AddLeaderRegionWithRange(1, "", "", 1, 2, 3)
+
+	dryRun, err := tc.RuleManager.DryRun([]placement.RuleOp{{
+		Rule: &placement.Rule{
+			GroupID: "pd",
+			ID:      "default",
+			Role:    placement.Voter,
+			Count:   4,
+		},
+		Action: placement.RuleOpAdd,
+	}})
+	c.Assert(err, IsNil)
+	regions := []*core.RegionInfo{tc.GetRegion(1)}
+	res := SimulateRules(tc, dryRun, regions, 10)
+	c.Assert(res.RegionCount, Equals, 1)
+	c.Assert(res.UnsatisfiedCount, Equals, 1)
+	c.Assert(res.NewlyUnsatisfiedCount, Equals, 1)
+	c.Assert(res.StuckCount, Equals, 0)
+	c.Assert(res.StorePeerDelta[4], Equals, 1)
+	c.Assert(res.Operators, HasLen, 1)
+	c.Assert(res.Operators[0].Desc(), Equals, "add-rule-peer")
+
+	// The live cluster is not affected.
+	c.Assert(tc.GetRegion(1).GetPeers(), HasLen, 3)
+	c.Assert(tc.FitRegion(tc.GetRegion(1)).IsSatisfied(), IsTrue)
+
+	// No store is available for the fifth peer.
+	dryRun, err = tc.RuleManager.DryRun([]placement.RuleOp{{
+		Rule: &placement.Rule{
+			GroupID: "pd",
+			ID:      "default",
+			Role:    placement.Voter,
+			Count:   5,
+		},
+		Action: placement.RuleOpAdd,
+	}})
+	c.Assert(err, IsNil)
+	res = SimulateRules(tc, dryRun, regions, 0)
+	c.Assert(res.UnsatisfiedCount, Equals, 1)
+	c.Assert(res.StuckCount, Equals, 1)
+	c.Assert(res.StorePeerDelta[4], Equals, 1)
+	c.Assert(res.Operators, HasLen, 0)
+}
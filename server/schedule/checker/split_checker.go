@@ -0,0 +1,122 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/statistics"
+	"go.uber.org/zap"
+)
+
+// hotRegionSplitRatio is the ratio of the flow of a store above which a single
+// region is regarded as saturating the store. Moving such a region only moves
+// the hot spot to another store, so it has to be split.
+const hotRegionSplitRatio = 0.6
+
+// hotRegionSplitMinByteRate and hotRegionSplitMinKeyRate are the min flow of a
+// region to be split, so that a region which saturates an idle store is not
+// split.
+const (
+	hotRegionSplitMinByteRate = 1024 * 1024
+	hotRegionSplitMinKeyRate  = 1024
+)
+
+// SplitChecker splits the regions which stay hot and cannot be balanced.
+type SplitChecker struct {
+	cluster opt.Cluster
+}
+
+// NewSplitChecker creates a split checker.
+func NewSplitChecker(cluster opt.Cluster) *SplitChecker {
+	return &SplitChecker{
+		cluster: cluster,
+	}
+}
+
+// Check verifies a region's flow, creating an operator to split the region if
+// it saturates any of its stores.
+func (s *SplitChecker) Check(region *core.RegionInfo) *operator.Operator {
+	if !s.cluster.IsRegionHot(region) {
+		return nil
+	}
+	checkerCounter.WithLabelValues("split_checker", "check").Inc()
+
+	if len(region.GetDownPeers()) > 0 || len(region.GetPendingPeers()) > 0 {
+		checkerCounter.WithLabelValues("split_checker", "unhealthy").Inc()
+		return nil
+	}
+	if !s.isSaturated(region) {
+		checkerCounter.WithLabelValues("split_checker", "no-need").Inc()
+		return nil
+	}
+
+	log.Debug("region saturates the store, try to split it", zap.Uint64("region-id", region.GetID()))
+	checkerCounter.WithLabelValues("split_checker", "new-operator").Inc()
+	return operator.CreateSplitRegionOperator("split-hot-region", region, operator.OpSplit, pdpb.CheckPolicy_APPROXIMATE, nil)
+}
+
+// isSaturated checks if the read flow of the leader or the write flow of any
+// peer of the region is high and makes up most of the flow of its store.
+func (s *SplitChecker) isSaturated(region *core.RegionInfo) bool {
+	minHotDegree := s.cluster.GetHotRegionCacheHitsThreshold()
+	storesStats := s.cluster.GetStoresStats()
+
+	leaderStoreID := region.GetLeader().GetStoreId()
+	stat := findHotPeerStat(s.cluster.RegionReadStats(), leaderStoreID, region.GetID())
+	if stat != nil && stat.HotDegree >= minHotDegree &&
+		exceedRatio(stat, leaderStoreID, storesStats.GetStoresBytesReadStat(), storesStats.GetStoresKeysReadStat()) {
+		return true
+	}
+
+	writeStats := s.cluster.RegionWriteStats()
+	var storeByteRate, storeKeyRate map[uint64]float64
+	for _, peer := range region.GetPeers() {
+		stat := findHotPeerStat(writeStats, peer.GetStoreId(), region.GetID())
+		if stat == nil || stat.HotDegree < minHotDegree {
+			continue
+		}
+		if storeByteRate == nil {
+			storeByteRate, storeKeyRate = storesStats.GetStoresBytesWriteStat(), storesStats.GetStoresKeysWriteStat()
+		}
+		if exceedRatio(stat, peer.GetStoreId(), storeByteRate, storeKeyRate) {
+			return true
+		}
+	}
+	return false
+}
+
+func findHotPeerStat(stats map[uint64][]*statistics.HotPeerStat, storeID, regionID uint64) *statistics.HotPeerStat {
+	for _, stat := range stats[storeID] {
+		if stat.RegionID == regionID {
+			return stat
+		}
+	}
+	return nil
+}
+
+func exceedRatio(stat *statistics.HotPeerStat, storeID uint64, storeByteRate, storeKeyRate map[uint64]float64) bool {
+	if rate := storeByteRate[storeID]; rate > 0 && stat.GetByteRate() >= hotRegionSplitMinByteRate &&
+		stat.GetByteRate() >= rate*hotRegionSplitRatio {
+		return true
+	}
+	if rate := storeKeyRate[storeID]; rate > 0 && stat.GetKeyRate() >= hotRegionSplitMinKeyRate &&
+		stat.GetKeyRate() >= rate*hotRegionSplitRatio {
+		return true
+	}
+	return false
+}
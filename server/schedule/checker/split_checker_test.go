@@ -0,0 +1,86 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/statistics"
+)
+
+const MB = 1024 * 1024
+
+var _ = Suite(&testSplitCheckerSuite{})
+
+type testSplitCheckerSuite struct {
+	cluster *mockcluster.Cluster
+	sc      *SplitChecker
+}
+
+func (s *testSplitCheckerSuite) SetUpTest(c *C) {
+	statistics.Denoising = false
+	cfg := mockoption.NewScheduleOptions()
+	cfg.HotRegionCacheHitsThreshold = 0
+	s.cluster = mockcluster.NewCluster(cfg)
+	s.sc = NewSplitChecker(s.cluster)
+	for id := uint64(1); id <= 4; id++ {
+		s.cluster.AddRegionStore(id, 10)
+		s.cluster.UpdateStorageWrittenBytes(id, 10*MB*statistics.StoreHeartBeatReportInterval)
+		s.cluster.UpdateStorageReadBytes(id, 10*MB*statistics.StoreHeartBeatReportInterval)
+	}
+}
+
+func (s *testSplitCheckerSuite) TestSplitHotRegion(c *C) {
+	// The region makes up most of the written bytes of the stores.
+	s.cluster.AddLeaderRegionWithWriteInfo(1, 1, 8*MB*statistics.RegionHeartBeatReportInterval, 0, statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+	op := s.sc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Kind(), Equals, operator.OpSplit)
+	c.Assert(op.Len(), Equals, 1)
+	step, ok := op.Step(0).(operator.SplitRegion)
+	c.Assert(ok, IsTrue)
+	c.Assert(step.Policy, Equals, pdpb.CheckPolicy_APPROXIMATE)
+
+	// The leader serves most of the read bytes of the store.
+	s.cluster.AddLeaderRegionWithReadInfo(2, 4, 8*MB*statistics.RegionHeartBeatReportInterval, 0, statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+	op = s.sc.Check(s.cluster.GetRegion(2))
+	c.Assert(op, NotNil)
+	c.Assert(op.Kind(), Equals, operator.OpSplit)
+}
+
+func (s *testSplitCheckerSuite) TestNoNeedToSplit(c *C) {
+	// The region is hot, but it can be balanced.
+	s.cluster.AddLeaderRegionWithWriteInfo(1, 1, 1*MB*statistics.RegionHeartBeatReportInterval, 0, statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+	c.Assert(s.cluster.IsRegionHot(s.cluster.GetRegion(1)), IsTrue)
+	c.Assert(s.sc.Check(s.cluster.GetRegion(1)), IsNil)
+
+	// The region saturates an idle store, but its flow is low.
+	s.cluster.AddRegionStore(5, 10)
+	s.cluster.UpdateStorageWrittenBytes(5, 100*1024*statistics.StoreHeartBeatReportInterval)
+	s.cluster.AddLeaderRegionWithWriteInfo(4, 5, 80*1024*statistics.RegionHeartBeatReportInterval, 0, statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+	c.Assert(s.cluster.IsRegionHot(s.cluster.GetRegion(4)), IsTrue)
+	c.Assert(s.sc.Check(s.cluster.GetRegion(4)), IsNil)
+
+	// The region is not hot.
+	s.cluster.AddLeaderRegion(2, 1, 2, 3)
+	c.Assert(s.sc.Check(s.cluster.GetRegion(2)), IsNil)
+
+	// The region is not hot enough yet.
+	s.cluster.HotRegionCacheHitsThreshold = 3
+	s.cluster.AddLeaderRegionWithWriteInfo(3, 1, 8*MB*statistics.RegionHeartBeatReportInterval, 0, statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+	c.Assert(s.sc.Check(s.cluster.GetRegion(3)), IsNil)
+}
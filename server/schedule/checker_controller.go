@@ -15,6 +15,7 @@ package schedule
 
 import (
 	"context"
+	"time"
 
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
@@ -31,6 +32,7 @@ type CheckerController struct {
 	replicaChecker *checker.ReplicaChecker
 	ruleChecker    *checker.RuleChecker
 	mergeChecker   *checker.MergeChecker
+	splitChecker   *checker.SplitChecker
 }
 
 // NewCheckerController create a new CheckerController.
@@ -43,6 +45,7 @@ func NewCheckerController(ctx context.Context, cluster opt.Cluster, ruleManager
 		replicaChecker: checker.NewReplicaChecker(cluster),
 		ruleChecker:    checker.NewRuleChecker(cluster, ruleManager),
 		mergeChecker:   checker.NewMergeChecker(ctx, cluster, ruleManager),
+		splitChecker:   checker.NewSplitChecker(cluster),
 	}
 }
 
@@ -52,10 +55,15 @@ func (c *CheckerController) CheckRegion(region *core.RegionInfo) (bool, []*opera
 	// Don't check isRaftLearnerEnabled cause it maybe disable learner feature but there are still some learners to promote.
 	opController := c.opController
 	checkerIsBusy := true
+	now := time.Now()
+	// The replica checker is replaced by the rule checker when the placement
+	// rules are enabled, so they share the same schedule windows. The windows
+	// do not postpone repairing down peers and missing replicas.
+	replicaInWindow := c.cluster.IsInScheduleWindows(checker.ReplicaCheckerName, now)
 	if c.cluster.IsPlacementRulesEnabled() {
 		if opController.OperatorCount(operator.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
 			checkerIsBusy = false
-			if op := c.ruleChecker.Check(region); op != nil {
+			if op := c.ruleChecker.Check(region); op != nil && (replicaInWindow || op.IsUrgent()) {
 				return checkerIsBusy, []*operator.Operator{op}
 			}
 		}
@@ -65,13 +73,21 @@ func (c *CheckerController) CheckRegion(region *core.RegionInfo) (bool, []*opera
 		}
 		if opController.OperatorCount(operator.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
 			checkerIsBusy = false
-			if op := c.replicaChecker.Check(region); op != nil {
+			if op := c.replicaChecker.Check(region); op != nil && (replicaInWindow || op.IsUrgent()) {
 				return checkerIsBusy, []*operator.Operator{op}
 			}
 		}
 	}
 
-	if c.mergeChecker != nil && opController.OperatorCount(operator.OpMerge) < c.cluster.GetMergeScheduleLimit() {
+	if c.splitChecker != nil && opController.OperatorCount(operator.OpSplit) < c.cluster.GetSplitScheduleLimit() {
+		checkerIsBusy = false
+		if op := c.splitChecker.Check(region); op != nil {
+			return checkerIsBusy, []*operator.Operator{op}
+		}
+	}
+
+	if c.mergeChecker != nil && c.cluster.IsInScheduleWindows(checker.MergeCheckerName, now) &&
+		opController.OperatorCount(operator.OpMerge) < c.cluster.GetMergeScheduleLimit() {
 		checkerIsBusy = false
 		if ops := c.mergeChecker.Check(region); ops != nil {
 			// It makes sure that two operators can be added successfully altogether.
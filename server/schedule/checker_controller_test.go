@@ -0,0 +1,69 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/schedule/checker"
+)
+
+var _ = Suite(&testCheckerControllerSuite{})
+
+type testCheckerControllerSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *testCheckerControllerSuite) SetUpSuite(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *testCheckerControllerSuite) TearDownSuite(c *C) {
+	s.cancel()
+}
+
+func (s *testCheckerControllerSuite) TestRuleCheckerWindows(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.EnablePlacementRules = true
+	// The window is closed now.
+	now := time.Now()
+	window := now.Add(2*time.Hour).Format("15:04") + "-" + now.Add(3*time.Hour).Format("15:04")
+	opt.ScheduleWindows = map[string][]string{checker.ReplicaCheckerName: {window}}
+	tc := mockcluster.NewCluster(opt)
+	cc := NewCheckerController(s.ctx, tc, tc.RuleManager, NewOperatorController(s.ctx, tc, nil))
+	for id := uint64(1); id <= 4; id++ {
+		tc.AddLeaderStore(id, 1)
+	}
+
+	// Making up a missing replica is not postponed by the windows.
+	tc.AddLeaderRegion(1, 1, 2)
+	_, ops := cc.CheckRegion(tc.GetRegion(1))
+	c.Assert(ops, HasLen, 1)
+	c.Assert(ops[0].Desc(), Equals, "add-rule-peer")
+	c.Assert(ops[0].IsUrgent(), IsTrue)
+
+	// Removing an extra peer waits for the windows.
+	tc.AddLeaderRegion(2, 1, 2, 3, 4)
+	_, ops = cc.CheckRegion(tc.GetRegion(2))
+	c.Assert(ops, HasLen, 0)
+	opt.ScheduleWindows = nil
+	_, ops = cc.CheckRegion(tc.GetRegion(2))
+	c.Assert(ops, HasLen, 1)
+	c.Assert(ops[0].IsUrgent(), IsFalse)
+}
@@ -0,0 +1,275 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/pd/v4/pkg/slice"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+)
+
+// maxDiagnosticScoreRecords limits the score comparisons kept for one round.
+const maxDiagnosticScoreRecords = 64
+
+// Diagnostic status of a scheduler.
+const (
+	DiagnosticStatusNormal      = "normal"
+	DiagnosticStatusPaused      = "paused"
+	DiagnosticStatusOutOfWindow = "out-of-window"
+	DiagnosticStatusDisallow    = "disallowed"
+	DiagnosticStatusPending     = "pending"
+)
+
+// DiagnosableScheduler is a scheduler which records the decision trace of its
+// scheduling rounds.
+type DiagnosableScheduler interface {
+	Scheduler
+	GetDiagnosticRecorder() *DiagnosticRecorder
+}
+
+// FilterRecord records how many times a filter rejected a store.
+type FilterRecord struct {
+	StoreID uint64 `json:"store_id"`
+	// Action is either "source" or "target".
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+	Type   string `json:"type"`
+	Count  int    `json:"count"`
+}
+
+// ScoreRecord records a comparison of the scores of a source store and a
+// target store after the tolerant resource is applied.
+type ScoreRecord struct {
+	RegionID         uint64  `json:"region_id"`
+	SourceStoreID    uint64  `json:"source_store_id"`
+	TargetStoreID    uint64  `json:"target_store_id"`
+	SourceScore      float64 `json:"source_score"`
+	TargetScore      float64 `json:"target_score"`
+	TolerantResource int64   `json:"tolerant_resource"`
+	ShouldBalance    bool    `json:"should_balance"`
+}
+
+// DiagnosticResult is the decision trace of a scheduling round.
+type DiagnosticResult struct {
+	Name          string          `json:"name"`
+	Status        string          `json:"status"`
+	StartTime     time.Time       `json:"start_time"`
+	FinishTime    time.Time       `json:"finish_time"`
+	OperatorCount int             `json:"operator_count"`
+	SourceStores  []uint64        `json:"source_stores"`
+	Filters       []*FilterRecord `json:"filters"`
+	Scores        []*ScoreRecord  `json:"scores"`
+	Events        map[string]int  `json:"events"`
+}
+
+type filterRecordKey struct {
+	storeID uint64
+	action  string
+	scope   string
+	typ     string
+}
+
+// DiagnosticRecorder records the decision trace of a scheduler. It keeps the
+// trace of the last finished round only. A nil recorder records nothing.
+type DiagnosticRecorder struct {
+	sync.Mutex
+	name    string
+	current *DiagnosticResult
+	filters map[filterRecordKey]*FilterRecord
+	last    *DiagnosticResult
+}
+
+// NewDiagnosticRecorder creates a recorder for the scheduler.
+func NewDiagnosticRecorder(name string) *DiagnosticRecorder {
+	return &DiagnosticRecorder{name: name}
+}
+
+// Begin starts recording a new round.
+func (r *DiagnosticRecorder) Begin() {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.current = &DiagnosticResult{
+		Name:      r.name,
+		StartTime: time.Now(),
+		Events:    make(map[string]int),
+	}
+	r.filters = make(map[filterRecordKey]*FilterRecord)
+}
+
+// End finishes the current round with the operators it created.
+func (r *DiagnosticRecorder) End(ops []*operator.Operator) {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	if r.current == nil {
+		return
+	}
+	r.current.FinishTime = time.Now()
+	r.current.OperatorCount = len(ops)
+	r.current.Filters = make([]*FilterRecord, 0, len(r.filters))
+	for _, record := range r.filters {
+		r.current.Filters = append(r.current.Filters, record)
+	}
+	sort.Slice(r.current.Filters, func(i, j int) bool {
+		fi, fj := r.current.Filters[i], r.current.Filters[j]
+		if fi.StoreID != fj.StoreID {
+			return fi.StoreID < fj.StoreID
+		}
+		if fi.Action != fj.Action {
+			return fi.Action < fj.Action
+		}
+		return fi.Type < fj.Type
+	})
+	r.last, r.current, r.filters = r.current, nil, nil
+}
+
+// GetLastResult returns a copy of the trace of the last finished round. It
+// returns nil if no round has finished yet.
+func (r *DiagnosticRecorder) GetLastResult() *DiagnosticResult {
+	if r == nil {
+		return nil
+	}
+	r.Lock()
+	defer r.Unlock()
+	if r.last == nil {
+		return nil
+	}
+	result := *r.last
+	result.Events = make(map[string]int, len(r.last.Events))
+	for k, v := range r.last.Events {
+		result.Events[k] = v
+	}
+	return &result
+}
+
+// RecordSourceStores records the candidate source stores. The stores recorded
+// multiple times in a round, e.g. by the solvers of different resources, are
+// merged.
+func (r *DiagnosticRecorder) RecordSourceStores(stores []*core.StoreInfo) {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	if r.current == nil {
+		return
+	}
+	if r.current.SourceStores == nil {
+		r.current.SourceStores = make([]uint64, 0, len(stores))
+	}
+	for _, s := range stores {
+		if !slice.AnyOf(r.current.SourceStores, func(i int) bool { return r.current.SourceStores[i] == s.GetID() }) {
+			r.current.SourceStores = append(r.current.SourceStores, s.GetID())
+		}
+	}
+	sort.Slice(r.current.SourceStores, func(i, j int) bool { return r.current.SourceStores[i] < r.current.SourceStores[j] })
+}
+
+// RecordFilter records that the filter rejected the store.
+func (r *DiagnosticRecorder) RecordFilter(action string, f filter.Filter, store *core.StoreInfo) {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	if r.current == nil {
+		return
+	}
+	key := filterRecordKey{storeID: store.GetID(), action: action, scope: f.Scope(), typ: f.Type()}
+	record, ok := r.filters[key]
+	if !ok {
+		record = &FilterRecord{StoreID: key.storeID, Action: action, Scope: key.scope, Type: key.typ}
+		r.filters[key] = record
+	}
+	record.Count++
+}
+
+// RecordScore records a score comparison between two stores.
+func (r *DiagnosticRecorder) RecordScore(record *ScoreRecord) {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	if r.current == nil || len(r.current.Scores) >= maxDiagnosticScoreRecords {
+		return
+	}
+	r.current.Scores = append(r.current.Scores, record)
+}
+
+// RecordEvent records why the scheduler gave up a candidate, e.g. "no-region"
+// or "region-hot".
+func (r *DiagnosticRecorder) RecordEvent(event string) {
+	if r == nil {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	if r.current == nil {
+		return
+	}
+	r.current.Events[event]++
+}
+
+// WrapFilters wraps the filters so that the stores they reject are recorded.
+func (r *DiagnosticRecorder) WrapFilters(filters []filter.Filter) []filter.Filter {
+	if r == nil {
+		return filters
+	}
+	wrapped := make([]filter.Filter, 0, len(filters))
+	for _, f := range filters {
+		wrapped = append(wrapped, r.WrapFilter(f))
+	}
+	return wrapped
+}
+
+// WrapFilter wraps the filter so that the stores it rejects are recorded.
+func (r *DiagnosticRecorder) WrapFilter(f filter.Filter) filter.Filter {
+	if r == nil {
+		return f
+	}
+	return &diagnosticFilter{Filter: f, recorder: r}
+}
+
+type diagnosticFilter struct {
+	filter.Filter
+	recorder *DiagnosticRecorder
+}
+
+func (f *diagnosticFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
+	if f.Filter.Source(opt, store) {
+		f.recorder.RecordFilter("source", f.Filter, store)
+		return true
+	}
+	return false
+}
+
+func (f *diagnosticFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
+	if f.Filter.Target(opt, store) {
+		f.recorder.RecordFilter("target", f.Filter, store)
+		return true
+	}
+	return false
+}
@@ -118,11 +118,16 @@ func (f *excludedFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
 	return ok
 }
 
-type storeLimitFilter struct{ scope string }
+type storeLimitFilter struct {
+	scope  string
+	opKind string
+}
 
-// NewStoreLimitFilter creates a Filter that filters all stores those exceed the limit of a store.
-func NewStoreLimitFilter(scope string) Filter {
-	return &storeLimitFilter{scope: scope}
+// NewStoreLimitFilter creates a Filter that filters all stores those exceed the
+// limit of a store. The opKind is the name of the operator kind whose bucket
+// of the store limit is checked.
+func NewStoreLimitFilter(scope string, opKind string) Filter {
+	return &storeLimitFilter{scope: scope, opKind: opKind}
 }
 
 func (f *storeLimitFilter) Scope() string {
@@ -134,11 +139,11 @@ func (f *storeLimitFilter) Type() string {
 }
 
 func (f *storeLimitFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
-	return !store.IsAvailable()
+	return !store.IsAvailable(core.StoreLimitRemovePeer, f.opKind)
 }
 
 func (f *storeLimitFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
-	return !store.IsAvailable()
+	return !store.IsAvailable(core.StoreLimitAddPeer, f.opKind)
 }
 
 type stateFilter struct{ scope string }
@@ -356,6 +361,9 @@ type StoreStateFilter struct {
 	TransferLeader bool
 	// Set true if the schedule involves any move region operation.
 	MoveRegion bool
+	// OpKind is the name of the operator kind whose bucket of the store limit
+	// is checked when moving regions. The default bucket is used if empty.
+	OpKind string
 }
 
 // Scope returns the scheduler or the checker which the filter acts on.
@@ -379,7 +387,7 @@ func (f StoreStateFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
 		return true
 	}
 
-	if f.MoveRegion && f.filterMoveRegion(opt, store) {
+	if f.MoveRegion && f.filterMoveRegion(opt, store, core.StoreLimitRemovePeer) {
 		return true
 	}
 	return false
@@ -407,19 +415,19 @@ func (f StoreStateFilter) Target(opts opt.Options, store *core.StoreInfo) bool {
 			return true
 		}
 
-		if f.filterMoveRegion(opts, store) {
+		if f.filterMoveRegion(opts, store, core.StoreLimitAddPeer) {
 			return true
 		}
 	}
 	return false
 }
 
-func (f StoreStateFilter) filterMoveRegion(opt opt.Options, store *core.StoreInfo) bool {
+func (f StoreStateFilter) filterMoveRegion(opt opt.Options, store *core.StoreInfo, limitType core.StoreLimitType) bool {
 	if store.IsBusy() {
 		return true
 	}
 
-	if !store.IsAvailable() {
+	if !store.IsAvailable(limitType, f.OpKind) {
 		return true
 	}
 
@@ -568,3 +576,103 @@ func (f *ruleFitFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
 	newFit := f.fitter.FitRegion(region)
 	return placement.CompareRegionFit(f.oldFit, newFit) > 0
 }
+
+// isolationFilter is used to ensure that a new peer is placed in a different
+// location (at the isolation level) than all other peers of the rule.
+type isolationFilter struct {
+	scope       string
+	labels      []string
+	constraints [][]string
+}
+
+// NewIsolationFilter creates a filter that filters out stores that share the
+// same location with any of the given stores at the isolation level.
+func NewIsolationFilter(scope, isolationLevel string, locationLabels []string, regionStores []*core.StoreInfo) Filter {
+	isolationFilter := &isolationFilter{scope: scope}
+	// Get which idx this isolationLevel at according to locationLabels.
+	isolationLevelIdx := -1
+	for i, label := range locationLabels {
+		if label == isolationLevel {
+			isolationLevelIdx = i
+			break
+		}
+	}
+	if isolationLevelIdx == -1 {
+		return isolationFilter
+	}
+	isolationFilter.labels = locationLabels[:isolationLevelIdx+1]
+	for _, store := range regionStores {
+		var values []string
+		for _, label := range isolationFilter.labels {
+			values = append(values, store.GetLabelValue(label))
+		}
+		isolationFilter.constraints = append(isolationFilter.constraints, values)
+	}
+	return isolationFilter
+}
+
+func (f *isolationFilter) Scope() string {
+	return f.scope
+}
+
+func (f *isolationFilter) Type() string {
+	return "isolation-filter"
+}
+
+func (f *isolationFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
+	return false
+}
+
+func (f *isolationFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
+	for _, values := range f.constraints {
+		if f.sameLocation(store, values) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameLocation checks if the store has the same label values as the given
+// values on all labels under the isolation level.
+func (f *isolationFilter) sameLocation(store *core.StoreInfo, values []string) bool {
+	for i, label := range f.labels {
+		if store.GetLabelValue(label) != values[i] {
+			return false
+		}
+	}
+	return true
+}
+
+type leaderPreferenceFilter struct {
+	scope      string
+	prefs      [][]placement.LabelConstraint
+	sourceRank int
+}
+
+// NewLeaderPreferenceFilter creates a filter that filters out stores which are
+// less preferred to hold the leader of the region than the source store,
+// according to the leader preferences of placement rules.
+func NewLeaderPreferenceFilter(scope string, fitter RegionFitter, region *core.RegionInfo, source *core.StoreInfo) Filter {
+	prefs := fitter.FitRegion(region).GetLeaderPreferences()
+	return &leaderPreferenceFilter{
+		scope:      scope,
+		prefs:      prefs,
+		sourceRank: placement.LeaderPreferenceRank(source, prefs),
+	}
+}
+
+func (f *leaderPreferenceFilter) Scope() string {
+	return f.scope
+}
+
+func (f *leaderPreferenceFilter) Type() string {
+	return "leader-preference-filter"
+}
+
+func (f *leaderPreferenceFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
+	return false
+}
+
+func (f *leaderPreferenceFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
+	return placement.LeaderPreferenceRank(store, f.prefs) > f.sourceRank
+}
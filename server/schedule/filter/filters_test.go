@@ -80,3 +80,27 @@ func (s *testFiltersSuite) TestRuleFitFilter(c *C) {
 	c.Assert(filter.Target(tc, tc.GetStore(4)), IsTrue)
 	c.Assert(filter.Source(tc, tc.GetStore(4)), IsFalse)
 }
+
+func (s *testFiltersSuite) TestIsolationFilter(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	tc.AddLabelsStore(1, 1, map[string]string{"zone": "z1", "rack": "r1"})
+	tc.AddLabelsStore(2, 1, map[string]string{"zone": "z1", "rack": "r2"})
+	tc.AddLabelsStore(3, 1, map[string]string{"zone": "z2", "rack": "r1"})
+	tc.AddLabelsStore(4, 1, map[string]string{"zone": "z3", "rack": "r1"})
+	regionStores := []*core.StoreInfo{tc.GetStore(1), tc.GetStore(3)}
+	locationLabels := []string{"zone", "rack"}
+
+	filter := NewIsolationFilter("", "zone", locationLabels, regionStores)
+	c.Assert(filter.Target(tc, tc.GetStore(2)), IsTrue)
+	c.Assert(filter.Target(tc, tc.GetStore(4)), IsFalse)
+	c.Assert(filter.Source(tc, tc.GetStore(2)), IsFalse)
+
+	filter = NewIsolationFilter("", "rack", locationLabels, regionStores)
+	c.Assert(filter.Target(tc, tc.GetStore(2)), IsFalse)
+	c.Assert(filter.Target(tc, tc.GetStore(4)), IsFalse)
+
+	// Unknown isolation level filters nothing.
+	filter = NewIsolationFilter("", "host", locationLabels, regionStores)
+	c.Assert(filter.Target(tc, tc.GetStore(1)), IsFalse)
+}
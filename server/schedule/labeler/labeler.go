@@ -0,0 +1,169 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"go.uber.org/zap"
+)
+
+// RegionLabeler is utility to label regions by key ranges.
+type RegionLabeler struct {
+	storage *core.Storage
+	sync.RWMutex
+	labelRules map[string]*LabelRule
+	// sortedRules is sorted by rule ID, it is rebuilt when rules change.
+	sortedRules []*LabelRule
+}
+
+// NewRegionLabeler creates a Labeler instance and loads the rules from storage.
+func NewRegionLabeler(storage *core.Storage) (*RegionLabeler, error) {
+	l := &RegionLabeler{
+		storage:    storage,
+		labelRules: make(map[string]*LabelRule),
+	}
+	if err := l.loadRules(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *RegionLabeler) loadRules() error {
+	var toDelete []string
+	_, err := l.storage.LoadRegionLabelRules(func(v string) error {
+		var r LabelRule
+		if err := json.Unmarshal([]byte(v), &r); err != nil {
+			return err
+		}
+		if err := r.Adjust(); err != nil {
+			log.Error("invalid region label rule, delete it", zap.String("rule-id", r.ID), zap.Error(err))
+			toDelete = append(toDelete, r.ID)
+			return nil
+		}
+		l.labelRules[r.ID] = &r
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, id := range toDelete {
+		if err := l.storage.DeleteRegionLabelRule(id); err != nil {
+			return err
+		}
+	}
+	l.buildSortedRules()
+	return nil
+}
+
+func (l *RegionLabeler) buildSortedRules() {
+	rules := make([]*LabelRule, 0, len(l.labelRules))
+	for _, r := range l.labelRules {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	l.sortedRules = rules
+}
+
+// GetAllLabelRules returns all the rules, sorted by ID.
+func (l *RegionLabeler) GetAllLabelRules() []*LabelRule {
+	l.RLock()
+	defer l.RUnlock()
+	return append(l.sortedRules[:0:0], l.sortedRules...)
+}
+
+// GetLabelRule returns the Rule with the same ID.
+func (l *RegionLabeler) GetLabelRule(id string) *LabelRule {
+	l.RLock()
+	defer l.RUnlock()
+	return l.labelRules[id]
+}
+
+// SetLabelRule inserts or updates a LabelRule.
+func (l *RegionLabeler) SetLabelRule(rule *LabelRule) error {
+	if err := rule.Adjust(); err != nil {
+		return err
+	}
+	l.Lock()
+	defer l.Unlock()
+	if err := l.storage.SaveRegionLabelRule(rule.ID, rule); err != nil {
+		return err
+	}
+	l.labelRules[rule.ID] = rule
+	l.buildSortedRules()
+	log.Info("region label rule updated", zap.String("rule-id", rule.ID), zap.Reflect("labels", rule.Labels))
+	return nil
+}
+
+// DeleteLabelRule removes a LabelRule.
+func (l *RegionLabeler) DeleteLabelRule(id string) error {
+	l.Lock()
+	defer l.Unlock()
+	if _, ok := l.labelRules[id]; !ok {
+		return nil
+	}
+	if err := l.storage.DeleteRegionLabelRule(id); err != nil {
+		return err
+	}
+	delete(l.labelRules, id)
+	l.buildSortedRules()
+	log.Info("region label rule deleted", zap.String("rule-id", id))
+	return nil
+}
+
+// GetRegionLabel returns the label of the region for a key. If the region
+// overlaps with multiple rules that have the key, the rule with the smallest
+// ID wins.
+func (l *RegionLabeler) GetRegionLabel(region *core.RegionInfo, key string) string {
+	l.RLock()
+	defer l.RUnlock()
+	for _, r := range l.sortedRules {
+		if v, ok := r.Labels[key]; ok && r.overlaps(region.GetStartKey(), region.GetEndKey()) {
+			return v
+		}
+	}
+	return ""
+}
+
+// GetRegionLabels returns all labels of the region.
+func (l *RegionLabeler) GetRegionLabels(region *core.RegionInfo) map[string]string {
+	l.RLock()
+	defer l.RUnlock()
+	labels := make(map[string]string)
+	for _, r := range l.sortedRules {
+		if !r.overlaps(region.GetStartKey(), region.GetEndKey()) {
+			continue
+		}
+		for k, v := range r.Labels {
+			if _, ok := labels[k]; !ok {
+				labels[k] = v
+			}
+		}
+	}
+	return labels
+}
+
+// IsMergeDenied returns true if the region is labeled to deny merging.
+func (l *RegionLabeler) IsMergeDenied(region *core.RegionInfo) bool {
+	return l.GetRegionLabel(region, MergeLabel) == DenyValue
+}
+
+// IsScheduleDenied returns true if the region is labeled to deny scheduling.
+func (l *RegionLabeler) IsScheduleDenied(region *core.RegionInfo) bool {
+	return l.GetRegionLabel(region, ScheduleLabel) == DenyValue
+}
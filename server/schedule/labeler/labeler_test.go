@@ -0,0 +1,117 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"encoding/hex"
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+)
+
+func TestLabeler(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testLabelerSuite{})
+
+type testLabelerSuite struct {
+	store   *core.Storage
+	labeler *RegionLabeler
+}
+
+func (s *testLabelerSuite) SetUpTest(c *C) {
+	s.store = core.NewStorage(kv.NewMemoryKV())
+	var err error
+	s.labeler, err = NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+}
+
+func (s *testLabelerSuite) TestAdjustRule(c *C) {
+	rules := []LabelRule{
+		{ID: "", StartKeyHex: "", EndKeyHex: "", Labels: map[string]string{"k": "v"}},
+		{ID: "id", StartKeyHex: "", EndKeyHex: "", Labels: nil},
+		{ID: "id", StartKeyHex: "", EndKeyHex: "", Labels: map[string]string{"k": ""}},
+		{ID: "id", StartKeyHex: "XX", EndKeyHex: "", Labels: map[string]string{"k": "v"}},
+		{ID: "id", StartKeyHex: "", EndKeyHex: "XX", Labels: map[string]string{"k": "v"}},
+		{ID: "id", StartKeyHex: "2233", EndKeyHex: "1122", Labels: map[string]string{"k": "v"}},
+	}
+	for _, r := range rules {
+		c.Assert(s.labeler.SetLabelRule(&r), NotNil)
+	}
+}
+
+func (s *testLabelerSuite) TestSaveLoadRule(c *C) {
+	rules := []*LabelRule{
+		{ID: "r1", StartKeyHex: "1111", EndKeyHex: "2222", Labels: map[string]string{MergeLabel: DenyValue}},
+		{ID: "r2", StartKeyHex: "3333", EndKeyHex: "", Labels: map[string]string{ScheduleLabel: DenyValue}},
+	}
+	for _, r := range rules {
+		c.Assert(s.labeler.SetLabelRule(r), IsNil)
+	}
+
+	labeler, err := NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+	loaded := labeler.GetAllLabelRules()
+	c.Assert(loaded, HasLen, 2)
+	for i := range loaded {
+		c.Assert(loaded[i].ID, Equals, rules[i].ID)
+		c.Assert(loaded[i].Labels, DeepEquals, rules[i].Labels)
+	}
+
+	c.Assert(labeler.DeleteLabelRule("r1"), IsNil)
+	c.Assert(labeler.GetLabelRule("r1"), IsNil)
+	c.Assert(labeler.GetAllLabelRules(), HasLen, 1)
+	labeler, err = NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+	c.Assert(labeler.GetAllLabelRules(), HasLen, 1)
+}
+
+func (s *testLabelerSuite) TestGetRegionLabel(c *C) {
+	rules := []*LabelRule{
+		{ID: "r1", StartKeyHex: "1111", EndKeyHex: "2222", Labels: map[string]string{MergeLabel: DenyValue}},
+		{ID: "r2", StartKeyHex: "2222", EndKeyHex: "", Labels: map[string]string{ScheduleLabel: DenyValue, "k": "v2"}},
+		{ID: "r3", StartKeyHex: "", EndKeyHex: "3333", Labels: map[string]string{"k": "v3"}},
+	}
+	for _, r := range rules {
+		c.Assert(s.labeler.SetLabelRule(r), IsNil)
+	}
+
+	cases := []struct {
+		start, end string
+		merge      bool
+		schedule   bool
+		k          string
+	}{
+		{"", "1111", false, false, "v3"},
+		{"", "1112", true, false, "v3"},
+		{"2222", "3333", false, true, "v2"},
+		{"2221", "2222", true, false, "v3"},
+		{"4444", "", false, true, "v2"},
+	}
+	for _, t := range cases {
+		start, err := hex.DecodeString(t.start)
+		c.Assert(err, IsNil)
+		end, err := hex.DecodeString(t.end)
+		c.Assert(err, IsNil)
+		region := core.NewRegionInfo(&metapb.Region{StartKey: start, EndKey: end}, nil)
+		c.Assert(s.labeler.IsMergeDenied(region), Equals, t.merge)
+		c.Assert(s.labeler.IsScheduleDenied(region), Equals, t.schedule)
+		c.Assert(s.labeler.GetRegionLabel(region, "k"), Equals, t.k)
+		c.Assert(s.labeler.GetRegionLabels(region)["k"], Equals, t.k)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"bytes"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+// Well-known label keys and values that are honored by schedulers and checkers.
+const (
+	// MergeLabel controls whether the regions in the range can be merged.
+	MergeLabel = "merge"
+	// ScheduleLabel controls whether the regions in the range can be
+	// scheduled by balance schedulers and the scatterer.
+	ScheduleLabel = "schedule"
+	// DenyValue is the label value that disables the corresponding behavior.
+	DenyValue = "deny"
+)
+
+// LabelRule is the rule to attach labels to a key range.
+type LabelRule struct {
+	ID          string            `json:"id"`
+	StartKeyHex string            `json:"start_key"` // hex format start key, for marshal/unmarshal
+	EndKeyHex   string            `json:"end_key"`   // hex format end key, for marshal/unmarshal
+	Labels      map[string]string `json:"labels"`
+
+	startKey, endKey []byte // only set at runtime, no need to {,un}marshal or persist.
+}
+
+// Adjust checks the rule and decodes its keys.
+func (r *LabelRule) Adjust() error {
+	if r.ID == "" {
+		return errors.New("empty rule id")
+	}
+	if len(r.Labels) == 0 {
+		return errors.New("no label in rule")
+	}
+	for k, v := range r.Labels {
+		if k == "" || v == "" {
+			return errors.New("empty label key or value")
+		}
+	}
+	var err error
+	r.startKey, err = hex.DecodeString(r.StartKeyHex)
+	if err != nil {
+		return errors.Wrap(err, "start key is not hex format")
+	}
+	r.endKey, err = hex.DecodeString(r.EndKeyHex)
+	if err != nil {
+		return errors.Wrap(err, "end key is not hex format")
+	}
+	if len(r.endKey) > 0 && bytes.Compare(r.endKey, r.startKey) <= 0 {
+		return errors.New("endKey should be greater than startKey")
+	}
+	return nil
+}
+
+// overlaps returns true if the rule's key range overlaps with [start, end).
+// An empty end key means +inf.
+func (r *LabelRule) overlaps(start, end []byte) bool {
+	return (len(r.endKey) == 0 || bytes.Compare(start, r.endKey) < 0) &&
+		(len(end) == 0 || bytes.Compare(r.startKey, end) < 0)
+}
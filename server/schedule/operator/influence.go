@@ -36,7 +36,29 @@ type StoreInfluence struct {
 	RegionCount int64
 	LeaderSize  int64
 	LeaderCount int64
-	StepCost    int64
+	StepCost    map[core.StoreLimitType]int64
+}
+
+// GetStepCost returns the step cost of the store limit type.
+func (s *StoreInfluence) GetStepCost(limitType core.StoreLimitType) int64 {
+	return s.StepCost[limitType]
+}
+
+// addStepCost adds the cost of adding or removing a peer of the region.
+func (s *StoreInfluence) addStepCost(limitType core.StoreLimitType, regionSize int64) {
+	var cost int64
+	if regionSize > smallRegionThreshold {
+		cost = RegionInfluence
+	} else if regionSize > core.EmptyRegionApproximateSize {
+		cost = smallRegionInfluence
+	}
+	if cost == 0 {
+		return
+	}
+	if s.StepCost == nil {
+		s.StepCost = make(map[core.StoreLimitType]int64)
+	}
+	s.StepCost[limitType] += cost
 }
 
 // ResourceProperty returns delta size of leader/region by influence.
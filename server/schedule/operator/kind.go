@@ -33,6 +33,7 @@ const (
 	OpBalance                      // Initiated by balancers.
 	OpMerge                        // Initiated by merge checkers or merge schedulers.
 	OpRange                        // Initiated by range scheduler.
+	OpSplit                        // Initiated by split checkers.
 	opMax
 )
 
@@ -46,6 +47,7 @@ var flagToName = map[OpKind]string{
 	OpBalance:   "balance",
 	OpMerge:     "merge",
 	OpRange:     "range",
+	OpSplit:     "split",
 }
 
 var nameToFlag = map[string]OpKind{
@@ -58,6 +60,7 @@ var nameToFlag = map[string]OpKind{
 	"balance":    OpBalance,
 	"merge":      OpMerge,
 	"range":      OpRange,
+	"split":      OpSplit,
 }
 
 func (k OpKind) String() string {
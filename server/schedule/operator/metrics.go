@@ -24,8 +24,17 @@ var (
 			Help:      "Bucketed histogram of processing time (s) of finished operator step.",
 			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 16),
 		}, []string{"type"})
+
+	operatorStepTimeoutCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "operator_step_timeout_total",
+			Help:      "Counter of operator steps which run longer than the max step wait time.",
+		}, []string{"type"})
 )
 
 func init() {
 	prometheus.MustRegister(operatorStepDuration)
+	prometheus.MustRegister(operatorStepTimeoutCounter)
 }
@@ -58,7 +58,9 @@ type Operator struct {
 	currentStep int32
 	status      OpStatusTracker
 	stepTime    int64
+	stepsTime   []int64 // the finish time of each step.
 	level       core.PriorityLevel
+	urgent      bool
 	Counters    []prometheus.Counter
 }
 
@@ -75,6 +77,7 @@ func NewOperator(desc, brief string, regionID uint64, regionEpoch *metapb.Region
 		regionEpoch: regionEpoch,
 		kind:        kind,
 		steps:       steps,
+		stepsTime:   make([]int64, len(steps)),
 		status:      NewOpStatusTracker(),
 		level:       level,
 	}
@@ -229,6 +232,49 @@ func (o *Operator) Step(i int) OpStep {
 	return nil
 }
 
+// GetStepStartTime returns the time when the i-th step starts. It returns
+// zero time if the step has not started.
+func (o *Operator) GetStepStartTime(i int) time.Time {
+	if i < 0 || i >= len(o.steps) || i > int(atomic.LoadInt32(&o.currentStep)) || !o.HasStarted() {
+		return time.Time{}
+	}
+	if i == 0 {
+		return o.GetStartTime()
+	}
+	return o.GetStepFinishTime(i - 1)
+}
+
+// GetStepFinishTime returns the time when the i-th step finishes. It returns
+// zero time if the step has not finished.
+func (o *Operator) GetStepFinishTime(i int) time.Time {
+	if i < 0 || i >= len(o.steps) {
+		return time.Time{}
+	}
+	if t := atomic.LoadInt64(&o.stepsTime[i]); t != 0 {
+		return time.Unix(0, t)
+	}
+	return time.Time{}
+}
+
+// CheckStepTimeout checks if the current step runs longer than the timeout,
+// and update the status. A non-positive timeout means no limit.
+func (o *Operator) CheckStepTimeout(timeout time.Duration) bool {
+	if timeout <= 0 || o.Status() != STARTED || o.CheckSuccess() {
+		return false
+	}
+	stepTime := time.Unix(0, atomic.LoadInt64(&o.stepTime))
+	if time.Since(stepTime) <= timeout {
+		return false
+	}
+	if !o.status.To(TIMEOUT) {
+		return false
+	}
+	if step := o.Step(int(atomic.LoadInt32(&o.currentStep))); step != nil {
+		operatorStepTimeoutCounter.WithLabelValues(reflect.TypeOf(step).Name()).Inc()
+	}
+	return true
+}
+
 // Check checks if current step is finished, returns next step to take action.
 // If operator is at an end status, check returns nil.
 // It's safe to be called by multiple goroutine concurrently.
@@ -241,10 +287,12 @@ func (o *Operator) Check(region *core.RegionInfo) OpStep {
 	defer func() { _ = o.CheckTimeout() }()
 	for step := atomic.LoadInt32(&o.currentStep); int(step) < len(o.steps); step++ {
 		if o.steps[int(step)].IsFinish(region) {
+			now := time.Now()
 			operatorStepDuration.WithLabelValues(reflect.TypeOf(o.steps[int(step)]).Name()).
-				Observe(time.Since(time.Unix(0, atomic.LoadInt64(&o.stepTime))).Seconds())
+				Observe(now.Sub(time.Unix(0, atomic.LoadInt64(&o.stepTime))).Seconds())
+			atomic.StoreInt64(&o.stepsTime[step], now.UnixNano())
 			atomic.StoreInt32(&o.currentStep, step+1)
-			atomic.StoreInt64(&o.stepTime, time.Now().UnixNano())
+			atomic.StoreInt64(&o.stepTime, now.UnixNano())
 		} else {
 			return o.steps[int(step)]
 		}
@@ -278,6 +326,17 @@ func (o *Operator) GetPriorityLevel() core.PriorityLevel {
 	return o.level
 }
 
+// SetUrgent marks the operator as urgent, such as repairing a down peer or a
+// missing replica, which is not postponed by the schedule windows.
+func (o *Operator) SetUrgent() {
+	o.urgent = true
+}
+
+// IsUrgent returns if the operator is urgent.
+func (o *Operator) IsUrgent() bool {
+	return o.urgent
+}
+
 // UnfinishedInfluence calculates the store difference which unfinished operator steps make.
 func (o *Operator) UnfinishedInfluence(opInfluence OpInfluence, region *core.RegionInfo) {
 	for step := atomic.LoadInt32(&o.currentStep); int(step) < len(o.steps); step++ {
@@ -155,7 +155,7 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 		LeaderCount: 0,
 		RegionSize:  50,
 		RegionCount: 1,
-		StepCost:    1000,
+		StepCost:    map[core.StoreLimitType]int64{core.StoreLimitAddPeer: 1000},
 	})
 
 	TransferLeader{FromStore: 1, ToStore: 2}.Influence(opInfluence, region)
@@ -164,14 +164,13 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 		LeaderCount: -1,
 		RegionSize:  0,
 		RegionCount: 0,
-		StepCost:    0,
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  50,
 		LeaderCount: 1,
 		RegionSize:  50,
 		RegionCount: 1,
-		StepCost:    1000,
+		StepCost:    map[core.StoreLimitType]int64{core.StoreLimitAddPeer: 1000},
 	})
 
 	RemovePeer{FromStore: 1}.Influence(opInfluence, region)
@@ -180,14 +179,14 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 		LeaderCount: -1,
 		RegionSize:  -50,
 		RegionCount: -1,
-		StepCost:    0,
+		StepCost:    map[core.StoreLimitType]int64{core.StoreLimitRemovePeer: 1000},
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  50,
 		LeaderCount: 1,
 		RegionSize:  50,
 		RegionCount: 1,
-		StepCost:    1000,
+		StepCost:    map[core.StoreLimitType]int64{core.StoreLimitAddPeer: 1000},
 	})
 
 	MergeRegion{IsPassive: false}.Influence(opInfluence, region)
@@ -196,14 +195,14 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 		LeaderCount: -1,
 		RegionSize:  -50,
 		RegionCount: -1,
-		StepCost:    0,
+		StepCost:    map[core.StoreLimitType]int64{core.StoreLimitRemovePeer: 1000},
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  50,
 		LeaderCount: 1,
 		RegionSize:  50,
 		RegionCount: 1,
-		StepCost:    1000,
+		StepCost:    map[core.StoreLimitType]int64{core.StoreLimitAddPeer: 1000},
 	})
 
 	MergeRegion{IsPassive: true}.Influence(opInfluence, region)
@@ -212,14 +211,14 @@ func (s *testOperatorSuite) TestInfluence(c *C) {
 		LeaderCount: -2,
 		RegionSize:  -50,
 		RegionCount: -2,
-		StepCost:    0,
+		StepCost:    map[core.StoreLimitType]int64{core.StoreLimitRemovePeer: 1000},
 	})
 	c.Assert(*storeOpInfluence[2], DeepEquals, StoreInfluence{
 		LeaderSize:  50,
 		LeaderCount: 1,
 		RegionSize:  50,
 		RegionCount: 0,
-		StepCost:    1000,
+		StepCost:    map[core.StoreLimitType]int64{core.StoreLimitAddPeer: 1000},
 	})
 }
 
@@ -267,6 +266,44 @@ func (s *testOperatorSuite) TestCheckSuccess(c *C) {
 	}
 }
 
+func (s *testOperatorSuite) TestStepTime(c *C) {
+	steps := []OpStep{
+		AddPeer{ToStore: 1, PeerID: 1},
+		TransferLeader{FromStore: 2, ToStore: 1},
+		RemovePeer{FromStore: 2},
+	}
+	op := s.newTestOperator(1, OpLeader|OpRegion, steps...)
+	c.Assert(op.GetStepStartTime(0).IsZero(), IsTrue)
+	c.Assert(op.Start(), IsTrue)
+	c.Assert(op.GetStepStartTime(0), Equals, op.GetStartTime())
+	c.Assert(op.GetStepFinishTime(0).IsZero(), IsTrue)
+	c.Assert(op.GetStepStartTime(1).IsZero(), IsTrue)
+
+	region := s.newTestRegion(1, 2, [2]uint64{1, 1}, [2]uint64{2, 2})
+	c.Assert(op.Check(region), Equals, steps[1])
+	c.Assert(op.GetStepFinishTime(0).IsZero(), IsFalse)
+	c.Assert(op.GetStepStartTime(1), Equals, op.GetStepFinishTime(0))
+	c.Assert(op.GetStepFinishTime(1).IsZero(), IsTrue)
+	c.Assert(op.GetStepStartTime(2).IsZero(), IsTrue)
+}
+
+func (s *testOperatorSuite) TestCheckStepTimeout(c *C) {
+	steps := []OpStep{
+		AddLearner{ToStore: 1, PeerID: 1},
+		RemovePeer{FromStore: 2},
+	}
+	op := s.newTestOperator(1, OpRegion, steps...)
+	c.Assert(op.CheckStepTimeout(time.Minute), IsFalse)
+	c.Assert(op.Start(), IsTrue)
+	c.Assert(op.CheckStepTimeout(0), IsFalse)
+	c.Assert(op.CheckStepTimeout(time.Minute), IsFalse)
+	op.stepTime = time.Now().Add(-2 * time.Minute).UnixNano()
+	c.Assert(op.CheckStepTimeout(0), IsFalse)
+	c.Assert(op.Status(), Equals, STARTED)
+	c.Assert(op.CheckStepTimeout(time.Minute), IsTrue)
+	c.Assert(op.Status(), Equals, TIMEOUT)
+}
+
 func (s *testOperatorSuite) TestCheckTimeout(c *C) {
 	{
 		steps := []OpStep{
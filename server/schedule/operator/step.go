@@ -107,11 +107,7 @@ func (ap AddPeer) Influence(opInfluence OpInfluence, region *core.RegionInfo) {
 	regionSize := region.GetApproximateSize()
 	to.RegionSize += regionSize
 	to.RegionCount++
-	if regionSize > smallRegionThreshold {
-		to.StepCost += RegionInfluence
-	} else if regionSize <= smallRegionThreshold && regionSize > core.EmptyRegionApproximateSize {
-		to.StepCost += smallRegionInfluence
-	}
+	to.addStepCost(core.StoreLimitAddPeer, regionSize)
 }
 
 // AddLearner is an OpStep that adds a region learner peer.
@@ -150,11 +146,7 @@ func (al AddLearner) Influence(opInfluence OpInfluence, region *core.RegionInfo)
 	regionSize := region.GetApproximateSize()
 	to.RegionSize += regionSize
 	to.RegionCount++
-	if regionSize > smallRegionThreshold {
-		to.StepCost += RegionInfluence
-	} else if regionSize <= smallRegionThreshold && regionSize > core.EmptyRegionApproximateSize {
-		to.StepCost += smallRegionInfluence
-	}
+	to.addStepCost(core.StoreLimitAddPeer, regionSize)
 }
 
 // PromoteLearner is an OpStep that promotes a region learner peer to normal voter.
@@ -211,8 +203,10 @@ func (rp RemovePeer) IsFinish(region *core.RegionInfo) bool {
 func (rp RemovePeer) Influence(opInfluence OpInfluence, region *core.RegionInfo) {
 	from := opInfluence.GetStoreInfluence(rp.FromStore)
 
-	from.RegionSize -= region.GetApproximateSize()
+	regionSize := region.GetApproximateSize()
+	from.RegionSize -= regionSize
 	from.RegionCount--
+	from.addStepCost(core.StoreLimitRemovePeer, regionSize)
 }
 
 // MergeRegion is an OpStep that merge two regions.
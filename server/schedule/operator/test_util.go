@@ -14,6 +14,7 @@
 package operator
 
 import (
+	"sync/atomic"
 	"time"
 )
 
@@ -22,3 +23,9 @@ import (
 func SetOperatorStatusReachTime(op *Operator, st OpStatus, t time.Time) {
 	op.status.setTime(st, t)
 }
+
+// SetOperatorStepTime sets the start time of the current step of the operator.
+// NOTE: Should only use in test.
+func SetOperatorStepTime(op *Operator, t time.Time) {
+	atomic.StoreInt64(&op.stepTime, t.UnixNano())
+}
@@ -17,8 +17,10 @@ import (
 	"container/heap"
 	"container/list"
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,8 +31,10 @@ import (
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/pkg/cache"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -61,7 +65,10 @@ type OperatorController struct {
 	histories       *list.List
 	counts          map[operator.OpKind]uint64
 	opRecords       *OperatorRecords
-	storesLimit     map[uint64]*StoreLimit
+	historyStorage  *OperatorHistoryStorage
+	historyRecords  chan *OperatorRecord
+	storesLimit     map[uint64]map[StoreLimitKey]*StoreLimit
+	addLearnerDurs  *addLearnerDurations
 	wop             WaitingOperator
 	wopStatus       *WaitingOperatorStatus
 	opNotifierQueue operatorQueue
@@ -77,13 +84,23 @@ func NewOperatorController(ctx context.Context, cluster opt.Cluster, hbStreams o
 		histories:       list.New(),
 		counts:          make(map[operator.OpKind]uint64),
 		opRecords:       NewOperatorRecords(ctx),
-		storesLimit:     make(map[uint64]*StoreLimit),
+		storesLimit:     make(map[uint64]map[StoreLimitKey]*StoreLimit),
+		addLearnerDurs:  newAddLearnerDurations(),
 		wop:             NewRandBuckets(),
 		wopStatus:       NewWaitingOperatorStatus(),
 		opNotifierQueue: make(operatorQueue, 0),
 	}
 }
 
+// SetHistoryStorage sets the storage to persist the ended operators, and
+// starts to persist them in background. It should be called before the
+// controller starts to work.
+func (oc *OperatorController) SetHistoryStorage(storage *OperatorHistoryStorage) {
+	oc.historyStorage = storage
+	oc.historyRecords = make(chan *OperatorRecord, maxPendingOperatorRecords)
+	go oc.persistHistoryLoop(storage, oc.historyRecords)
+}
+
 // Ctx returns a context which will be canceled once RaftCluster is stopped.
 // For now, it is only used to control the lifetime of TTL cache in schedulers.
 func (oc *OperatorController) Ctx() context.Context {
@@ -109,6 +126,17 @@ func (oc *OperatorController) Dispatch(region *core.RegionInfo, source string) {
 		// The operator status should be STARTED.
 		// Check will call CheckSuccess and CheckTimeout.
 		step := op.Check(region)
+		if step != nil && op.CheckStepTimeout(oc.cluster.GetMaxOperatorStepWaitTime()) {
+			log.Warn("operator step timeout",
+				zap.Uint64("region-id", op.RegionID()),
+				zap.Stringer("step", step),
+				zap.Reflect("operator", op))
+			operatorCounter.WithLabelValues(op.Desc(), "step-timeout").Inc()
+			// The learner is stuck in receiving or applying the snapshot.
+			if addLearner, ok := step.(operator.AddLearner); ok {
+				oc.addLearnerDurs.observe(addLearner.ToStore, oc.cluster.GetMaxOperatorStepWaitTime())
+			}
+		}
 
 		switch op.Status() {
 		case operator.STARTED:
@@ -118,6 +146,7 @@ func (oc *OperatorController) Dispatch(region *core.RegionInfo, source string) {
 			}
 			oc.SendScheduleCommand(region, step, source)
 		case operator.SUCCESS:
+			oc.observeAddLearnerDurations(op)
 			oc.pushHistory(op)
 			if oc.RemoveOperator(op) {
 				oc.PromoteWaitingOperator()
@@ -146,6 +175,27 @@ func (oc *OperatorController) Dispatch(region *core.RegionInfo, source string) {
 	}
 }
 
+// observeAddLearnerDurations records the durations of the AddLearner steps of
+// the finished operator.
+func (oc *OperatorController) observeAddLearnerDurations(op *operator.Operator) {
+	for i := 0; i < op.Len(); i++ {
+		addLearner, ok := op.Step(i).(operator.AddLearner)
+		if !ok {
+			continue
+		}
+		start, finish := op.GetStepStartTime(i), op.GetStepFinishTime(i)
+		if !start.IsZero() && !finish.IsZero() {
+			oc.addLearnerDurs.observe(addLearner.ToStore, finish.Sub(start))
+		}
+	}
+}
+
+// GetAddLearnerDuration returns the moving average of the durations of adding
+// learners to the store. It returns 0 if no learner is added to the store.
+func (oc *OperatorController) GetAddLearnerDuration(storeID uint64) time.Duration {
+	return oc.addLearnerDurs.get(storeID)
+}
+
 func (oc *OperatorController) checkStaleOperator(op *operator.Operator, region *core.RegionInfo) bool {
 	// When the "source" is heartbeat, the region may have a newer
 	// confver than the region that the operator holds. In this case,
@@ -443,12 +493,18 @@ func (oc *OperatorController) addOperatorLocked(op *operator.Operator) bool {
 	operatorWaitDuration.WithLabelValues(op.Desc()).Observe(op.ElapsedTime().Seconds())
 	opInfluence := NewTotalOpInfluence([]*operator.Operator{op}, oc.cluster)
 	for storeID := range opInfluence.StoresInfluence {
-		stepCost := opInfluence.GetStoreInfluence(storeID).StepCost
-		if stepCost == 0 {
-			continue
+		for _, limitType := range core.StoreLimitTypes {
+			stepCost := opInfluence.GetStoreInfluence(storeID).GetStepCost(limitType)
+			if stepCost == 0 {
+				continue
+			}
+			key, limit := oc.pickStoreLimit(storeID, limitType, op.Kind())
+			if limit == nil {
+				continue
+			}
+			storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), key.String(), "take").Set(float64(stepCost) / float64(operator.RegionInfluence))
+			limit.Take(stepCost)
 		}
-		storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), "take").Set(float64(stepCost) / float64(operator.RegionInfluence))
-		oc.storesLimit[storeID].Take(stepCost)
 	}
 	oc.updateCounts(oc.operators)
 
@@ -545,6 +601,51 @@ func (oc *OperatorController) buryOperator(op *operator.Operator) {
 	}
 
 	oc.opRecords.Put(op)
+	if st != operator.EXPIRED {
+		oc.persistHistory(op)
+	}
+}
+
+// persistHistory queues the record of the operator to be persisted. The
+// record is dropped if the queue is full, so that burying operators is never
+// blocked by the storage.
+func (oc *OperatorController) persistHistory(op *operator.Operator) {
+	if oc.historyRecords == nil || !oc.cluster.IsOperatorHistoryEnabled() {
+		return
+	}
+	select {
+	case oc.historyRecords <- NewOperatorRecord(op):
+	default:
+		operatorCounter.WithLabelValues(op.Desc(), "drop-history").Inc()
+	}
+}
+
+// persistHistoryLoop persists the queued records, the records queued at the
+// same time in a batch.
+func (oc *OperatorController) persistHistoryLoop(storage *OperatorHistoryStorage, records chan *OperatorRecord) {
+	batch := make([]*OperatorRecord, 0, kv.MaxBatchOps)
+	for {
+		select {
+		case <-oc.ctx.Done():
+			return
+		case record := <-records:
+			batch = append(batch[:0], record)
+		}
+	drain:
+		for len(batch) < kv.MaxBatchOps {
+			select {
+			case record := <-records:
+				batch = append(batch, record)
+			default:
+				break drain
+			}
+		}
+		if err := storage.Put(batch...); err != nil {
+			log.Warn("failed to persist operator history",
+				zap.Int("records", len(batch)),
+				zap.Error(err))
+		}
+	}
 }
 
 // GetOperatorStatus gets the operator and its status with the specify id.
@@ -714,13 +815,26 @@ func (oc *OperatorController) pushHistory(op *operator.Operator) {
 // PruneHistory prunes a part of operators' history.
 func (oc *OperatorController) PruneHistory() {
 	oc.Lock()
-	defer oc.Unlock()
 	p := oc.histories.Back()
 	for p != nil && time.Since(p.Value.(operator.OpHistory).FinishTime) > historyKeepTime {
 		prev := p.Prev()
 		oc.histories.Remove(p)
 		p = prev
 	}
+	oc.Unlock()
+	if oc.historyStorage != nil {
+		if err := oc.historyStorage.Prune(time.Now(), oc.cluster.GetOperatorHistoryRetention(), oc.cluster.GetMaxOperatorHistoryRecords()); err != nil {
+			log.Warn("failed to prune operator history", zap.Error(err))
+		}
+	}
+}
+
+// GetHistoryRecords gets the ended operators in storage which match the filter.
+func (oc *OperatorController) GetHistoryRecords(f *OperatorHistoryFilter) ([]*OperatorRecord, error) {
+	if oc.historyStorage == nil {
+		return nil, errors.New("operator history storage is not set")
+	}
+	return oc.historyStorage.Load(f)
 }
 
 // GetHistory gets operators' history.
@@ -816,9 +930,22 @@ func NewOperatorWithStatus(op *operator.Operator) *OperatorWithStatus {
 	}
 }
 
-// MarshalJSON returns the status of operator as a JSON string
+// MarshalJSON returns the status of operator and the time of its steps as a
+// JSON string
 func (o *OperatorWithStatus) MarshalJSON() ([]byte, error) {
-	return []byte(`"` + fmt.Sprintf("status: %s, operator: %s", o.Status.String(), o.Op.String()) + `"`), nil
+	stepsTime := make([]string, 0, o.Op.Len())
+	for i := 0; i < o.Op.Len(); i++ {
+		start, finish := o.Op.GetStepStartTime(i), o.Op.GetStepFinishTime(i)
+		switch {
+		case start.IsZero():
+			stepsTime = append(stepsTime, fmt.Sprintf("step %d: not started", i))
+		case finish.IsZero():
+			stepsTime = append(stepsTime, fmt.Sprintf("step %d: started at %s", i, start))
+		default:
+			stepsTime = append(stepsTime, fmt.Sprintf("step %d: started at %s, finished at %s, takes %s", i, start, finish, finish.Sub(start)))
+		}
+	}
+	return json.Marshal(fmt.Sprintf("status: %s, operator: %s, steps time: [%s]", o.Status.String(), o.Op.String(), strings.Join(stepsTime, "; ")))
 }
 
 // OperatorRecords remains the operator and its status for a while.
@@ -853,86 +980,148 @@ func (o *OperatorRecords) Put(op *operator.Operator) {
 
 // exceedStoreLimit returns true if the store exceeds the cost limit after adding the operator. Otherwise, returns false.
 func (oc *OperatorController) exceedStoreLimit(ops ...*operator.Operator) bool {
-	opInfluence := NewTotalOpInfluence(ops, oc.cluster)
-	for storeID := range opInfluence.StoresInfluence {
-		stepCost := opInfluence.GetStoreInfluence(storeID).StepCost
-		if stepCost == 0 {
-			continue
-		}
-
-		available := oc.getOrCreateStoreLimit(storeID).bucket.Available()
-		storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), "available").Set(float64(available) / float64(operator.RegionInfluence))
-		if available < stepCost {
-			return true
+	// The operators may share the same bucket, so the costs are accumulated
+	// before being compared with the available tokens.
+	costs := make(map[*StoreLimit]int64)
+	for _, op := range ops {
+		opInfluence := NewTotalOpInfluence([]*operator.Operator{op}, oc.cluster)
+		for storeID := range opInfluence.StoresInfluence {
+			for _, limitType := range core.StoreLimitTypes {
+				stepCost := opInfluence.GetStoreInfluence(storeID).GetStepCost(limitType)
+				if stepCost == 0 {
+					continue
+				}
+				key, limit := oc.pickStoreLimit(storeID, limitType, op.Kind())
+				if limit == nil {
+					continue
+				}
+				costs[limit] += stepCost
+				available := limit.Available()
+				storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), key.String(), "available").Set(float64(available) / float64(operator.RegionInfluence))
+				if available < costs[limit] {
+					return true
+				}
+			}
 		}
 	}
 	return false
 }
 
-// SetAllStoresLimit is used to set limit of all stores.
-func (oc *OperatorController) SetAllStoresLimit(rate float64, mode StoreLimitMode) {
+// SetAllStoresLimit is used to set limit of all stores. It sets the default
+// buckets of all types if no key is given.
+func (oc *OperatorController) SetAllStoresLimit(rate float64, mode StoreLimitMode, keys ...StoreLimitKey) {
 	oc.Lock()
 	defer oc.Unlock()
 	stores := oc.cluster.GetStores()
 	for _, s := range stores {
-		oc.newStoreLimit(s.GetID(), rate, mode)
+		oc.newStoreLimit(s.GetID(), rate, mode, keys...)
 	}
 }
 
-// SetAllStoresLimitAuto updates the store limit in StoreLimitAuto mode
+// SetAllStoresLimitAuto updates the default buckets of the store limit in
+// StoreLimitAuto mode
 func (oc *OperatorController) SetAllStoresLimitAuto(rate float64) {
 	oc.Lock()
 	defer oc.Unlock()
 	stores := oc.cluster.GetStores()
 	for _, s := range stores {
-		sid := s.GetID()
-		if old, ok := oc.storesLimit[sid]; ok {
-			if old.Mode() == StoreLimitManual {
-				continue
-			}
+		oc.setStoreLimitAuto(s.GetID(), rate)
+	}
+}
+
+// SetStoreLimitAuto updates the default buckets of the limit of a store in
+// StoreLimitAuto mode. The rates of the buckets are changed without refilling
+// them.
+func (oc *OperatorController) SetStoreLimitAuto(storeID uint64, rate float64) {
+	oc.Lock()
+	defer oc.Unlock()
+	limits := oc.getOrCreateStoreLimits(storeID)
+	for _, limitType := range core.StoreLimitTypes {
+		key := StoreLimitKey{Type: limitType}
+		old, ok := limits[key]
+		switch {
+		case !ok:
+			limits[key] = NewStoreLimit(rate, StoreLimitAuto)
+		case old.Mode() == StoreLimitAuto:
+			limits[key] = old.withRate(rate)
 		}
-		oc.storesLimit[sid] = NewStoreLimit(rate, StoreLimitAuto)
 	}
 }
 
-// SetStoreLimit is used to set the limit of a store.
-func (oc *OperatorController) SetStoreLimit(storeID uint64, rate float64, mode StoreLimitMode) {
+func (oc *OperatorController) setStoreLimitAuto(storeID uint64, rate float64) {
+	limits := oc.getOrCreateStoreLimits(storeID)
+	for _, limitType := range core.StoreLimitTypes {
+		key := StoreLimitKey{Type: limitType}
+		if old, ok := limits[key]; ok && old.Mode() == StoreLimitManual {
+			continue
+		}
+		limits[key] = NewStoreLimit(rate, StoreLimitAuto)
+	}
+}
+
+// SetStoreLimit is used to set the limit of a store. It sets the default
+// buckets of all types if no key is given.
+func (oc *OperatorController) SetStoreLimit(storeID uint64, rate float64, mode StoreLimitMode, keys ...StoreLimitKey) {
 	oc.Lock()
 	defer oc.Unlock()
-	oc.newStoreLimit(storeID, rate, mode)
+	oc.newStoreLimit(storeID, rate, mode, keys...)
 }
 
 // newStoreLimit is used to create the limit of a store.
-func (oc *OperatorController) newStoreLimit(storeID uint64, rate float64, mode StoreLimitMode) {
-	oc.storesLimit[storeID] = NewStoreLimit(rate, mode)
-}
-
-// getOrCreateStoreLimit is used to get or create the limit of a store.
-func (oc *OperatorController) getOrCreateStoreLimit(storeID uint64) *StoreLimit {
-	if oc.storesLimit[storeID] == nil {
-		rate := oc.cluster.GetStoreBalanceRate() / StoreBalanceBaseTime
-		oc.newStoreLimit(storeID, rate, StoreLimitAuto)
-		oc.cluster.AttachAvailableFunc(storeID, func() bool {
-			oc.RLock()
-			defer oc.RUnlock()
-			return oc.storesLimit[storeID].Available() >= operator.RegionInfluence
-		})
+func (oc *OperatorController) newStoreLimit(storeID uint64, rate float64, mode StoreLimitMode, keys ...StoreLimitKey) {
+	limits := oc.getOrCreateStoreLimits(storeID)
+	if len(keys) == 0 {
+		for _, limitType := range core.StoreLimitTypes {
+			keys = append(keys, StoreLimitKey{Type: limitType})
+		}
+	}
+	for _, key := range keys {
+		limits[key] = NewStoreLimit(rate, mode)
+	}
+}
+
+// getOrCreateStoreLimits is used to get or create the limits of a store.
+func (oc *OperatorController) getOrCreateStoreLimits(storeID uint64) map[StoreLimitKey]*StoreLimit {
+	if limits, ok := oc.storesLimit[storeID]; ok {
+		return limits
+	}
+	rate := oc.cluster.GetStoreBalanceRate() / StoreBalanceBaseTime
+	limits := make(map[StoreLimitKey]*StoreLimit)
+	for _, limitType := range core.StoreLimitTypes {
+		limits[StoreLimitKey{Type: limitType}] = NewStoreLimit(rate, StoreLimitAuto)
 	}
-	return oc.storesLimit[storeID]
+	oc.storesLimit[storeID] = limits
+	oc.cluster.AttachAvailableFunc(storeID, func(limitType core.StoreLimitType, opKind string) bool {
+		kind := operator.OpRegion | storeLimitKindNames[opKind]
+		oc.RLock()
+		defer oc.RUnlock()
+		_, limit := pickStoreLimit(oc.storesLimit[storeID], limitType, kind)
+		return limit == nil || limit.Available() >= operator.RegionInfluence
+	})
+	return limits
+}
+
+// pickStoreLimit returns the bucket of the store used by the operator of the kind.
+func (oc *OperatorController) pickStoreLimit(storeID uint64, limitType core.StoreLimitType, kind operator.OpKind) (StoreLimitKey, *StoreLimit) {
+	return pickStoreLimit(oc.getOrCreateStoreLimits(storeID), limitType, kind)
 }
 
 // GetAllStoresLimit is used to get limit of all stores.
-func (oc *OperatorController) GetAllStoresLimit() map[uint64]*StoreLimit {
+func (oc *OperatorController) GetAllStoresLimit() map[uint64]map[StoreLimitKey]*StoreLimit {
 	oc.RLock()
 	defer oc.RUnlock()
-	limits := make(map[uint64]*StoreLimit)
-	for storeID, limit := range oc.storesLimit {
+	ret := make(map[uint64]map[StoreLimitKey]*StoreLimit)
+	for storeID, limits := range oc.storesLimit {
 		store := oc.cluster.GetStore(storeID)
-		if !store.IsTombstone() {
-			limits[storeID] = limit
+		if store.IsTombstone() {
+			continue
+		}
+		ret[storeID] = make(map[StoreLimitKey]*StoreLimit, len(limits))
+		for key, limit := range limits {
+			ret[storeID][key] = limit
 		}
 	}
-	return limits
+	return ret
 }
 
 // GetLeaderSchedulePolicy is to get leader schedule policy.
@@ -949,4 +1138,5 @@ func (oc *OperatorController) RemoveStoreLimit(storeID uint64) {
 	defer oc.Unlock()
 	oc.cluster.AttachAvailableFunc(storeID, nil)
 	delete(oc.storesLimit, storeID)
+	oc.addLearnerDurs.remove(storeID)
 }
@@ -28,7 +28,9 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
 	"github.com/pingcap/pd/v4/pkg/mock/mockhbstream"
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 )
@@ -129,6 +131,147 @@ func (t *testOperatorControllerSuite) TestOperatorStatus(c *C) {
 	c.Assert(oc.GetOperatorStatus(2).Status, Equals, pdpb.OperatorStatus_SUCCESS)
 }
 
+func (t *testOperatorControllerSuite) TestOperatorStepTimeout(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.MaxOperatorStepWaitTime = time.Minute
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(t.ctx, tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLeaderStore(1, 1)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderRegion(1, 1)
+	steps := []operator.OpStep{
+		operator.AddLearner{ToStore: 2, PeerID: 2},
+		operator.PromoteLearner{ToStore: 2, PeerID: 2},
+	}
+	op := operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion, steps...)
+	c.Assert(op.Start(), IsTrue)
+	oc.SetOperator(op)
+	oc.Dispatch(tc.GetRegion(1), "test")
+	c.Assert(oc.GetOperatorStatus(1).Status, Equals, pdpb.OperatorStatus_RUNNING)
+
+	// the learner is stuck in applying snapshot.
+	operator.SetOperatorStepTime(op, time.Now().Add(-2*time.Minute))
+	oc.Dispatch(tc.GetRegion(1), "test")
+	c.Assert(oc.GetOperatorStatus(1).Status, Equals, pdpb.OperatorStatus_TIMEOUT)
+	c.Assert(oc.GetOperator(1), IsNil)
+}
+
+func (t *testOperatorControllerSuite) TestOperatorHistory(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.EnableOperatorHistory = true
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(t.ctx, tc, mockhbstream.NewHeartbeatStream())
+	storage := NewOperatorHistoryStorage(kv.NewMemoryKV())
+	oc.SetHistoryStorage(storage)
+	tc.AddLeaderStore(1, 2)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	tc.AddLeaderRegion(1, 1, 2)
+	tc.AddLeaderRegion(2, 1, 2)
+	start := time.Now()
+	op1 := operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	op2 := operator.NewOperator("test", "test", 2, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 3, PeerID: 4})
+	c.Assert(op1.Start(), IsTrue)
+	oc.SetOperator(op1)
+	c.Assert(op2.Start(), IsTrue)
+	oc.SetOperator(op2)
+	operator.SetOperatorStatusReachTime(op1, operator.STARTED, time.Now().Add(-10*time.Minute))
+	oc.Dispatch(tc.GetRegion(1), "test")
+	ApplyOperator(tc, op2)
+	oc.Dispatch(tc.GetRegion(2), "test")
+
+	// the records are persisted in background.
+	var records []*OperatorRecord
+	var err error
+	testutil.WaitUntil(c, func(c *C) bool {
+		records, err = oc.GetHistoryRecords(&OperatorHistoryFilter{Start: start})
+		c.Assert(err, IsNil)
+		return len(records) == 2
+	})
+	c.Assert(records[0].RegionID, Equals, uint64(1))
+	c.Assert(records[0].Status, Equals, "Timeout")
+	c.Assert(records[0].Stores, DeepEquals, []uint64{1, 2})
+	c.Assert(records[1].RegionID, Equals, uint64(2))
+	c.Assert(records[1].Status, Equals, "Success")
+
+	records, err = oc.GetHistoryRecords(&OperatorHistoryFilter{StoreID: 3})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RegionID, Equals, uint64(2))
+	records, err = oc.GetHistoryRecords(&OperatorHistoryFilter{Kind: operator.OpLeader})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RegionID, Equals, uint64(1))
+	records, err = oc.GetHistoryRecords(&OperatorHistoryFilter{RegionID: 2, End: start})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 0)
+	records, err = oc.GetHistoryRecords(&OperatorHistoryFilter{Limit: 1})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].RegionID, Equals, uint64(1))
+
+	// the records survive when the controller is recreated.
+	oc = NewOperatorController(t.ctx, tc, mockhbstream.NewHeartbeatStream())
+	oc.SetHistoryStorage(storage)
+	records, err = oc.GetHistoryRecords(&OperatorHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 2)
+
+	// the records are not persisted if disabled.
+	opt.EnableOperatorHistory = false
+	op3 := operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+	c.Assert(op3.Start(), IsTrue)
+	oc.SetOperator(op3)
+	c.Assert(oc.RemoveOperator(op3), IsTrue)
+	c.Assert(oc.historyRecords, HasLen, 0)
+
+	c.Assert(storage.Prune(time.Now().Add(2*time.Hour), time.Hour, 100), IsNil)
+	records, err = oc.GetHistoryRecords(&OperatorHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 0)
+}
+
+func (t *testOperatorControllerSuite) TestOperatorHistoryStorage(c *C) {
+	storage := NewOperatorHistoryStorage(kv.NewMemoryKV())
+	now := time.Now()
+	count := maxOperatorHistoryPruneCount + operatorHistoryPageSize
+	records := make([]*OperatorRecord, 0, count)
+	for i := 0; i < count; i++ {
+		records = append(records, &OperatorRecord{RegionID: uint64(i), FinishTime: now})
+	}
+	c.Assert(storage.Put(records...), IsNil)
+
+	// the records are loaded in pages.
+	loaded, err := storage.Load(&OperatorHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(loaded, HasLen, count)
+	loaded, err = storage.Load(&OperatorHistoryFilter{Limit: operatorHistoryPageSize + 1})
+	c.Assert(err, IsNil)
+	c.Assert(loaded, HasLen, operatorHistoryPageSize+1)
+
+	// a prune removes a limited number of expired records.
+	c.Assert(storage.Prune(now.Add(2*time.Hour), time.Hour, uint64(count)), IsNil)
+	loaded, err = storage.Load(&OperatorHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(loaded, HasLen, operatorHistoryPageSize)
+	c.Assert(storage.Prune(now.Add(2*time.Hour), time.Hour, uint64(count)), IsNil)
+	loaded, err = storage.Load(&OperatorHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(loaded, HasLen, 0)
+
+	// the oldest records beyond the max number are removed.
+	records = records[:0]
+	for i := 0; i < 10; i++ {
+		records = append(records, &OperatorRecord{RegionID: uint64(i), FinishTime: now.Add(time.Duration(i) * time.Second)})
+	}
+	c.Assert(storage.Put(records...), IsNil)
+	c.Assert(storage.Prune(now, time.Hour, 4), IsNil)
+	loaded, err = storage.Load(&OperatorHistoryFilter{})
+	c.Assert(err, IsNil)
+	c.Assert(loaded, HasLen, 4)
+	c.Assert(loaded[0].RegionID, Equals, uint64(6))
+}
+
 func (t *testOperatorControllerSuite) TestCheckAddUnexpectedStatus(c *C) {
 	c.Assert(failpoint.Disable("github.com/pingcap/pd/v4/server/schedule/unexpectedOperator"), IsNil)
 	opt := mockoption.NewScheduleOptions()
@@ -339,6 +482,78 @@ func (t *testOperatorControllerSuite) TestStoreLimit(c *C) {
 	op = operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 1})
 	c.Assert(oc.AddOperator(op), IsFalse)
 	c.Assert(oc.RemoveOperator(op), IsFalse)
+
+	// Removing peers uses the other bucket.
+	for i := uint64(1); i <= 5; i++ {
+		op = operator.NewOperator("test", "test", i, &metapb.RegionEpoch{}, operator.OpRegion, operator.RemovePeer{FromStore: 2})
+		c.Assert(oc.AddOperator(op), IsTrue)
+		checkRemoveOperatorSuccess(c, oc, op)
+	}
+	op = operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion, operator.RemovePeer{FromStore: 2})
+	c.Assert(oc.AddOperator(op), IsFalse)
+	c.Assert(oc.RemoveOperator(op), IsFalse)
+
+	// Repairing replicas and the operators of the admin are not limited by the
+	// default remove-peer bucket.
+	for _, kind := range []operator.OpKind{operator.OpReplica, operator.OpAdmin} {
+		op = operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion|kind, operator.RemovePeer{FromStore: 2})
+		c.Assert(oc.AddOperator(op), IsTrue)
+		checkRemoveOperatorSuccess(c, oc, op)
+	}
+	c.Assert(tc.GetStore(2).IsAvailable(core.StoreLimitRemovePeer, operator.OpReplica.String()), IsTrue)
+	c.Assert(tc.GetStore(2).IsAvailable(core.StoreLimitRemovePeer, operator.OpBalance.String()), IsFalse)
+
+	// The operators of the kind with its own bucket are not limited by the
+	// default bucket.
+	oc.SetStoreLimit(2, 1, StoreLimitManual, StoreLimitKey{Type: core.StoreLimitAddPeer, Kind: operator.OpReplica})
+	for i := uint64(1); i <= 5; i++ {
+		op = operator.NewOperator("test", "test", i, &metapb.RegionEpoch{}, operator.OpRegion|operator.OpReplica, operator.AddPeer{ToStore: 2, PeerID: i})
+		c.Assert(oc.AddOperator(op), IsTrue)
+		checkRemoveOperatorSuccess(c, oc, op)
+	}
+	op = operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion|operator.OpReplica, operator.AddPeer{ToStore: 2, PeerID: 1})
+	c.Assert(oc.AddOperator(op), IsFalse)
+	c.Assert(oc.RemoveOperator(op), IsFalse)
+	c.Assert(tc.GetStore(2).IsAvailable(core.StoreLimitAddPeer, operator.OpReplica.String()), IsFalse)
+	c.Assert(tc.GetStore(2).IsAvailable(core.StoreLimitAddPeer, operator.OpBalance.String()), IsFalse)
+}
+
+func (t *testOperatorControllerSuite) TestPickStoreLimit(c *C) {
+	limits := map[StoreLimitKey]*StoreLimit{
+		{Type: core.StoreLimitAddPeer}:                           NewStoreLimit(1, StoreLimitAuto),
+		{Type: core.StoreLimitAddPeer, Kind: operator.OpReplica}: NewStoreLimit(2, StoreLimitManual),
+		{Type: core.StoreLimitAddPeer, Kind: operator.OpBalance}: NewStoreLimit(3, StoreLimitManual),
+	}
+	key, _ := pickStoreLimit(limits, core.StoreLimitAddPeer, operator.OpRegion|operator.OpReplica)
+	c.Assert(key.Kind, Equals, operator.OpReplica)
+	key, _ = pickStoreLimit(limits, core.StoreLimitAddPeer, operator.OpRegion|operator.OpReplica|operator.OpBalance)
+	c.Assert(key.Kind, Equals, operator.OpReplica)
+	key, _ = pickStoreLimit(limits, core.StoreLimitAddPeer, operator.OpRegion|operator.OpHotRegion)
+	c.Assert(key.Kind, Equals, operator.OpKind(0))
+	key, limit := pickStoreLimit(limits, core.StoreLimitRemovePeer, operator.OpRegion|operator.OpBalance)
+	c.Assert(key.String(), Equals, "remove-peer")
+	c.Assert(limit, IsNil)
+	c.Assert(StoreLimitKey{Type: core.StoreLimitAddPeer, Kind: operator.OpBalance}.String(), Equals, "balance/add-peer")
+
+	_, err := ParseStoreLimitKind("replica")
+	c.Assert(err, IsNil)
+	_, err = ParseStoreLimitKind("leader")
+	c.Assert(err, NotNil)
+}
+
+func (t *testOperatorControllerSuite) TestAddLearnerDurations(c *C) {
+	d := newAddLearnerDurations()
+	now := time.Now()
+	c.Assert(d.getAt(1, now), Equals, time.Duration(0))
+	d.observeAt(1, time.Minute, now)
+	c.Assert(d.getAt(1, now), Equals, time.Minute)
+	// The average decays by half in a half-life without new observations.
+	now = now.Add(addLearnerDurationHalfLife)
+	c.Assert(d.getAt(1, now), Equals, 30*time.Second)
+	d.observeAt(1, 40*time.Second, now)
+	c.Assert(d.getAt(1, now), Equals, 32*time.Second)
+	d.remove(1)
+	c.Assert(d.getAt(1, now), Equals, time.Duration(0))
 }
 
 // #1652
@@ -0,0 +1,328 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	operatorHistoryPath = "operator_history"
+	// maxOperatorHistoryQueryLimit is the max number of records returned by a
+	// query.
+	maxOperatorHistoryQueryLimit = 10000
+	// operatorHistoryPageSize is the number of records loaded from storage at
+	// a time in a query.
+	operatorHistoryPageSize = 1000
+	// maxOperatorHistoryPruneCount is the max number of expired records
+	// removed by a prune, the rest are left to the next prune.
+	maxOperatorHistoryPruneCount = 1024
+	// maxPendingOperatorRecords is the max number of the records waiting to
+	// be persisted, the records beyond it are dropped.
+	maxPendingOperatorRecords = 4096
+)
+
+// OperatorRecord is the persistent record of an ended operator.
+type OperatorRecord struct {
+	RegionID   uint64    `json:"region_id"`
+	Desc       string    `json:"desc"`
+	Kind       string    `json:"kind"`
+	Status     string    `json:"status"`
+	Steps      []string  `json:"steps"`
+	Stores     []uint64  `json:"stores"`
+	CreateTime time.Time `json:"create_time"`
+	StartTime  time.Time `json:"start_time"`
+	FinishTime time.Time `json:"finish_time"`
+}
+
+// NewOperatorRecord creates a record of the ended operator.
+func NewOperatorRecord(op *operator.Operator) *OperatorRecord {
+	steps := make([]string, 0, op.Len())
+	for i := 0; i < op.Len(); i++ {
+		steps = append(steps, op.Step(i).String())
+	}
+	return &OperatorRecord{
+		RegionID:   op.RegionID(),
+		Desc:       op.Desc(),
+		Kind:       op.Kind().String(),
+		Status:     operator.OpStatusToString(op.Status()),
+		Steps:      steps,
+		Stores:     involvedStores(op),
+		CreateTime: op.GetCreateTime(),
+		StartTime:  op.GetStartTime(),
+		FinishTime: op.GetReachTimeOf(op.Status()),
+	}
+}
+
+// involvedStores returns the stores that the operator moves leader or peers
+// from or to.
+func involvedStores(op *operator.Operator) []uint64 {
+	var stores []uint64
+	seen := make(map[uint64]struct{})
+	add := func(ids ...uint64) {
+		for _, id := range ids {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				stores = append(stores, id)
+			}
+		}
+	}
+	for i := 0; i < op.Len(); i++ {
+		switch s := op.Step(i).(type) {
+		case operator.TransferLeader:
+			add(s.FromStore, s.ToStore)
+		case operator.AddPeer:
+			add(s.ToStore)
+		case operator.AddLightPeer:
+			add(s.ToStore)
+		case operator.AddLearner:
+			add(s.ToStore)
+		case operator.AddLightLearner:
+			add(s.ToStore)
+		case operator.PromoteLearner:
+			add(s.ToStore)
+		case operator.RemovePeer:
+			add(s.FromStore)
+		}
+	}
+	return stores
+}
+
+// OperatorHistoryFilter is used to filter the operator records. Zero value
+// fields are not used for filtering.
+type OperatorHistoryFilter struct {
+	Start, End time.Time
+	Kind       operator.OpKind
+	StoreID    uint64
+	RegionID   uint64
+	// Limit is the max number of records returned, which can not exceed
+	// maxOperatorHistoryQueryLimit. The next page starts from the finish time
+	// of the last record.
+	Limit int
+}
+
+func (f *OperatorHistoryFilter) limit() int {
+	if f.Limit <= 0 || f.Limit > maxOperatorHistoryQueryLimit {
+		return maxOperatorHistoryQueryLimit
+	}
+	return f.Limit
+}
+
+func (f *OperatorHistoryFilter) match(record *OperatorRecord) bool {
+	if f.RegionID != 0 && record.RegionID != f.RegionID {
+		return false
+	}
+	if f.Kind != 0 {
+		kind, err := operator.ParseOperatorKind(record.Kind)
+		if err != nil || kind&f.Kind != f.Kind {
+			return false
+		}
+	}
+	if f.StoreID != 0 {
+		for _, id := range record.Stores {
+			if id == f.StoreID {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// OperatorHistoryStorage keeps the ended operators in storage, indexed by
+// their finish time, so they survive the leader change.
+type OperatorHistoryStorage struct {
+	kv kv.Base
+
+	mu sync.Mutex
+	// count is the number of the records in storage, which is -1 before
+	// they are counted in the first prune. It may be larger than the actual
+	// number if a record is overwritten, which only makes a prune remove
+	// a few more records.
+	count int
+}
+
+// NewOperatorHistoryStorage creates a OperatorHistoryStorage.
+func NewOperatorHistoryStorage(kv kv.Base) *OperatorHistoryStorage {
+	return &OperatorHistoryStorage{
+		kv:    kv,
+		count: -1,
+	}
+}
+
+// The key is ordered by finish time first, region ID is used to avoid conflict.
+func operatorHistoryKey(finishTime time.Time, regionID uint64) string {
+	return path.Join(operatorHistoryPath, fmt.Sprintf("%020d", finishTime.UnixNano()), fmt.Sprintf("%020d", regionID))
+}
+
+func operatorHistoryTimeKey(t time.Time) string {
+	return path.Join(operatorHistoryPath, fmt.Sprintf("%020d", t.UnixNano()))
+}
+
+// Put saves the records of the ended operators, a batch of records in a
+// transaction.
+func (s *OperatorHistoryStorage) Put(records ...*OperatorRecord) error {
+	ops := make([]kv.Op, 0, kv.MaxBatchOps)
+	for _, record := range records {
+		value, err := json.Marshal(record)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ops = append(ops, kv.OpSave(operatorHistoryKey(record.FinishTime, record.RegionID), string(value)))
+		if len(ops) == kv.MaxBatchOps {
+			if err := s.batch(ops, len(ops)); err != nil {
+				return err
+			}
+			ops = ops[:0]
+		}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	return s.batch(ops, len(ops))
+}
+
+// batch applies the ops, and adds delta to the count of the records.
+func (s *OperatorHistoryStorage) batch(ops []kv.Op, delta int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.kv.Batch(ops...); err != nil {
+		return err
+	}
+	if s.count >= 0 {
+		s.count += delta
+		if s.count < 0 {
+			s.count = 0
+		}
+	}
+	return nil
+}
+
+// Load returns at most f.Limit records which finish in [f.Start, f.End) and
+// match the filter, in the order of finish time.
+func (s *OperatorHistoryStorage) Load(f *OperatorHistoryFilter) ([]*OperatorRecord, error) {
+	startKey := operatorHistoryPath + "/"
+	if !f.Start.IsZero() {
+		startKey = operatorHistoryTimeKey(f.Start)
+	}
+	endKey := operatorHistoryPath + "0" // '0' is the next byte of '/'.
+	if !f.End.IsZero() {
+		endKey = operatorHistoryTimeKey(f.End)
+	}
+	limit := f.limit()
+	records := make([]*OperatorRecord, 0)
+	for {
+		keys, values, err := s.kv.LoadRange(startKey, endKey, operatorHistoryPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range values {
+			record := &OperatorRecord{}
+			if err := json.Unmarshal([]byte(values[i]), record); err != nil {
+				log.Warn("failed to unmarshal operator record", zap.String("key", keys[i]), zap.Error(err))
+				continue
+			}
+			if f.match(record) {
+				records = append(records, record)
+				if len(records) == limit {
+					return records, nil
+				}
+			}
+		}
+		if len(keys) < operatorHistoryPageSize {
+			return records, nil
+		}
+		startKey = keys[len(keys)-1] + "\x00"
+	}
+}
+
+// Prune removes the records older than the retention, and the oldest ones
+// beyond maxRecords. It removes at most maxOperatorHistoryPruneCount expired
+// records at a time, so a large backlog is removed in several prunes.
+func (s *OperatorHistoryStorage) Prune(now time.Time, retention time.Duration, maxRecords uint64) error {
+	if err := s.countRecords(); err != nil {
+		return err
+	}
+	endKey := operatorHistoryTimeKey(now.Add(-retention))
+	keys, _, err := s.kv.LoadRange(operatorHistoryPath+"/", endKey, maxOperatorHistoryPruneCount)
+	if err != nil {
+		return err
+	}
+	if err := s.remove(keys); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	excess := s.count - int(maxRecords)
+	s.mu.Unlock()
+	if excess <= 0 {
+		return nil
+	}
+	keys, _, err = s.kv.LoadRange(operatorHistoryPath+"/", operatorHistoryPath+"0", excess)
+	if err != nil {
+		return err
+	}
+	return s.remove(keys)
+}
+
+// countRecords counts the records in storage if they are not counted yet.
+func (s *OperatorHistoryStorage) countRecords() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count >= 0 {
+		return nil
+	}
+	var count int
+	startKey := operatorHistoryPath + "/"
+	for {
+		keys, _, err := s.kv.LoadRange(startKey, operatorHistoryPath+"0", operatorHistoryPageSize)
+		if err != nil {
+			return err
+		}
+		count += len(keys)
+		if len(keys) < operatorHistoryPageSize {
+			s.count = count
+			return nil
+		}
+		startKey = keys[len(keys)-1] + "\x00"
+	}
+}
+
+func (s *OperatorHistoryStorage) remove(keys []string) error {
+	for len(keys) > 0 {
+		n := len(keys)
+		if n > kv.MaxBatchOps {
+			n = kv.MaxBatchOps
+		}
+		ops := make([]kv.Op, 0, n)
+		for _, key := range keys[:n] {
+			ops = append(ops, kv.OpRemove(key))
+		}
+		if err := s.batch(ops, -n); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
@@ -59,3 +59,15 @@ func IsRegionReplicated(cluster Cluster, region *core.RegionInfo) bool {
 func ReplicatedRegion(cluster Cluster) func(*core.RegionInfo) bool {
 	return func(region *core.RegionInfo) bool { return IsRegionReplicated(cluster, region) }
 }
+
+// IsRegionScheduleAllowed checks if a region can be scheduled by balance
+// schedulers. It requires the region is not labeled to deny scheduling.
+func IsRegionScheduleAllowed(cluster Cluster, region *core.RegionInfo) bool {
+	return !cluster.GetRegionLabeler().IsScheduleDenied(region)
+}
+
+// ScheduleAllowedRegion returns a function that checks if a region can be
+// scheduled by balance schedulers.
+func ScheduleAllowedRegion(cluster Cluster) func(*core.RegionInfo) bool {
+	return func(region *core.RegionInfo) bool { return IsRegionScheduleAllowed(cluster, region) }
+}
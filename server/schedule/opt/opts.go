@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/pd/v4/server/statistics"
 )
@@ -29,6 +30,7 @@ type Options interface {
 	GetRegionScheduleLimit() uint64
 	GetReplicaScheduleLimit() uint64
 	GetMergeScheduleLimit() uint64
+	GetSplitScheduleLimit() uint64
 	GetHotRegionScheduleLimit() uint64
 
 	// store limit
@@ -37,6 +39,7 @@ type Options interface {
 	GetMaxSnapshotCount() uint64
 	GetMaxPendingPeerCount() uint64
 	GetMaxStoreDownTime() time.Duration
+	GetMaxOperatorStepWaitTime() time.Duration
 	GetMaxMergeRegionSize() uint64
 	GetMaxMergeRegionKeys() uint64
 	GetSplitMergeInterval() time.Duration
@@ -60,7 +63,13 @@ type Options interface {
 	IsRemoveExtraReplicaEnabled() bool
 	IsLocationReplacementEnabled() bool
 	IsDebugMetricsEnabled() bool
+	IsOperatorHistoryEnabled() bool
+	GetOperatorHistoryRetention() time.Duration
+	GetMaxOperatorHistoryRecords() uint64
 	GetLeaderSchedulePolicy() core.SchedulePolicy
+	GetRegionWeightMode() core.RegionWeightMode
+	GetStoreClassWeights() map[string]float64
+	IsInScheduleWindows(name string, t time.Time) bool
 	GetKeyType() core.KeyType
 
 	RemoveScheduler(name string) error
@@ -87,6 +96,8 @@ type Cluster interface {
 
 	AllocID() (uint64, error)
 	FitRegion(*core.RegionInfo) *placement.RegionFit
+	ObserveRuleFit(*core.RegionInfo, *placement.RegionFit)
+	GetRegionLabeler() *labeler.RegionLabeler
 }
 
 // HeartbeatStream is an interface.
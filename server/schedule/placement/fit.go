@@ -55,6 +55,20 @@ func (f *RegionFit) GetRuleFit(peerID uint64) *RuleFit {
 	return nil
 }
 
+// GetLeaderPreferences returns the leader preferences of the first rule that
+// can hold the leader and has leader preferences.
+func (f *RegionFit) GetLeaderPreferences() [][]LabelConstraint {
+	for _, rf := range f.RuleFits {
+		if rf.Rule.Role != Leader && rf.Rule.Role != Voter {
+			continue
+		}
+		if prefs := rf.Rule.GetLeaderPreferences(); len(prefs) > 0 {
+			return prefs
+		}
+	}
+	return nil
+}
+
 // CompareRegionFit determines the superiority of 2 fits.
 // It returns 1 when the first fit result is better.
 func CompareRegionFit(a, b *RegionFit) int {
@@ -89,7 +103,13 @@ type RuleFit struct {
 
 // IsSatisfied returns if the rule is properly satisfied.
 func (f *RuleFit) IsSatisfied() bool {
-	return len(f.Peers) == f.Rule.Count && len(f.PeersWithDifferentRole) == 0
+	return len(f.Peers) == f.Rule.Count && len(f.PeersWithDifferentRole) == 0 && f.IsIsolationSatisfied()
+}
+
+// IsIsolationSatisfied returns if the peers are isolated at the level required
+// by the rule's IsolationLevel.
+func (f *RuleFit) IsIsolationSatisfied() bool {
+	return len(f.Peers) <= 1 || f.IsolationLevel >= f.Rule.requiredIsolationLevel()
 }
 
 func compareRuleFit(a, b *RuleFit) int {
@@ -111,8 +131,13 @@ func compareRuleFit(a, b *RuleFit) int {
 	}
 }
 
+// StoreSet represents the store container that a region is fitted against.
+type StoreSet interface {
+	GetStore(id uint64) *core.StoreInfo
+}
+
 // FitRegion tries to fit peers of a region to the rules.
-func FitRegion(stores core.StoreSetInformer, region *core.RegionInfo, rules []*Rule) *RegionFit {
+func FitRegion(stores StoreSet, region *core.RegionInfo, rules []*Rule) *RegionFit {
 	peers := prepareFitPeers(stores, region)
 
 	var regionFit RegionFit
@@ -193,7 +218,7 @@ func (p *fitPeer) matchRoleLoose(role PeerRoleType) bool {
 	return role != Learner || p.IsLearner
 }
 
-func prepareFitPeers(stores core.StoreSetInformer, region *core.RegionInfo) []*fitPeer {
+func prepareFitPeers(stores StoreSet, region *core.RegionInfo) []*fitPeer {
 	var peers []*fitPeer
 	for _, p := range region.GetPeers() {
 		peers = append(peers, &fitPeer{
@@ -162,3 +162,47 @@ func (s *testFitSuite) TestFitByLocation(c *C) {
 		c.Assert(ruleFit.IsolationLevel, Equals, cc.expectedIsolationLevel)
 	}
 }
+
+func (s *testFitSuite) TestIsolationLevel(c *C) {
+	stores := make(map[uint64]*core.StoreInfo)
+	for _, id := range []uint64{1111, 1112, 1211, 2111, 3111} {
+		labels := map[string]string{
+			"zone": fmt.Sprintf("zone%d", id/1000),
+			"rack": fmt.Sprintf("rack%d", id/100%10),
+			"host": fmt.Sprintf("host%d", id/10%10),
+		}
+		stores[id] = core.NewStoreInfoWithLabel(id, 0, labels)
+	}
+
+	cases := []struct {
+		peerStoreID    []uint64
+		isolationLevel string
+		satisfied      bool
+	}{
+		{[]uint64{1111, 2111, 3111}, "", true},
+		{[]uint64{1111, 2111, 3111}, "zone", true},
+		{[]uint64{1111, 1211, 3111}, "zone", false},
+		{[]uint64{1111, 1211, 3111}, "rack", true},
+		{[]uint64{1111, 1112, 3111}, "rack", false},
+		{[]uint64{1111, 1112, 3111}, "host", false},
+		{[]uint64{1111}, "zone", true},
+	}
+	for _, cc := range cases {
+		var peers []*fitPeer
+		for _, id := range cc.peerStoreID {
+			peers = append(peers, &fitPeer{
+				Peer:  &metapb.Peer{Id: id, StoreId: id},
+				store: stores[id],
+			})
+		}
+		rule := &Rule{
+			Count:          len(cc.peerStoreID),
+			Role:           Voter,
+			LocationLabels: []string{"zone", "rack", "host"},
+			IsolationLevel: cc.isolationLevel,
+		}
+		ruleFit := fitRule(peers, rule)
+		c.Assert(ruleFit.IsIsolationSatisfied(), Equals, cc.satisfied)
+		c.Assert(ruleFit.IsSatisfied(), Equals, cc.satisfied)
+	}
+}
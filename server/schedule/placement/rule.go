@@ -13,7 +13,13 @@
 
 package placement
 
-import "sort"
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pkg/errors"
+)
 
 // PeerRoleType is the expected peer type of the placement rule.
 type PeerRoleType string
@@ -49,6 +55,16 @@ type Rule struct {
 	Count            int               `json:"count"`                       // expected count of the peers
 	LabelConstraints []LabelConstraint `json:"label_constraints,omitempty"` // used to select stores to place peers
 	LocationLabels   []string          `json:"location_labels,omitempty"`   // used to make peers isolated physically
+	IsolationLevel   string            `json:"isolation_level,omitempty"`   // used to isolate replicas explicitly and forcibly
+	// LeaderPreferences is an ordered list of label constraints. Leaders are
+	// preferred to be placed on stores that match a former item.
+	LeaderPreferences [][]LabelConstraint `json:"leader_preferences,omitempty"`
+
+	group *RuleGroup // only set at runtime, no need to {,un}marshal or persist.
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%s/%s", r.GroupID, r.ID)
 }
 
 // Key returns (groupID, ID) as the global unique key of a rule.
@@ -61,9 +77,109 @@ func (r Rule) StoreKey() string {
 	return r.StartKeyHex + "-" + r.EndKeyHex
 }
 
-// Rules are ordered by (GroupID, Index, ID).
+// requiredIsolationLevel returns the minimum isolation level (see
+// isolationLevel) that peers of the rule must reach. It returns 0 if the rule
+// has no isolation requirement.
+func (r *Rule) requiredIsolationLevel() int {
+	for i, label := range r.LocationLabels {
+		if label == r.IsolationLevel {
+			return len(r.LocationLabels) - i
+		}
+	}
+	return 0
+}
+
+// GetLeaderPreferences returns the leader preferences of the rule. If the rule
+// does not specify any, it falls back to the preferences of its group.
+func (r *Rule) GetLeaderPreferences() [][]LabelConstraint {
+	if len(r.LeaderPreferences) > 0 {
+		return r.LeaderPreferences
+	}
+	if r.group != nil {
+		return r.group.LeaderPreferences
+	}
+	return nil
+}
+
+func (r *Rule) groupIndex() int {
+	if r.group != nil {
+		return r.group.Index
+	}
+	return 0
+}
+
+func (r *Rule) groupOverride() bool {
+	return r.group != nil && r.group.Override
+}
+
+// RuleGroup defines properties of a rule group. Groups are applied in the
+// order of (Index, ID). When a group's Override is true, all groups that are
+// applied before it are disabled.
+type RuleGroup struct {
+	ID       string `json:"id"`
+	Index    int    `json:"index,omitempty"`
+	Override bool   `json:"override,omitempty"`
+	// LeaderPreferences is used by rules of the group that have no leader
+	// preferences of their own.
+	LeaderPreferences [][]LabelConstraint `json:"leader_preferences,omitempty"`
+}
+
+func (g *RuleGroup) isDefault() bool {
+	return g.Index == 0 && !g.Override && len(g.LeaderPreferences) == 0
+}
+
+// LeaderPreferenceRank returns the index of the first item of the preferences
+// that the store matches. A smaller rank means the store is more preferred. It
+// returns len(prefs) if the store matches none of them.
+func LeaderPreferenceRank(store *core.StoreInfo, prefs [][]LabelConstraint) int {
+	for i, constraints := range prefs {
+		if MatchLabelConstraints(store, constraints) {
+			return i
+		}
+	}
+	return len(prefs)
+}
+
+func validateLeaderPreferences(prefs [][]LabelConstraint) error {
+	for _, constraints := range prefs {
+		for _, c := range constraints {
+			if !validateOp(c.Op) {
+				return errors.Errorf("invalid op %s in leader preferences", c.Op)
+			}
+		}
+	}
+	return nil
+}
+
+// RuleOpType indicates the operation type of a RuleOp.
+type RuleOpType string
+
+const (
+	// RuleOpAdd a placement rule, only need to specify the field *Rule.
+	RuleOpAdd RuleOpType = "add"
+	// RuleOpDel a placement rule, only need to specify the field GroupID, ID.
+	RuleOpDel RuleOpType = "del"
+)
+
+// RuleOp is for batching placement rule actions. The action type is
+// distinguished by the field `Action`.
+type RuleOp struct {
+	*Rule                       // information of the placement rule to add/delete
+	Action           RuleOpType `json:"action"`              // the operation type
+	DeleteByIDPrefix bool       `json:"delete_by_id_prefix"` // if action == delete, delete by the prefix of id
+}
+
+func (r RuleOp) String() string {
+	return fmt.Sprintf("%s %s (by prefix: %v)", r.Action, r.Rule, r.DeleteByIDPrefix)
+}
+
+// Rules are ordered by (GroupIndex, GroupID, Index, ID).
 func compareRule(a, b *Rule) int {
 	switch {
+	case a.groupIndex() < b.groupIndex():
+		return -1
+	case a.groupIndex() > b.groupIndex():
+		return 1
 	case a.GroupID < b.GroupID:
 		return -1
 	case a.GroupID > b.GroupID:
@@ -92,7 +208,11 @@ func prepareRulesForApply(rules []*Rule) []*Rule {
 	var i, j int
 	for i = 1; i < len(rules); i++ {
 		if rules[j].GroupID != rules[i].GroupID {
-			res = append(res, rules[j:i]...)
+			if rules[i].groupOverride() {
+				res = res[:0] // override all previous groups
+			} else {
+				res = append(res, rules[j:i]...) // save rules belong to previous groups
+			}
 			j = i
 		}
 		if rules[i].Override {
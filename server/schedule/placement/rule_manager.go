@@ -17,11 +17,17 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
 
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/slice"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 // RuleManager is responsible for the lifecycle of all placement Rules.
@@ -31,14 +37,16 @@ type RuleManager struct {
 	sync.RWMutex
 	initialized bool
 	rules       map[[2]string]*Rule
+	groups      map[string]*RuleGroup
 	splitKeys   [][]byte
 }
 
 // NewRuleManager creates a RuleManager instance.
 func NewRuleManager(store *core.Storage) *RuleManager {
 	return &RuleManager{
-		store: store,
-		rules: make(map[[2]string]*Rule),
+		store:  store,
+		rules:  make(map[[2]string]*Rule),
+		groups: make(map[string]*RuleGroup),
 	}
 }
 
@@ -51,6 +59,17 @@ func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error
 		return nil
 	}
 
+	if _, err := m.store.LoadRuleGroups(func(v string) error {
+		var g RuleGroup
+		if err := json.Unmarshal([]byte(v), &g); err != nil {
+			return err
+		}
+		m.groups[g.ID] = &g
+		return nil
+	}); err != nil {
+		return err
+	}
+
 	var rules []*Rule
 	ok, err := m.store.LoadRules(func(v string) error {
 		var r Rule
@@ -81,6 +100,7 @@ func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error
 		if err = m.adjustRule(r); err != nil {
 			return err
 		}
+		r.group = m.groups[r.GroupID]
 		m.rules[r.Key()] = r
 	}
 	m.updateSplitKeys()
@@ -119,6 +139,12 @@ func (m *RuleManager) adjustRule(r *Rule) error {
 			return errors.Errorf("invalid op %s", c.Op)
 		}
 	}
+	if err := validateLeaderPreferences(r.LeaderPreferences); err != nil {
+		return err
+	}
+	if r.IsolationLevel != "" && slice.NoneOf(r.LocationLabels, func(i int) bool { return r.LocationLabels[i] == r.IsolationLevel }) {
+		return errors.Errorf("isolation level %s is not in location labels", r.IsolationLevel)
+	}
 	return nil
 }
 
@@ -137,6 +163,7 @@ func (m *RuleManager) SetRule(rule *Rule) error {
 	}
 	m.Lock()
 	defer m.Unlock()
+	rule.group = m.groups[rule.GroupID]
 	old := m.rules[rule.Key()]
 	m.rules[rule.Key()] = rule
 
@@ -172,6 +199,179 @@ func (m *RuleManager) DeleteRule(group, id string) error {
 	return nil
 }
 
+// Batch executes a series of actions at once. The changes are persisted in a
+// transaction, and the rules in memory are replaced only after that, so that
+// neither the storage nor a region sees the intermediate state of the batch.
+// A batch can change at most kv.MaxBatchOps rules.
+func (m *RuleManager) Batch(todo []RuleOp) error {
+	if err := m.batch(todo); err != nil {
+		return err
+	}
+	log.Info("placement rules updated", zap.String("batch", fmt.Sprint(todo)))
+	return nil
+}
+
+func (m *RuleManager) batch(todo []RuleOp) error {
+	for _, t := range todo {
+		if t.Rule == nil {
+			return errors.New("rule should not be empty")
+		}
+		switch t.Action {
+		case RuleOpAdd:
+			if err := m.adjustRule(t.Rule); err != nil {
+				return err
+			}
+		case RuleOpDel:
+		default:
+			return errors.Errorf("unknown action type %s", t.Action)
+		}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	rules := make(map[[2]string]*Rule, len(m.rules))
+	for k, r := range m.rules {
+		rules[k] = r
+	}
+	for _, t := range todo {
+		switch t.Action {
+		case RuleOpAdd:
+			// The rule is copied since it may be shared with a dry run.
+			r := *t.Rule
+			r.group = m.groups[r.GroupID]
+			rules[t.Key()] = &r
+		case RuleOpDel:
+			if !t.DeleteByIDPrefix {
+				delete(rules, t.Key())
+				continue
+			}
+			for k, r := range rules {
+				if r.GroupID == t.GroupID && strings.HasPrefix(r.ID, t.ID) {
+					delete(rules, k)
+				}
+			}
+		}
+	}
+
+	saves := make(map[string]interface{})
+	var deletes []string
+	for k, r := range m.rules {
+		if _, ok := rules[k]; !ok {
+			deletes = append(deletes, r.StoreKey())
+		}
+	}
+	for k, r := range rules {
+		if old, ok := m.rules[k]; !ok || old != r {
+			saves[r.StoreKey()] = r
+		}
+	}
+	if err := m.store.SaveRules(saves, deletes); err != nil {
+		return err
+	}
+
+	m.rules = rules
+	m.updateSplitKeys()
+	return nil
+}
+
+// DryRun returns a copy of the RuleManager with the actions applied. The copy
+// is kept in memory, so nothing is persisted.
+func (m *RuleManager) DryRun(todo []RuleOp) (*RuleManager, error) {
+	m.RLock()
+	dryRun := NewRuleManager(core.NewStorage(kv.NewMemoryKV()))
+	for k, r := range m.rules {
+		dryRun.rules[k] = r
+	}
+	for id, g := range m.groups {
+		dryRun.groups[id] = g
+	}
+	dryRun.initialized = m.initialized
+	m.RUnlock()
+
+	if err := dryRun.batch(todo); err != nil {
+		return nil, err
+	}
+	return dryRun, nil
+}
+
+// GetRuleGroup returns a RuleGroup configuration.
+func (m *RuleManager) GetRuleGroup(id string) *RuleGroup {
+	m.RLock()
+	defer m.RUnlock()
+	return m.groups[id]
+}
+
+// GetRuleGroups returns all RuleGroup configuration, ordered by (Index, ID).
+func (m *RuleManager) GetRuleGroups() []*RuleGroup {
+	m.RLock()
+	defer m.RUnlock()
+	groups := make([]*RuleGroup, 0, len(m.groups))
+	for _, g := range m.groups {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Index != groups[j].Index {
+			return groups[i].Index < groups[j].Index
+		}
+		return groups[i].ID < groups[j].ID
+	})
+	return groups
+}
+
+// SetRuleGroup updates a RuleGroup. A group with default configuration is
+// removed from storage.
+func (m *RuleManager) SetRuleGroup(group *RuleGroup) error {
+	if group.ID == "" {
+		return errors.New("group ID should not be empty")
+	}
+	if err := validateLeaderPreferences(group.LeaderPreferences); err != nil {
+		return err
+	}
+	if group.isDefault() {
+		return m.DeleteRuleGroup(group.ID)
+	}
+	m.Lock()
+	defer m.Unlock()
+	if err := m.store.SaveRuleGroup(group.ID, group); err != nil {
+		return err
+	}
+	m.groups[group.ID] = group
+	m.attachGroup(group.ID)
+	log.Info("group config updated", zap.Reflect("group", group))
+	return nil
+}
+
+// DeleteRuleGroup removes a RuleGroup configuration. Rules of the group are
+// kept and fall back to the default group configuration.
+func (m *RuleManager) DeleteRuleGroup(id string) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.groups[id]; !ok {
+		return nil
+	}
+	if err := m.store.DeleteRuleGroup(id); err != nil {
+		return err
+	}
+	delete(m.groups, id)
+	m.attachGroup(id)
+	log.Info("group config reset", zap.String("group", id))
+	return nil
+}
+
+// attachGroup refreshes the group configuration of all rules in the group.
+// Rules are copied instead of updated in place because they may be in use by
+// callers outside the lock.
+func (m *RuleManager) attachGroup(id string) {
+	for k, r := range m.rules {
+		if r.GroupID == id {
+			nr := *r
+			nr.group = m.groups[id]
+			m.rules[k] = &nr
+		}
+	}
+}
+
 func (m *RuleManager) updateSplitKeys() {
 	keys := m.splitKeys[:0]
 	m.splitKeys = m.splitKeys[:0]
@@ -285,7 +485,7 @@ func (m *RuleManager) GetRulesForApplyRegion(region *core.RegionInfo) []*Rule {
 }
 
 // FitRegion fits a region to the rules it matches.
-func (m *RuleManager) FitRegion(stores core.StoreSetInformer, region *core.RegionInfo) *RegionFit {
+func (m *RuleManager) FitRegion(stores StoreSet, region *core.RegionInfo) *RegionFit {
 	rules := m.GetRulesForApplyRegion(region)
 	return FitRegion(stores, region, rules)
 }
@@ -15,6 +15,7 @@ package placement
 
 import (
 	"encoding/hex"
+	"fmt"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -61,6 +62,7 @@ func (s *testManagerSuite) TestAdjustRule(c *C) {
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 0},
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: -1},
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 3, LabelConstraints: []LabelConstraint{{Op: "foo"}}},
+		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 3, LocationLabels: []string{"zone"}, IsolationLevel: "rack"},
 	}
 	c.Assert(s.manager.adjustRule(&rules[0]), IsNil)
 	c.Assert(rules[0].StartKey, DeepEquals, []byte{0x12, 0x3a, 0xbc})
@@ -159,6 +161,77 @@ func (s *testManagerSuite) TestKeys(c *C) {
 	}
 }
 
+func (s *testManagerSuite) TestBatch(c *C) {
+	ops := []RuleOp{
+		{Rule: &Rule{GroupID: "foo", ID: "bar1", StartKeyHex: "", EndKeyHex: "11", Role: "voter", Count: 1}, Action: RuleOpAdd},
+		{Rule: &Rule{GroupID: "foo", ID: "bar2", StartKeyHex: "11", EndKeyHex: "22", Role: "voter", Count: 1}, Action: RuleOpAdd},
+		{Rule: &Rule{GroupID: "foo", ID: "baz", StartKeyHex: "22", EndKeyHex: "33", Role: "voter", Count: 1}, Action: RuleOpAdd},
+		{Rule: &Rule{GroupID: "pd", ID: "default"}, Action: RuleOpDel},
+	}
+	c.Assert(s.manager.Batch(ops), IsNil)
+	c.Assert(s.manager.GetAllRules(), HasLen, 3)
+	c.Assert(s.manager.GetRule("pd", "default"), IsNil)
+
+	ops = []RuleOp{
+		{Rule: &Rule{GroupID: "foo", ID: "bar"}, Action: RuleOpDel, DeleteByIDPrefix: true},
+		{Rule: &Rule{GroupID: "pd", ID: "default", Role: "voter", Count: 3}, Action: RuleOpAdd},
+	}
+	c.Assert(s.manager.Batch(ops), IsNil)
+	rules := s.manager.GetAllRules()
+	c.Assert(rules, HasLen, 2)
+	c.Assert(rules[0].Key(), Equals, [2]string{"foo", "baz"})
+	c.Assert(rules[1].Key(), Equals, [2]string{"pd", "default"})
+
+	// Invalid rule fails the whole batch.
+	ops = []RuleOp{
+		{Rule: &Rule{GroupID: "foo", ID: "baz"}, Action: RuleOpDel},
+		{Rule: &Rule{GroupID: "foo", ID: "qux", Role: "voter", Count: 0}, Action: RuleOpAdd},
+	}
+	c.Assert(s.manager.Batch(ops), NotNil)
+	c.Assert(s.manager.GetAllRules(), HasLen, 2)
+
+	// A batch which fails to persist changes nothing.
+	ops = []RuleOp{{Rule: &Rule{GroupID: "foo", ID: "baz"}, Action: RuleOpDel}}
+	for i := 0; i < kv.MaxBatchOps; i++ {
+		ops = append(ops, RuleOp{Rule: &Rule{GroupID: "foo", ID: fmt.Sprintf("r%d", i), StartKeyHex: fmt.Sprintf("%04x", i), EndKeyHex: fmt.Sprintf("%04x", i+1), Role: "voter", Count: 1}, Action: RuleOpAdd})
+	}
+	c.Assert(s.manager.Batch(ops), NotNil)
+	c.Assert(s.manager.GetAllRules(), HasLen, 2)
+
+	m2 := NewRuleManager(s.store)
+	err := m2.Initialize(3, []string{"no", "labels"})
+	c.Assert(err, IsNil)
+	c.Assert(m2.GetAllRules(), HasLen, 2)
+}
+
+func (s *testManagerSuite) TestGroupConfig(c *C) {
+	c.Assert(s.manager.SetRule(&Rule{GroupID: "g1", ID: "1", Role: "voter", Count: 1}), IsNil)
+	region := core.NewRegionInfo(&metapb.Region{}, nil)
+	rules := s.manager.GetRulesForApplyRegion(region)
+	c.Assert(rules, HasLen, 2)
+	c.Assert(rules[0].Key(), Equals, [2]string{"g1", "1"})
+	c.Assert(rules[1].Key(), Equals, [2]string{"pd", "default"})
+
+	// Move g1 after pd and let it override.
+	c.Assert(s.manager.SetRuleGroup(&RuleGroup{ID: "g1", Index: 1, Override: true}), IsNil)
+	rules = s.manager.GetRulesForApplyRegion(region)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].Key(), Equals, [2]string{"g1", "1"})
+	c.Assert(s.manager.GetRuleGroups(), HasLen, 1)
+
+	m2 := NewRuleManager(s.store)
+	err := m2.Initialize(3, []string{"no", "labels"})
+	c.Assert(err, IsNil)
+	c.Assert(m2.GetRuleGroup("g1"), DeepEquals, &RuleGroup{ID: "g1", Index: 1, Override: true})
+	c.Assert(m2.GetRulesForApplyRegion(region), HasLen, 1)
+
+	// Default config is the same as no config.
+	c.Assert(s.manager.SetRuleGroup(&RuleGroup{ID: "g1"}), IsNil)
+	c.Assert(s.manager.GetRuleGroup("g1"), IsNil)
+	c.Assert(s.manager.GetRuleGroups(), HasLen, 0)
+	c.Assert(s.manager.GetRulesForApplyRegion(region), HasLen, 2)
+}
+
 func (s *testManagerSuite) dhex(hk string) []byte {
 	k, err := hex.DecodeString(hk)
 	if err != nil {
@@ -166,3 +239,22 @@ func (s *testManagerSuite) dhex(hk string) []byte {
 	}
 	return k
 }
+
+func (s *testManagerSuite) TestDryRun(c *C) {
+	ops := []RuleOp{
+		{Rule: &Rule{GroupID: "foo", ID: "bar", Role: "voter", Count: 1}, Action: RuleOpAdd},
+		{Rule: &Rule{GroupID: "pd", ID: "default"}, Action: RuleOpDel},
+	}
+	dryRun, err := s.manager.DryRun(ops)
+	c.Assert(err, IsNil)
+	c.Assert(dryRun.GetAllRules(), HasLen, 1)
+	c.Assert(dryRun.GetRule("foo", "bar"), NotNil)
+
+	// The original manager and storage are not changed.
+	c.Assert(s.manager.GetAllRules(), HasLen, 1)
+	c.Assert(s.manager.GetRule("pd", "default"), NotNil)
+	m2 := NewRuleManager(s.store)
+	c.Assert(m2.Initialize(3, []string{"no", "labels"}), IsNil)
+	c.Assert(m2.GetRule("pd", "default"), NotNil)
+	c.Assert(m2.GetRule("foo", "bar"), IsNil)
+}
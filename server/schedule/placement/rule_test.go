@@ -17,6 +17,7 @@ import (
 	"math/rand"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server/core"
 )
 
 var _ = Suite(&testRuleSuite{})
@@ -58,3 +59,47 @@ func (s *testRuleSuite) TestPrepareRulesForApply(c *C) {
 		c.Assert(rules[i].Key(), Equals, expected[i])
 	}
 }
+
+func (s *testRuleSuite) TestGroupOverride(c *C) {
+	g1 := &RuleGroup{ID: "g1", Index: 1}
+	g2 := &RuleGroup{ID: "g2", Index: 2, Override: true}
+	g3 := &RuleGroup{ID: "g3", Index: 3}
+	rules := []*Rule{
+		{GroupID: "g1", ID: "id1", group: g1},
+		{GroupID: "g1", ID: "id2", group: g1},
+		{GroupID: "g2", ID: "id1", group: g2},
+		{GroupID: "g3", ID: "id1", group: g3},
+		{GroupID: "g0", ID: "id1"},
+	}
+	expected := [][2]string{
+		{"g2", "id1"}, {"g3", "id1"},
+	}
+
+	rand.Shuffle(len(rules), func(i, j int) { rules[i], rules[j] = rules[j], rules[i] })
+	rules = prepareRulesForApply(rules)
+
+	c.Assert(len(rules), Equals, len(expected))
+	for i := range rules {
+		c.Assert(rules[i].Key(), Equals, expected[i])
+	}
+}
+
+func (s *testRuleSuite) TestLeaderPreferences(c *C) {
+	z1 := core.NewStoreInfoWithLabel(1, 0, map[string]string{"zone": "z1"})
+	z2 := core.NewStoreInfoWithLabel(2, 0, map[string]string{"zone": "z2"})
+	z3 := core.NewStoreInfoWithLabel(3, 0, map[string]string{"zone": "z3"})
+	prefs := [][]LabelConstraint{
+		{{Key: "zone", Op: In, Values: []string{"z1"}}},
+		{{Key: "zone", Op: In, Values: []string{"z2"}}},
+	}
+	c.Assert(LeaderPreferenceRank(z1, prefs), Equals, 0)
+	c.Assert(LeaderPreferenceRank(z2, prefs), Equals, 1)
+	c.Assert(LeaderPreferenceRank(z3, prefs), Equals, 2)
+	c.Assert(LeaderPreferenceRank(z3, nil), Equals, 0)
+
+	group := &RuleGroup{ID: "g", LeaderPreferences: prefs[1:]}
+	rule := &Rule{GroupID: "g", ID: "r", group: group}
+	c.Assert(rule.GetLeaderPreferences(), DeepEquals, prefs[1:])
+	rule.LeaderPreferences = prefs
+	c.Assert(rule.GetLeaderPreferences(), DeepEquals, prefs)
+}
@@ -97,6 +97,10 @@ func (r *RegionScatterer) Scatter(region *core.RegionInfo) (*operator.Operator,
 		return nil, errors.Errorf("region %d has no leader", region.GetID())
 	}
 
+	if r.cluster.GetRegionLabeler().IsScheduleDenied(region) {
+		return nil, errors.Errorf("region %d is labeled to deny scheduling", region.GetID())
+	}
+
 	return r.scatterRegion(region), nil
 }
 
@@ -14,10 +14,14 @@
 package schedule
 
 import (
+	"math"
+	"sync"
 	"time"
 
 	"github.com/juju/ratelimit"
+	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pkg/errors"
 )
 
 // StoreLimitMode indicates the strategy to set store limit
@@ -62,6 +66,16 @@ func NewStoreLimit(rate float64, mode StoreLimitMode) *StoreLimit {
 	}
 }
 
+// withRate returns a limit of the rate in the same mode, which keeps the
+// tokens available in l, so that changing the rate does not refill the bucket.
+func (l *StoreLimit) withRate(rate float64) *StoreLimit {
+	limit := NewStoreLimit(rate, l.mode)
+	if excess := limit.bucket.Available() - l.bucket.Available(); excess > 0 {
+		limit.bucket.Take(excess)
+	}
+	return limit
+}
+
 // Available returns the number of available tokens
 func (l *StoreLimit) Available() int64 {
 	return l.bucket.Available()
@@ -81,3 +95,147 @@ func (l *StoreLimit) Take(count int64) time.Duration {
 func (l *StoreLimit) Mode() StoreLimitMode {
 	return l.mode
 }
+
+// StoreLimitKey identifies a bucket of the store limit. The bucket whose Kind
+// is zero is the default one of the store, which is used by the operators
+// whose kinds have no bucket of their own.
+type StoreLimitKey struct {
+	Type core.StoreLimitType
+	Kind operator.OpKind
+}
+
+// storeLimitKinds are the operator kinds which can have their own buckets. An
+// operator with multiple kinds uses the bucket of the first kind here.
+var storeLimitKinds = []operator.OpKind{
+	operator.OpAdmin,
+	operator.OpReplica,
+	operator.OpMerge,
+	operator.OpHotRegion,
+	operator.OpBalance,
+	operator.OpRange,
+	operator.OpAdjacent,
+	operator.OpRegion,
+}
+
+// storeLimitKindNames maps the names of storeLimitKinds to the kinds, so that
+// the names passed by the filters are not parsed in every check.
+var storeLimitKindNames = func() map[string]operator.OpKind {
+	names := make(map[string]operator.OpKind, len(storeLimitKinds))
+	for _, k := range storeLimitKinds {
+		names[k.String()] = k
+	}
+	return names
+}()
+
+// removePeerExemptKinds are the operator kinds which take no tokens from the
+// default remove-peer bucket, so that repairing replicas and the operators
+// created by the admin do not wait for the balance to move peers out of the
+// store. They are limited only by the remove-peer buckets of their own.
+const removePeerExemptKinds = operator.OpReplica | operator.OpAdmin
+
+// ParseStoreLimitKind converts string to an operator kind which can have its
+// own bucket of the store limit.
+func ParseStoreLimitKind(str string) (operator.OpKind, error) {
+	kind, err := operator.ParseOperatorKind(str)
+	if err != nil {
+		return 0, err
+	}
+	for _, k := range storeLimitKinds {
+		if kind == k {
+			return kind, nil
+		}
+	}
+	return 0, errors.Errorf("operator kind %s cannot be limited separately", str)
+}
+
+// String returns the representation of the StoreLimitKey.
+func (k StoreLimitKey) String() string {
+	if k.Kind == 0 {
+		return k.Type.String()
+	}
+	return k.Kind.String() + "/" + k.Type.String()
+}
+
+// pickStoreLimit returns the bucket used by the operator of the kind, or nil
+// if the operator is not limited.
+func pickStoreLimit(limits map[StoreLimitKey]*StoreLimit, limitType core.StoreLimitType, kind operator.OpKind) (StoreLimitKey, *StoreLimit) {
+	for _, k := range storeLimitKinds {
+		if kind&k == 0 {
+			continue
+		}
+		key := StoreLimitKey{Type: limitType, Kind: k}
+		if limit, ok := limits[key]; ok {
+			return key, limit
+		}
+	}
+	key := StoreLimitKey{Type: limitType}
+	if limitType == core.StoreLimitRemovePeer && kind&removePeerExemptKinds != 0 {
+		return key, nil
+	}
+	return key, limits[key]
+}
+
+const (
+	// addLearnerDurationWeight is the weight of the latest duration in the
+	// moving average of the durations of adding learners.
+	addLearnerDurationWeight = 0.2
+	// addLearnerDurationHalfLife is the time in which the moving average
+	// decays by half if no learner is added to the store, so a store which
+	// was slow once is not limited forever.
+	addLearnerDurationHalfLife = 5 * time.Minute
+)
+
+type addLearnerDuration struct {
+	duration   time.Duration
+	updateTime time.Time
+}
+
+// decayed returns the duration decayed by the time passed since the update.
+func (d addLearnerDuration) decayed(now time.Time) time.Duration {
+	elapsed := now.Sub(d.updateTime)
+	if elapsed <= 0 {
+		return d.duration
+	}
+	return time.Duration(float64(d.duration) * math.Exp2(-float64(elapsed)/float64(addLearnerDurationHalfLife)))
+}
+
+// addLearnerDurations records the moving average of the durations of adding
+// learners to each store, which shows if the store keeps up with the
+// snapshots sent to it.
+type addLearnerDurations struct {
+	sync.RWMutex
+	durations map[uint64]addLearnerDuration
+}
+
+func newAddLearnerDurations() *addLearnerDurations {
+	return &addLearnerDurations{durations: make(map[uint64]addLearnerDuration)}
+}
+
+func (d *addLearnerDurations) observe(storeID uint64, duration time.Duration) {
+	d.observeAt(storeID, duration, time.Now())
+}
+
+func (d *addLearnerDurations) observeAt(storeID uint64, duration time.Duration, now time.Time) {
+	d.Lock()
+	defer d.Unlock()
+	if old, ok := d.durations[storeID]; ok {
+		duration = time.Duration(addLearnerDurationWeight*float64(duration) + (1-addLearnerDurationWeight)*float64(old.decayed(now)))
+	}
+	d.durations[storeID] = addLearnerDuration{duration: duration, updateTime: now}
+}
+
+func (d *addLearnerDurations) get(storeID uint64) time.Duration {
+	return d.getAt(storeID, time.Now())
+}
+
+func (d *addLearnerDurations) getAt(storeID uint64, now time.Time) time.Duration {
+	d.RLock()
+	defer d.RUnlock()
+	return d.durations[storeID].decayed(now)
+}
+
+func (d *addLearnerDurations) remove(storeID uint64) {
+	d.Lock()
+	defer d.Unlock()
+	delete(d.durations, storeID)
+}
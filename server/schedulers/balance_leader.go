@@ -74,6 +74,7 @@ type balanceLeaderScheduler struct {
 	opController *schedule.OperatorController
 	filters      []filter.Filter
 	counter      *prometheus.CounterVec
+	diagnostic   *schedule.DiagnosticRecorder
 }
 
 // newBalanceLeaderScheduler creates a scheduler that tends to keep leaders on
@@ -91,6 +92,7 @@ func newBalanceLeaderScheduler(opController *schedule.OperatorController, conf *
 		opt(s)
 	}
 	s.filters = []filter.Filter{filter.StoreStateFilter{ActionScope: s.GetName(), TransferLeader: true}}
+	s.diagnostic = schedule.NewDiagnosticRecorder(s.GetName())
 	return s
 }
 
@@ -127,13 +129,21 @@ func (l *balanceLeaderScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return l.opController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
 }
 
-func (l *balanceLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+func (l *balanceLeaderScheduler) GetDiagnosticRecorder() *schedule.DiagnosticRecorder {
+	return l.diagnostic
+}
+
+func (l *balanceLeaderScheduler) Schedule(cluster opt.Cluster) (ops []*operator.Operator) {
 	schedulerCounter.WithLabelValues(l.GetName(), "schedule").Inc()
+	l.diagnostic.Begin()
+	defer func() { l.diagnostic.End(ops) }()
 
 	leaderSchedulePolicy := l.opController.GetLeaderSchedulePolicy()
 	stores := cluster.GetStores()
-	sources := filter.SelectSourceStores(stores, l.filters, cluster)
-	targets := filter.SelectTargetStores(stores, l.filters, cluster)
+	filters := l.diagnostic.WrapFilters(l.filters)
+	sources := filter.SelectSourceStores(stores, filters, cluster)
+	targets := filter.SelectTargetStores(stores, filters, cluster)
+	l.diagnostic.RecordSourceStores(sources)
 	opInfluence := l.opController.GetOpInfluence(cluster)
 	kind := core.NewScheduleKind(core.LeaderKind, leaderSchedulePolicy)
 	sort.Slice(sources, func(i, j int) bool {
@@ -190,14 +200,19 @@ func (l *balanceLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 // the best follower peer and transfers the leader.
 func (l *balanceLeaderScheduler) transferLeaderOut(cluster opt.Cluster, source *core.StoreInfo) []*operator.Operator {
 	sourceID := source.GetID()
-	region := cluster.RandLeaderRegion(sourceID, l.conf.Ranges, opt.HealthRegion(cluster))
+	region := cluster.RandLeaderRegion(sourceID, l.conf.Ranges, opt.HealthRegion(cluster), opt.ScheduleAllowedRegion(cluster))
 	if region == nil {
 		log.Debug("store has no leader", zap.String("scheduler", l.GetName()), zap.Uint64("store-id", sourceID))
 		schedulerCounter.WithLabelValues(l.GetName(), "no-leader-region").Inc()
+		l.diagnostic.RecordEvent("no-leader-region")
 		return nil
 	}
 	targets := cluster.GetFollowerStores(region)
-	targets = filter.SelectTargetStores(targets, l.filters, cluster)
+	finalFilters := l.filters
+	if cluster.IsPlacementRulesEnabled() {
+		finalFilters = append([]filter.Filter{filter.NewLeaderPreferenceFilter(l.GetName(), cluster, region, source)}, l.filters...)
+	}
+	targets = filter.SelectTargetStores(targets, l.diagnostic.WrapFilters(finalFilters), cluster)
 	leaderSchedulePolicy := l.opController.GetLeaderSchedulePolicy()
 	sort.Slice(targets, func(i, j int) bool {
 		return targets[i].LeaderScore(leaderSchedulePolicy, 0) < targets[j].LeaderScore(leaderSchedulePolicy, 0)
@@ -209,6 +224,7 @@ func (l *balanceLeaderScheduler) transferLeaderOut(cluster opt.Cluster, source *
 	}
 	log.Debug("region has no target store", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
 	schedulerCounter.WithLabelValues(l.GetName(), "no-target-store").Inc()
+	l.diagnostic.RecordEvent("no-target-store")
 	return nil
 }
 
@@ -217,10 +233,11 @@ func (l *balanceLeaderScheduler) transferLeaderOut(cluster opt.Cluster, source *
 // the worst follower peer and transfers the leader.
 func (l *balanceLeaderScheduler) transferLeaderIn(cluster opt.Cluster, target *core.StoreInfo) []*operator.Operator {
 	targetID := target.GetID()
-	region := cluster.RandFollowerRegion(targetID, l.conf.Ranges, opt.HealthRegion(cluster))
+	region := cluster.RandFollowerRegion(targetID, l.conf.Ranges, opt.HealthRegion(cluster), opt.ScheduleAllowedRegion(cluster))
 	if region == nil {
 		log.Debug("store has no follower", zap.String("scheduler", l.GetName()), zap.Uint64("store-id", targetID))
 		schedulerCounter.WithLabelValues(l.GetName(), "no-follower-region").Inc()
+		l.diagnostic.RecordEvent("no-follower-region")
 		return nil
 	}
 	leaderStoreID := region.GetLeader().GetStoreId()
@@ -232,8 +249,18 @@ func (l *balanceLeaderScheduler) transferLeaderIn(cluster opt.Cluster, target *c
 			zap.Uint64("store-id", leaderStoreID),
 		)
 		schedulerCounter.WithLabelValues(l.GetName(), "no-leader").Inc()
+		l.diagnostic.RecordEvent("no-leader")
 		return nil
 	}
+	if cluster.IsPlacementRulesEnabled() {
+		f := filter.NewLeaderPreferenceFilter(l.GetName(), cluster, region, source)
+		if f.Target(cluster, target) {
+			log.Debug("target store is less preferred to hold the leader", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
+			schedulerCounter.WithLabelValues(l.GetName(), "leader-preference").Inc()
+			l.diagnostic.RecordEvent("leader-preference")
+			return nil
+		}
+	}
 	return l.createOperator(cluster, region, source, target)
 }
 
@@ -245,6 +272,7 @@ func (l *balanceLeaderScheduler) createOperator(cluster opt.Cluster, region *cor
 	if cluster.IsRegionHot(region) {
 		log.Debug("region is hot region, ignore it", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
 		schedulerCounter.WithLabelValues(l.GetName(), "region-hot").Inc()
+		l.diagnostic.RecordEvent("region-hot")
 		return nil
 	}
 
@@ -253,8 +281,9 @@ func (l *balanceLeaderScheduler) createOperator(cluster opt.Cluster, region *cor
 
 	opInfluence := l.opController.GetOpInfluence(cluster)
 	kind := core.NewScheduleKind(core.LeaderKind, cluster.GetLeaderSchedulePolicy())
-	if !shouldBalance(cluster, source, target, region, kind, opInfluence, l.GetName()) {
+	if !shouldBalance(cluster, source, target, region, kind, opInfluence, l.GetName(), l.diagnostic) {
 		schedulerCounter.WithLabelValues(l.GetName(), "skip").Inc()
+		l.diagnostic.RecordEvent("skip")
 		return nil
 	}
 
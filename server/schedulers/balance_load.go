@@ -0,0 +1,443 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/slice"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/statistics"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// BalanceLoadName is balance load scheduler name.
+	BalanceLoadName = "balance-load-scheduler"
+	// BalanceLoadType is balance load scheduler type.
+	BalanceLoadType = "balance-load"
+	// balanceLoadRetryLimit is the limit to retry schedule for a selected source store.
+	balanceLoadRetryLimit = 10
+
+	// A store becomes overloaded when its load exceeds highLoadRatio times the
+	// mean load of the cluster in minOverloadedHeartbeats store heartbeats in a
+	// row. It stays overloaded until its load falls below lowLoadRatio times the
+	// mean load, so that the scheduler does not flap around the threshold.
+	highLoadRatio           = 1.5
+	lowLoadRatio            = 1.2
+	minOverloadedHeartbeats = 3
+	// A store can receive leaders or peers only when its load is lower than
+	// the mean load of the cluster.
+	targetLoadRatio = 1.0
+)
+
+// The loads of a store that the balance load scheduler balances.
+const (
+	cpuLoad int = iota
+	diskReadLoad
+	diskWriteLoad
+	loadDimLen
+)
+
+var loadDimNames = [loadDimLen]string{"cpu", "disk-read", "disk-write"}
+
+func init() {
+	schedule.RegisterSliceDecoderBuilder(BalanceLoadType, func(args []string) schedule.ConfigDecoder {
+		return func(v interface{}) error {
+			conf, ok := v.(*balanceLoadSchedulerConfig)
+			if !ok {
+				return ErrScheduleConfigNotExist
+			}
+			ranges, err := getKeyRanges(args)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			conf.Ranges = ranges
+			conf.Name = BalanceLoadName
+			return nil
+		}
+	})
+
+	schedule.RegisterScheduler(BalanceLoadType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
+		conf := &balanceLoadSchedulerConfig{}
+		if err := decoder(conf); err != nil {
+			return nil, err
+		}
+		return newBalanceLoadScheduler(opController, conf), nil
+	})
+}
+
+type balanceLoadSchedulerConfig struct {
+	Name   string          `json:"name"`
+	Ranges []core.KeyRange `json:"ranges"`
+}
+
+// storeLoadState records whether the loads of a store are overloaded.
+type storeLoadState struct {
+	// ratios are the loads of the store divided by the mean loads of the cluster.
+	ratios [loadDimLen]float64
+	// heartbeats counts the heartbeats in which the load stays high, and
+	// lastHeartbeat is the last heartbeat counted, so that the loads reported
+	// in one heartbeat are not counted again in every `Schedule` call.
+	heartbeats    [loadDimLen]int
+	lastHeartbeat time.Time
+	overloaded    [loadDimLen]bool
+}
+
+func (s *storeLoadState) isOverloaded() bool {
+	for _, overloaded := range s.overloaded {
+		if overloaded {
+			return true
+		}
+	}
+	return false
+}
+
+// maxOverloadedRatio returns the max load ratio of the overloaded dimensions.
+func (s *storeLoadState) maxOverloadedRatio() float64 {
+	var ratio float64
+	for dim := 0; dim < loadDimLen; dim++ {
+		if s.overloaded[dim] && s.ratios[dim] > ratio {
+			ratio = s.ratios[dim]
+		}
+	}
+	return ratio
+}
+
+type balanceLoadScheduler struct {
+	*BaseScheduler
+	sync.Mutex
+	conf         *balanceLoadSchedulerConfig
+	opController *schedule.OperatorController
+	filters      []filter.Filter
+	// states across multiple `Schedule` calls
+	states map[uint64]*storeLoadState
+}
+
+// newBalanceLoadScheduler creates a scheduler that moves leaders and regions
+// away from the stores whose CPU usage or disk IO rate stays far above the
+// mean of the cluster.
+func newBalanceLoadScheduler(opController *schedule.OperatorController, conf *balanceLoadSchedulerConfig) schedule.Scheduler {
+	s := &balanceLoadScheduler{
+		BaseScheduler: NewBaseScheduler(opController),
+		conf:          conf,
+		opController:  opController,
+		states:        make(map[uint64]*storeLoadState),
+	}
+	s.filters = []filter.Filter{filter.StoreStateFilter{ActionScope: s.GetName()}}
+	return s
+}
+
+func (s *balanceLoadScheduler) GetName() string {
+	return s.conf.Name
+}
+
+func (s *balanceLoadScheduler) GetType() string {
+	return BalanceLoadType
+}
+
+func (s *balanceLoadScheduler) EncodeConfig() ([]byte, error) {
+	return schedule.EncodeConfig(s.conf)
+}
+
+func (s *balanceLoadScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
+	return s.allowBalanceLeader(cluster) || s.allowBalanceRegion(cluster)
+}
+
+func (s *balanceLoadScheduler) allowBalanceLeader(cluster opt.Cluster) bool {
+	return s.opController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
+}
+
+func (s *balanceLoadScheduler) allowBalanceRegion(cluster opt.Cluster) bool {
+	return s.opController.OperatorCount(operator.OpRegion) < cluster.GetRegionScheduleLimit()
+}
+
+func (s *balanceLoadScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	s.Lock()
+	defer s.Unlock()
+
+	stores := filter.SelectSourceStores(cluster.GetStores(), s.filters, cluster)
+	s.updateLoadStates(cluster, stores)
+
+	sources := make([]*core.StoreInfo, 0, len(stores))
+	for _, store := range stores {
+		if s.states[store.GetID()].isOverloaded() {
+			sources = append(sources, store)
+		}
+	}
+	if len(sources) == 0 {
+		schedulerCounter.WithLabelValues(s.GetName(), "no-overloaded-store").Inc()
+		return nil
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return s.states[sources[i].GetID()].maxOverloadedRatio() > s.states[sources[j].GetID()].maxOverloadedRatio()
+	})
+
+	for _, source := range sources {
+		state := s.states[source.GetID()]
+		// The leader serves the reads and costs most of the CPU, so transferring
+		// leaders is preferred for these dimensions.
+		if (state.overloaded[cpuLoad] || state.overloaded[diskReadLoad]) && s.allowBalanceLeader(cluster) {
+			if op := s.transferLeaderOut(cluster, source); op != nil {
+				return []*operator.Operator{op}
+			}
+		}
+		if s.allowBalanceRegion(cluster) {
+			if op := s.movePeerOut(cluster, source); op != nil {
+				return []*operator.Operator{op}
+			}
+		}
+	}
+	schedulerCounter.WithLabelValues(s.GetName(), "skip").Inc()
+	return nil
+}
+
+// updateLoadStates updates the overloaded states of stores with the rolling
+// loads of stores.
+func (s *balanceLoadScheduler) updateLoadStates(cluster opt.Cluster, stores []*core.StoreInfo) {
+	storesStats := cluster.GetStoresStats()
+	var loads [loadDimLen]map[uint64]float64
+	loads[cpuLoad] = storesStats.GetStoresCPUUsage()
+	loads[diskReadLoad] = storesStats.GetStoresDiskReadRate()
+	loads[diskWriteLoad] = storesStats.GetStoresDiskWriteRate()
+
+	var means [loadDimLen]float64
+	for dim := 0; dim < loadDimLen; dim++ {
+		for _, store := range stores {
+			means[dim] += loads[dim][store.GetID()]
+		}
+		if len(stores) > 0 {
+			means[dim] /= float64(len(stores))
+		}
+	}
+
+	states := make(map[uint64]*storeLoadState, len(stores))
+	for _, store := range stores {
+		id := store.GetID()
+		state, ok := s.states[id]
+		if !ok {
+			state = &storeLoadState{}
+		}
+		newHeartbeat := store.GetLastHeartbeatTS().After(state.lastHeartbeat)
+		if newHeartbeat {
+			state.lastHeartbeat = store.GetLastHeartbeatTS()
+		}
+		for dim := 0; dim < loadDimLen; dim++ {
+			ratio := 0.0
+			if means[dim] > 0 {
+				ratio = loads[dim][id] / means[dim]
+			}
+			state.ratios[dim] = ratio
+			switch {
+			case state.overloaded[dim]:
+				if ratio < lowLoadRatio {
+					state.overloaded[dim], state.heartbeats[dim] = false, 0
+				}
+			case ratio >= highLoadRatio:
+				if newHeartbeat {
+					state.heartbeats[dim]++
+				}
+				if state.heartbeats[dim] >= minOverloadedHeartbeats {
+					log.Info("store is overloaded", zap.String("scheduler", s.GetName()), zap.Uint64("store-id", id),
+						zap.String("load", loadDimNames[dim]), zap.Float64("ratio", ratio))
+					state.overloaded[dim] = true
+				}
+			default:
+				state.heartbeats[dim] = 0
+			}
+		}
+		states[id] = state
+	}
+	// The states of removed stores are dropped.
+	s.states = states
+}
+
+// isTarget checks if the store can receive loads of the overloaded dimensions
+// of the source store.
+func (s *balanceLoadScheduler) isTarget(source, target *storeLoadState) bool {
+	if target == nil {
+		return false
+	}
+	for dim := 0; dim < loadDimLen; dim++ {
+		if source.overloaded[dim] && target.ratios[dim] >= targetLoadRatio {
+			return false
+		}
+	}
+	return true
+}
+
+// selectTarget picks the least loaded store among the candidates.
+func (s *balanceLoadScheduler) selectTarget(source *storeLoadState, candidates []*core.StoreInfo) *core.StoreInfo {
+	var (
+		best      *core.StoreInfo
+		bestRatio float64
+	)
+	for _, store := range candidates {
+		state := s.states[store.GetID()]
+		if !s.isTarget(source, state) {
+			continue
+		}
+		var ratio float64
+		for dim := 0; dim < loadDimLen; dim++ {
+			if source.overloaded[dim] && state.ratios[dim] > ratio {
+				ratio = state.ratios[dim]
+			}
+		}
+		if best == nil || ratio < bestRatio {
+			best, bestRatio = store, ratio
+		}
+	}
+	return best
+}
+
+// hotRegions returns the regions of the hot peers on the store, hottest first,
+// since moving them takes the most load away from the store. Only the regions
+// in the ranges of the scheduler which pass the options are returned.
+func (s *balanceLoadScheduler) hotRegions(cluster opt.Cluster, storeID uint64, kinds []statistics.FlowKind, opts ...core.RegionOption) []*core.RegionInfo {
+	var peers []*statistics.HotPeerStat
+	for _, kind := range kinds {
+		switch kind {
+		case statistics.ReadFlow:
+			peers = append(peers, cluster.RegionReadStats()[storeID]...)
+		case statistics.WriteFlow:
+			peers = append(peers, cluster.RegionWriteStats()[storeID]...)
+		}
+	}
+	sort.SliceStable(peers, func(i, j int) bool {
+		return peers[i].GetByteRate() > peers[j].GetByteRate()
+	})
+
+	regions := make([]*core.RegionInfo, 0, balanceLoadRetryLimit)
+	picked := make(map[uint64]struct{})
+	for _, peer := range peers {
+		if len(regions) >= balanceLoadRetryLimit {
+			break
+		}
+		if _, ok := picked[peer.RegionID]; ok {
+			continue
+		}
+		region := cluster.GetRegion(peer.RegionID)
+		if region == nil || region.GetStorePeer(storeID) == nil || !isInKeyRanges(region, s.conf.Ranges) {
+			continue
+		}
+		if !slice.AllOf(opts, func(i int) bool { return opts[i](region) }) {
+			continue
+		}
+		picked[peer.RegionID] = struct{}{}
+		regions = append(regions, region)
+	}
+	return regions
+}
+
+// isInKeyRanges checks if the region is inside one of the key ranges.
+func isInKeyRanges(region *core.RegionInfo, ranges []core.KeyRange) bool {
+	if len(ranges) == 0 {
+		return true
+	}
+	for _, r := range ranges {
+		if bytes.Compare(region.GetStartKey(), r.StartKey) >= 0 &&
+			(len(r.EndKey) == 0 || (len(region.GetEndKey()) > 0 && bytes.Compare(region.GetEndKey(), r.EndKey) <= 0)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *balanceLoadScheduler) transferLeaderOut(cluster opt.Cluster, source *core.StoreInfo) *operator.Operator {
+	sourceID := source.GetID()
+	state := s.states[sourceID]
+	// Only the leader serves the reads, so the read flow of the leaders tells
+	// which leaders cost the most.
+	isLeader := func(region *core.RegionInfo) bool { return region.GetLeader().GetStoreId() == sourceID }
+	regions := s.hotRegions(cluster, sourceID, []statistics.FlowKind{statistics.ReadFlow}, isLeader, opt.HealthRegion(cluster), opt.ScheduleAllowedRegion(cluster))
+	if len(regions) == 0 {
+		schedulerCounter.WithLabelValues(s.GetName(), "no-hot-leader-region").Inc()
+		return nil
+	}
+	for _, region := range regions {
+		candidates := filter.SelectTargetStores(cluster.GetFollowerStores(region), []filter.Filter{
+			filter.StoreStateFilter{ActionScope: s.GetName(), TransferLeader: true},
+		}, cluster)
+		target := s.selectTarget(state, candidates)
+		if target == nil {
+			schedulerCounter.WithLabelValues(s.GetName(), "no-target-store").Inc()
+			continue
+		}
+		op, err := operator.CreateTransferLeaderOperator(BalanceLoadType, cluster, region, sourceID, target.GetID(), operator.OpBalance)
+		if err != nil {
+			log.Debug("fail to create balance load operator", zap.Error(err))
+			continue
+		}
+		op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(s.GetName(), "new-operator"))
+		return op
+	}
+	return nil
+}
+
+func (s *balanceLoadScheduler) movePeerOut(cluster opt.Cluster, source *core.StoreInfo) *operator.Operator {
+	sourceID := source.GetID()
+	state := s.states[sourceID]
+	var kinds []statistics.FlowKind
+	if state.overloaded[diskWriteLoad] {
+		kinds = append(kinds, statistics.WriteFlow)
+	}
+	if state.overloaded[cpuLoad] || state.overloaded[diskReadLoad] {
+		kinds = append(kinds, statistics.ReadFlow)
+	}
+	regions := s.hotRegions(cluster, sourceID, kinds, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster), opt.ScheduleAllowedRegion(cluster))
+	if len(regions) == 0 {
+		schedulerCounter.WithLabelValues(s.GetName(), "no-hot-region").Inc()
+		return nil
+	}
+	for _, region := range regions {
+		var scoreGuard filter.Filter
+		if cluster.IsPlacementRulesEnabled() {
+			scoreGuard = filter.NewRuleFitFilter(s.GetName(), cluster, region, sourceID)
+		} else {
+			scoreGuard = filter.NewDistinctScoreFilter(s.GetName(), cluster.GetLocationLabels(), cluster.GetRegionStores(region), source)
+		}
+		candidates := filter.SelectTargetStores(cluster.GetStores(), []filter.Filter{
+			filter.StoreStateFilter{ActionScope: s.GetName(), MoveRegion: true},
+			filter.NewExcludedFilter(s.GetName(), nil, region.GetStoreIds()),
+			scoreGuard,
+		}, cluster)
+		target := s.selectTarget(state, candidates)
+		if target == nil {
+			schedulerCounter.WithLabelValues(s.GetName(), "no-target-store").Inc()
+			continue
+		}
+
+		oldPeer := region.GetStorePeer(sourceID)
+		newPeer := &metapb.Peer{StoreId: target.GetID(), IsLearner: oldPeer.GetIsLearner()}
+		op, err := operator.CreateMovePeerOperator(BalanceLoadType, cluster, region, operator.OpBalance, sourceID, newPeer)
+		if err != nil {
+			log.Debug("fail to create balance load operator", zap.Error(err))
+			continue
+		}
+		op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(s.GetName(), "new-operator"))
+		return op
+	}
+	return nil
+}
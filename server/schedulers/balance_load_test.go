@@ -0,0 +1,145 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/pkg/testutil"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/statistics"
+)
+
+var _ = Suite(&testBalanceLoadSchedulerSuite{})
+
+type testBalanceLoadSchedulerSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	tc     *mockcluster.Cluster
+	sche   schedule.Scheduler
+}
+
+func (s *testBalanceLoadSchedulerSuite) SetUpTest(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	opt := mockoption.NewScheduleOptions()
+	s.tc = mockcluster.NewCluster(opt)
+	var err error
+	s.sche, err = schedule.CreateScheduler(BalanceLoadType, schedule.NewOperatorController(s.ctx, nil, nil), core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(BalanceLoadType, []string{"", ""}))
+	c.Assert(err, IsNil)
+
+	for id := uint64(1); id <= 4; id++ {
+		s.tc.AddRegionStore(id, 10)
+	}
+}
+
+func (s *testBalanceLoadSchedulerSuite) TearDownTest(c *C) {
+	s.cancel()
+}
+
+// heartbeat mocks the stores reporting their loads in a new heartbeat.
+func (s *testBalanceLoadSchedulerSuite) heartbeat() {
+	for _, store := range s.tc.GetStores() {
+		s.tc.PutStore(store.Clone(core.SetLastHeartbeatTS(store.GetLastHeartbeatTS().Add(time.Second))))
+	}
+}
+
+func (s *testBalanceLoadSchedulerSuite) TestCPU(c *C) {
+	// The region 2 serves more reads than the region 1.
+	s.tc.AddLeaderRegionWithReadInfo(1, 1, 512*KB*statistics.RegionHeartBeatReportInterval, 0, statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+	s.tc.AddLeaderRegionWithReadInfo(2, 1, 1024*KB*statistics.RegionHeartBeatReportInterval, 0, statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+	s.tc.AddLeaderRegion(3, 2, 1, 3)
+
+	// store cpu load ratio: 2.07 | 0.69 | 0.55 | 0.69
+	s.tc.UpdateStorageCPUUsage(1, 300)
+	s.tc.UpdateStorageCPUUsage(2, 100)
+	s.tc.UpdateStorageCPUUsage(3, 80)
+	s.tc.UpdateStorageCPUUsage(4, 100)
+
+	// The store is not regarded as overloaded until it stays overloaded in
+	// several heartbeats, no matter how many times it is scheduled. The last
+	// heartbeat is counted only once.
+	for i := 0; i < minOverloadedHeartbeats*2; i++ {
+		c.Assert(s.sche.Schedule(s.tc), IsNil)
+	}
+	for i := 2; i < minOverloadedHeartbeats; i++ {
+		s.heartbeat()
+		c.Assert(s.sche.Schedule(s.tc), IsNil)
+	}
+	s.heartbeat()
+	ops := s.sche.Schedule(s.tc)
+	c.Assert(ops, HasLen, 1)
+	// The leader of the hottest region is transferred.
+	testutil.CheckTransferLeader(c, ops[0], operator.OpBalance, 1, 3)
+	c.Assert(ops[0].RegionID(), Equals, uint64(2))
+
+	// The store is still overloaded above the low load ratio.
+	// store cpu load ratio: 1.32 | 0.87 | 0.83 | 0.98
+	s.tc.UpdateStorageCPUUsage(1, 160)
+	s.tc.UpdateStorageCPUUsage(2, 105)
+	s.tc.UpdateStorageCPUUsage(3, 100)
+	s.tc.UpdateStorageCPUUsage(4, 119)
+	ops = s.sche.Schedule(s.tc)
+	c.Assert(ops, HasLen, 1)
+	testutil.CheckTransferLeader(c, ops[0], operator.OpBalance, 1, 3)
+
+	// The store is back to normal below the low load ratio.
+	// store cpu load ratio: 1.14 | 1.0 | 0.95 | 0.90
+	s.tc.UpdateStorageCPUUsage(1, 120)
+	s.tc.UpdateStorageCPUUsage(2, 105)
+	s.tc.UpdateStorageCPUUsage(3, 100)
+	s.tc.UpdateStorageCPUUsage(4, 95)
+	c.Assert(s.sche.Schedule(s.tc), IsNil)
+
+	// It takes heartbeats to be overloaded again.
+	s.tc.UpdateStorageCPUUsage(1, 300)
+	s.heartbeat()
+	c.Assert(s.sche.Schedule(s.tc), IsNil)
+}
+
+func (s *testBalanceLoadSchedulerSuite) TestDiskWrite(c *C) {
+	// store disk write load ratio: 2.5 | 0.5 | 0.5 | 0.5
+	s.tc.UpdateStorageDiskWriteRate(1, 100)
+	s.tc.UpdateStorageDiskWriteRate(2, 20)
+	s.tc.UpdateStorageDiskWriteRate(3, 20)
+	s.tc.UpdateStorageDiskWriteRate(4, 20)
+
+	for i := 0; i < minOverloadedHeartbeats; i++ {
+		s.heartbeat()
+		s.sche.Schedule(s.tc)
+	}
+	s.tc.AddLeaderRegion(1, 1, 2, 3)
+	s.tc.AddLeaderRegion(2, 1, 2, 3)
+	// The store is overloaded, but none of its peers is hot.
+	c.Assert(s.sche.Schedule(s.tc), IsNil)
+
+	s.tc.AddLeaderRegionWithWriteInfo(1, 1, 512*KB*statistics.RegionHeartBeatReportInterval, 0, statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+	s.tc.AddLeaderRegionWithWriteInfo(2, 1, 1024*KB*statistics.RegionHeartBeatReportInterval, 0, statistics.RegionHeartBeatReportInterval, []uint64{2, 3})
+	// The peer of the hottest region is moved to the only store which has no
+	// peer of the region.
+	ops := s.sche.Schedule(s.tc)
+	c.Assert(ops, HasLen, 1)
+	testutil.CheckTransferPeerWithLeaderTransfer(c, ops[0], operator.OpBalance, 1, 4)
+	c.Assert(ops[0].RegionID(), Equals, uint64(2))
+
+	// No store can receive more writes.
+	s.tc.UpdateStorageDiskWriteRate(4, 60)
+	c.Assert(s.sche.Schedule(s.tc), IsNil)
+}
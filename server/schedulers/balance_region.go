@@ -75,6 +75,7 @@ type balanceRegionScheduler struct {
 	opController *schedule.OperatorController
 	filters      []filter.Filter
 	counter      *prometheus.CounterVec
+	diagnostic   *schedule.DiagnosticRecorder
 }
 
 // newBalanceRegionScheduler creates a scheduler that tends to keep regions on
@@ -90,7 +91,8 @@ func newBalanceRegionScheduler(opController *schedule.OperatorController, conf *
 	for _, setOption := range opts {
 		setOption(scheduler)
 	}
-	scheduler.filters = []filter.Filter{filter.StoreStateFilter{ActionScope: scheduler.GetName(), MoveRegion: true}}
+	scheduler.filters = []filter.Filter{filter.StoreStateFilter{ActionScope: scheduler.GetName(), MoveRegion: true, OpKind: operator.OpBalance.String()}}
+	scheduler.diagnostic = schedule.NewDiagnosticRecorder(scheduler.GetName())
 	return scheduler
 }
 
@@ -127,10 +129,21 @@ func (s *balanceRegionScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return s.opController.OperatorCount(operator.OpRegion) < cluster.GetRegionScheduleLimit()
 }
 
-func (s *balanceRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+func (s *balanceRegionScheduler) GetDiagnosticRecorder() *schedule.DiagnosticRecorder {
+	return s.diagnostic
+}
+
+func (s *balanceRegionScheduler) Schedule(cluster opt.Cluster) (ops []*operator.Operator) {
 	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	s.diagnostic.Begin()
+	defer func() { s.diagnostic.End(ops) }()
 	stores := cluster.GetStores()
-	stores = filter.SelectSourceStores(stores, s.filters, cluster)
+	stores = filter.SelectSourceStores(stores, s.diagnostic.WrapFilters(s.filters), cluster)
+	s.diagnostic.RecordSourceStores(stores)
+	weights := getRegionWeights(cluster)
+	for i := range stores {
+		stores[i] = withRegionWeight(stores[i], weights)
+	}
 	opInfluence := s.opController.GetOpInfluence(cluster)
 	kind := core.NewScheduleKind(core.RegionKind, core.BySize)
 	sort.Slice(stores, func(i, j int) bool {
@@ -145,21 +158,22 @@ func (s *balanceRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 		for i := 0; i < balanceRegionRetryLimit; i++ {
 			// Priority pick the region that has a pending peer.
 			// Pending region may means the disk is overload, remove the pending region firstly.
-			region := cluster.RandPendingRegion(sourceID, s.conf.Ranges, opt.HealthAllowPending(cluster), opt.ReplicatedRegion(cluster))
+			region := cluster.RandPendingRegion(sourceID, s.conf.Ranges, opt.HealthAllowPending(cluster), opt.ReplicatedRegion(cluster), opt.ScheduleAllowedRegion(cluster))
 			if region == nil {
 				// Then pick the region that has a follower in the source store.
-				region = cluster.RandFollowerRegion(sourceID, s.conf.Ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster))
+				region = cluster.RandFollowerRegion(sourceID, s.conf.Ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster), opt.ScheduleAllowedRegion(cluster))
 			}
 			if region == nil {
 				// Then pick the region has the leader in the source store.
-				region = cluster.RandLeaderRegion(sourceID, s.conf.Ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster))
+				region = cluster.RandLeaderRegion(sourceID, s.conf.Ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster), opt.ScheduleAllowedRegion(cluster))
 			}
 			if region == nil {
 				// Finally pick learner.
-				region = cluster.RandLearnerRegion(sourceID, s.conf.Ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster))
+				region = cluster.RandLearnerRegion(sourceID, s.conf.Ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster), opt.ScheduleAllowedRegion(cluster))
 			}
 			if region == nil {
 				schedulerCounter.WithLabelValues(s.GetName(), "no-region").Inc()
+				s.diagnostic.RecordEvent("no-region")
 				continue
 			}
 			log.Debug("select region", zap.String("scheduler", s.GetName()), zap.Uint64("region-id", region.GetID()))
@@ -168,11 +182,12 @@ func (s *balanceRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 			if cluster.IsRegionHot(region) {
 				log.Debug("region is hot", zap.String("scheduler", s.GetName()), zap.Uint64("region-id", region.GetID()))
 				schedulerCounter.WithLabelValues(s.GetName(), "region-hot").Inc()
+				s.diagnostic.RecordEvent("region-hot")
 				continue
 			}
 
 			oldPeer := region.GetStorePeer(sourceID)
-			if op := s.transferPeer(cluster, region, oldPeer); op != nil {
+			if op := s.transferPeer(cluster, region, oldPeer, weights); op != nil {
 				op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(s.GetName(), "new-operator"))
 				return []*operator.Operator{op}
 			}
@@ -182,7 +197,7 @@ func (s *balanceRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 }
 
 // transferPeer selects the best store to create a new peer to replace the old peer.
-func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.RegionInfo, oldPeer *metapb.Peer) *operator.Operator {
+func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.RegionInfo, oldPeer *metapb.Peer, weights map[uint64]float64) *operator.Operator {
 	// scoreGuard guarantees that the distinct score will not decrease.
 	stores := cluster.GetRegionStores(region)
 	sourceStoreID := oldPeer.GetStoreId()
@@ -191,21 +206,23 @@ func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.
 		log.Error("failed to get the source store", zap.Uint64("store-id", sourceStoreID))
 		return nil
 	}
+	source = withRegionWeight(source, weights)
 	exclude := make(map[uint64]struct{})
 	excludeFilter := filter.NewExcludedFilter(s.GetName(), nil, exclude)
 	for {
 		var target *core.StoreInfo
 		if cluster.IsPlacementRulesEnabled() {
-			scoreGuard := filter.NewRuleFitFilter(s.GetName(), cluster, region, sourceStoreID)
+			scoreGuard := s.diagnostic.WrapFilter(filter.NewRuleFitFilter(s.GetName(), cluster, region, sourceStoreID))
 			fit := cluster.FitRegion(region)
 			rf := fit.GetRuleFit(oldPeer.GetId())
 			if rf == nil {
 				schedulerCounter.WithLabelValues(s.GetName(), "skip-orphan-peer").Inc()
+				s.diagnostic.RecordEvent("skip-orphan-peer")
 				return nil
 			}
 			target = checker.SelectStoreToReplacePeerByRule(s.GetName(), cluster, region, fit, rf, oldPeer, scoreGuard, excludeFilter)
 		} else {
-			scoreGuard := filter.NewDistinctScoreFilter(s.GetName(), cluster.GetLocationLabels(), stores, source)
+			scoreGuard := s.diagnostic.WrapFilter(filter.NewDistinctScoreFilter(s.GetName(), cluster.GetLocationLabels(), stores, source))
 			replicaChecker := checker.NewReplicaChecker(cluster, s.GetName())
 			storeID, _ := replicaChecker.SelectBestReplacementStore(region, oldPeer, scoreGuard, excludeFilter)
 			if storeID != 0 {
@@ -214,9 +231,11 @@ func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.
 		}
 		if target == nil {
 			schedulerCounter.WithLabelValues(s.GetName(), "no-replacement").Inc()
+			s.diagnostic.RecordEvent("no-replacement")
 			return nil
 		}
 		exclude[target.GetID()] = struct{}{} // exclude next round.
+		target = withRegionWeight(target, weights)
 
 		regionID := region.GetID()
 		sourceID := source.GetID()
@@ -225,8 +244,9 @@ func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.
 
 		opInfluence := s.opController.GetOpInfluence(cluster)
 		kind := core.NewScheduleKind(core.RegionKind, core.BySize)
-		if !shouldBalance(cluster, source, target, region, kind, opInfluence, s.GetName()) {
+		if !shouldBalance(cluster, source, target, region, kind, opInfluence, s.GetName(), s.diagnostic) {
 			schedulerCounter.WithLabelValues(s.GetName(), "skip").Inc()
+			s.diagnostic.RecordEvent("skip")
 			continue
 		}
 
@@ -234,6 +254,7 @@ func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.
 		op, err := operator.CreateMovePeerOperator("balance-region", cluster, region, operator.OpBalance, oldPeer.GetStoreId(), newPeer)
 		if err != nil {
 			schedulerCounter.WithLabelValues(s.GetName(), "create-operator-fail").Inc()
+			s.diagnostic.RecordEvent("create-operator-fail")
 			return nil
 		}
 		sourceLabel := strconv.FormatUint(sourceID, 10)
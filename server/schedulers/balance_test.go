@@ -30,7 +30,9 @@ import (
 	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
 )
 
 func newTestReplication(mso *mockoption.ScheduleOptions, maxReplicas int, locationLabels ...string) {
@@ -121,7 +123,7 @@ func (s *testBalanceSuite) TestShouldBalance(c *C) {
 		tc.PutRegion(region)
 		tc.LeaderSchedulePolicy = t.kind.String()
 		kind := core.NewScheduleKind(core.LeaderKind, t.kind)
-		c.Assert(shouldBalance(tc, source, target, region, kind, oc.GetOpInfluence(tc), ""), Equals, t.expectedResult)
+		c.Assert(shouldBalance(tc, source, target, region, kind, oc.GetOpInfluence(tc), "", nil), Equals, t.expectedResult)
 	}
 
 	for _, t := range tests {
@@ -133,7 +135,7 @@ func (s *testBalanceSuite) TestShouldBalance(c *C) {
 			region := tc.GetRegion(1).Clone(core.SetApproximateSize(t.regionSize))
 			tc.PutRegion(region)
 			kind := core.NewScheduleKind(core.RegionKind, t.kind)
-			c.Assert(shouldBalance(tc, source, target, region, kind, oc.GetOpInfluence(tc), ""), Equals, t.expectedResult)
+			c.Assert(shouldBalance(tc, source, target, region, kind, oc.GetOpInfluence(tc), "", nil), Equals, t.expectedResult)
 		}
 	}
 }
@@ -337,6 +339,82 @@ func (s *testBalanceLeaderSchedulerSuite) TestBalanceFilter(c *C) {
 	c.Assert(s.schedule(), HasLen, 0)
 }
 
+func (s *testBalanceLeaderSchedulerSuite) TestDiagnostic(c *C) {
+	// Stores:     1    2    3    4
+	// Leaders:    10   10   10   12
+	// Region1:    F    F    F    L
+	s.tc.AddLeaderStore(1, 10)
+	s.tc.AddLeaderStore(2, 10)
+	s.tc.AddLeaderStore(3, 10)
+	s.tc.AddLeaderStore(4, 12)
+	s.tc.AddLeaderRegion(1, 4, 1, 2, 3)
+	s.tc.SetStoreDown(1)
+
+	recorder := s.lb.(schedule.DiagnosableScheduler).GetDiagnosticRecorder()
+	c.Assert(recorder.GetLastResult(), IsNil)
+	c.Assert(s.schedule(), HasLen, 0)
+	result := recorder.GetLastResult()
+	c.Assert(result, NotNil)
+	c.Assert(result.Name, Equals, s.lb.GetName())
+	c.Assert(result.OperatorCount, Equals, 0)
+	c.Assert(result.SourceStores, HasLen, 3)
+	for _, id := range result.SourceStores {
+		c.Assert(id, Not(Equals), uint64(1))
+	}
+	// Store 1 is down, so it is rejected as both source and target.
+	var actions []string
+	for _, f := range result.Filters {
+		c.Assert(f.StoreID, Equals, uint64(1))
+		c.Assert(f.Type, Equals, "store-state-filter")
+		actions = append(actions, f.Action)
+	}
+	c.Assert(actions, DeepEquals, []string{"source", "target"})
+	// The leader counts are within the tolerant resource.
+	c.Assert(len(result.Scores), Greater, 0)
+	for _, score := range result.Scores {
+		c.Assert(score.ShouldBalance, IsFalse)
+		c.Assert(score.RegionID, Equals, uint64(1))
+		c.Assert(score.SourceStoreID, Equals, uint64(4))
+	}
+	c.Assert(result.Events["skip"], Greater, 0)
+
+	// A new round overrides the last result.
+	s.tc.UpdateLeaderCount(4, 16)
+	c.Assert(s.schedule(), HasLen, 1)
+	result = recorder.GetLastResult()
+	c.Assert(result.OperatorCount, Equals, 1)
+	c.Assert(result.Scores[len(result.Scores)-1].ShouldBalance, IsTrue)
+}
+
+func (s *testBalanceLeaderSchedulerSuite) TestLeaderPreference(c *C) {
+	s.tc.EnablePlacementRules = true
+	s.tc.SetRule(&placement.Rule{
+		GroupID: "pd",
+		ID:      "default",
+		Role:    placement.Voter,
+		Count:   3,
+		LeaderPreferences: [][]placement.LabelConstraint{
+			{{Key: "zone", Op: "in", Values: []string{"z1"}}},
+		},
+	})
+	// Stores:     1    2    3    4
+	// Zone:       z1   z2   z2   z1
+	// Leaders:    16   0    0    0
+	// Region1:    L    F    F
+	s.tc.AddLabelsStore(1, 16, map[string]string{"zone": "z1"})
+	s.tc.AddLabelsStore(2, 0, map[string]string{"zone": "z2"})
+	s.tc.AddLabelsStore(3, 0, map[string]string{"zone": "z2"})
+	s.tc.UpdateLeaderCount(1, 16)
+	s.tc.AddLeaderRegion(1, 1, 2, 3)
+	// Leaders should not be moved to less preferred stores.
+	c.Check(s.schedule(), IsNil)
+
+	// Region1:    L    F         F
+	s.tc.AddLabelsStore(4, 0, map[string]string{"zone": "z1"})
+	s.tc.AddLeaderRegion(1, 1, 2, 4)
+	testutil.CheckTransferLeader(c, s.schedule()[0], operator.OpBalance, 1, 4)
+}
+
 func (s *testBalanceLeaderSchedulerSuite) TestLeaderWeight(c *C) {
 	// Stores:     1       2       3       4
 	// Leaders:    10      10      10      10
@@ -556,6 +634,13 @@ func (s *testBalanceRegionSchedulerSuite) TestBalance(c *C) {
 
 	opt.SetMaxReplicas(1)
 	c.Assert(sb.Schedule(tc), NotNil)
+
+	// Regions labeled to deny scheduling are skipped.
+	rule := &labeler.LabelRule{ID: "test", Labels: map[string]string{labeler.ScheduleLabel: labeler.DenyValue}}
+	c.Assert(tc.GetRegionLabeler().SetLabelRule(rule), IsNil)
+	c.Assert(sb.Schedule(tc), IsNil)
+	c.Assert(tc.GetRegionLabeler().DeleteLabelRule("test"), IsNil)
+	c.Assert(sb.Schedule(tc), NotNil)
 }
 
 func (s *testBalanceRegionSchedulerSuite) TestReplicas3(c *C) {
@@ -742,6 +827,37 @@ func (s *testBalanceRegionSchedulerSuite) TestStoreWeight(c *C) {
 	testutil.CheckTransferPeer(c, sb.Schedule(tc)[0], operator.OpBalance, 1, 3)
 }
 
+func (s *testBalanceRegionSchedulerSuite) TestCapacityWeight(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(s.ctx, nil, nil)
+
+	sb, err := schedule.CreateScheduler(BalanceRegionType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(BalanceRegionType, []string{"", ""}))
+	c.Assert(err, IsNil)
+	opt.SetMaxReplicas(1)
+
+	// Store 1 and 2 are 4 times smaller than store 3 and 4.
+	tc.AddRegionStore(1, 30)
+	tc.AddRegionStore(2, 35)
+	tc.AddLabelsStore(3, 60, map[string]string{core.StoreClassLabel: "hdd"})
+	tc.AddRegionStore(4, 70)
+	tc.UpdateStorageCapacity(3, 4000*(1<<20))
+	tc.UpdateStorageCapacity(4, 4000*(1<<20))
+	tc.AddLeaderRegion(1, 1)
+	tc.AddLeaderRegion(2, 3)
+
+	// The region weights are equal in manual mode.
+	testutil.CheckTransferPeer(c, sb.Schedule(tc)[0], operator.OpBalance, 3, 1)
+
+	// The region scores are 750 | 875 | 375 | 437.5 in capacity mode.
+	opt.RegionWeightMode = core.CapacityRegionWeight.String()
+	testutil.CheckTransferPeer(c, sb.Schedule(tc)[0], operator.OpBalance, 1, 3)
+
+	// The region score of store 3 becomes 1500 with the weight of its class.
+	opt.StoreClassWeights = map[string]float64{"hdd": 0.25}
+	testutil.CheckTransferPeer(c, sb.Schedule(tc)[0], operator.OpBalance, 3, 1)
+}
+
 func (s *testBalanceRegionSchedulerSuite) TestReplacePendingRegion(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	tc := mockcluster.NewCluster(opt)
@@ -0,0 +1,437 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/extscheduler"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
+	"github.com/pingcap/pd/v4/pkg/logutil"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+)
+
+const (
+	// ExternalName is external scheduler name.
+	ExternalName = "external-scheduler"
+	// ExternalType is external scheduler type.
+	ExternalType = "external"
+	// externalScheduleTimeout is the timeout of a request to the external
+	// scheduler, including streaming the snapshot.
+	externalScheduleTimeout = 30 * time.Second
+	// externalRequestInterval is the interval of the requests to the external
+	// scheduler, which limits the rate of streaming the snapshots.
+	externalRequestInterval = 10 * time.Second
+	// externalSnapshotBatchSize is the number of regions in a snapshot chunk.
+	externalSnapshotBatchSize = 1024
+)
+
+func init() {
+	// args: [name, address].
+	schedule.RegisterSliceDecoderBuilder(ExternalType, func(args []string) schedule.ConfigDecoder {
+		return func(v interface{}) error {
+			if len(args) != 2 {
+				return errors.New("should specify the name and the address")
+			}
+			conf, ok := v.(*externalSchedulerConfig)
+			if !ok {
+				return ErrScheduleConfigNotExist
+			}
+			conf.Name = args[0]
+			conf.Address = args[1]
+			return nil
+		}
+	})
+
+	schedule.RegisterScheduler(ExternalType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
+		conf := &externalSchedulerConfig{}
+		if err := decoder(conf); err != nil {
+			return nil, err
+		}
+		if len(conf.Name) == 0 || len(conf.Address) == 0 {
+			return nil, errors.New("the name or the address is invalid")
+		}
+		return newExternalScheduler(opController, conf), nil
+	})
+}
+
+type externalSchedulerConfig struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type externalScheduler struct {
+	*BaseScheduler
+	conf    *externalSchedulerConfig
+	handler http.Handler
+
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	client    *extscheduler.Client
+	cancel    context.CancelFunc
+	proposals []*extscheduler.OperatorProposal
+}
+
+// newExternalScheduler creates a scheduler that streams the cluster snapshot
+// to a scheduler running out of the PD process and takes the operators it
+// proposes. The snapshots are streamed in background, and the scheduling
+// rounds only take the proposals received since the last round.
+func newExternalScheduler(opController *schedule.OperatorController, conf *externalSchedulerConfig) schedule.Scheduler {
+	return &externalScheduler{
+		BaseScheduler: NewBaseScheduler(opController),
+		conf:          conf,
+		handler:       newExternalHandler(conf),
+	}
+}
+
+func (s *externalScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *externalScheduler) GetName() string {
+	return fmt.Sprintf("%s-%s", ExternalName, s.conf.Name)
+}
+
+func (s *externalScheduler) GetType() string {
+	return ExternalType
+}
+
+func (s *externalScheduler) EncodeConfig() ([]byte, error) {
+	return schedule.EncodeConfig(s.conf)
+}
+
+func (s *externalScheduler) Prepare(cluster opt.Cluster) error {
+	ctx, cancel := context.WithCancel(s.OpController.Ctx())
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go s.requestLoop(ctx, cluster)
+	return nil
+}
+
+func (s *externalScheduler) Cleanup(cluster opt.Cluster) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+	s.proposals = nil
+}
+
+func (s *externalScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
+	return s.allowKind(cluster, operator.OpLeader, 0) || s.allowKind(cluster, operator.OpRegion, 0)
+}
+
+// allowKind checks the schedule limit of the leader or region operators,
+// counting the ones created in the current round.
+func (s *externalScheduler) allowKind(cluster opt.Cluster, kind operator.OpKind, created uint64) bool {
+	if kind == operator.OpLeader {
+		return s.OpController.OperatorCount(operator.OpLeader)+created < cluster.GetLeaderScheduleLimit()
+	}
+	return s.OpController.OperatorCount(operator.OpRegion)+created < cluster.GetRegionScheduleLimit()
+}
+
+func (s *externalScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	s.mu.Lock()
+	proposals := s.proposals
+	s.proposals = nil
+	s.mu.Unlock()
+	if len(proposals) == 0 {
+		return nil
+	}
+	ops := make([]*operator.Operator, 0, len(proposals))
+	created := make(map[operator.OpKind]uint64)
+	for _, p := range proposals {
+		kind := operator.OpRegion
+		if p.Kind == extscheduler.TransferLeader {
+			kind = operator.OpLeader
+		}
+		if !s.allowKind(cluster, kind, created[kind]) {
+			schedulerCounter.WithLabelValues(s.GetName(), "limit-full").Inc()
+			continue
+		}
+		op, err := s.createOperator(cluster, p)
+		if err != nil {
+			log.Debug("reject the proposal of the external scheduler", zap.String("scheduler", s.GetName()), zap.Reflect("proposal", p), zap.Error(err))
+			schedulerCounter.WithLabelValues(s.GetName(), "reject-proposal").Inc()
+			continue
+		}
+		op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(s.GetName(), "new-operator"))
+		ops = append(ops, op)
+		created[kind]++
+	}
+	return ops
+}
+
+func (s *externalScheduler) getClient() (*extscheduler.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil {
+		cc, err := grpcutil.GetClientConn(context.Background(), s.conf.Address, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.client = extscheduler.NewClient(cc)
+	}
+	return s.client, nil
+}
+
+// requestLoop requests the external scheduler periodically until the context
+// is canceled. The proposals of a request replace the ones not taken yet,
+// which are based on an older snapshot.
+func (s *externalScheduler) requestLoop(ctx context.Context, cluster opt.Cluster) {
+	defer logutil.LogPanic()
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(externalRequestInterval)
+	defer ticker.Stop()
+	for {
+		proposals, err := s.requestProposals(ctx, cluster)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warn("failed to request the external scheduler", zap.String("scheduler", s.GetName()), zap.String("address", s.conf.Address), zap.Error(err))
+			schedulerCounter.WithLabelValues(s.GetName(), "request-fail").Inc()
+		} else {
+			s.mu.Lock()
+			s.proposals = proposals
+			s.mu.Unlock()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// requestProposals streams the snapshot of the cluster to the external
+// scheduler and returns the operators it proposes.
+func (s *externalScheduler) requestProposals(ctx context.Context, cluster opt.Cluster) ([]*extscheduler.OperatorProposal, error) {
+	client, err := s.getClient()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, externalScheduleTimeout)
+	defer cancel()
+	stream, err := client.Schedule(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	header := &extscheduler.SnapshotHeader{
+		Scheduler:      s.GetName(),
+		MaxReplicas:    cluster.GetMaxReplicas(),
+		LocationLabels: cluster.GetLocationLabels(),
+	}
+	for _, store := range cluster.GetStores() {
+		header.Stores = append(header.Stores, &extscheduler.StoreSnapshot{
+			Store:       store.GetMeta(),
+			Stats:       store.GetStoreStats(),
+			LeaderCount: store.GetLeaderCount(),
+			RegionCount: store.GetRegionCount(),
+			LeaderSize:  store.GetLeaderSize(),
+			RegionSize:  store.GetRegionSize(),
+			Blocked:     store.IsBlocked(),
+		})
+	}
+	if err := stream.Send(&extscheduler.SnapshotChunk{Header: header}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var startKey []byte
+	for {
+		regions := cluster.ScanRegions(startKey, nil, externalSnapshotBatchSize)
+		if len(regions) == 0 {
+			break
+		}
+		chunk := &extscheduler.SnapshotChunk{Regions: make([]*extscheduler.RegionSnapshot, 0, len(regions))}
+		for _, region := range regions {
+			chunk.Regions = append(chunk.Regions, &extscheduler.RegionSnapshot{
+				Region:          region.GetMeta(),
+				Leader:          region.GetLeader(),
+				DownPeers:       region.GetDownPeers(),
+				PendingPeers:    region.GetPendingPeers(),
+				ApproximateSize: region.GetApproximateSize(),
+				ApproximateKeys: region.GetApproximateKeys(),
+				WrittenBytes:    region.GetBytesWritten(),
+				WrittenKeys:     region.GetKeysWritten(),
+				ReadBytes:       region.GetBytesRead(),
+				ReadKeys:        region.GetKeysRead(),
+			})
+		}
+		if err := stream.Send(chunk); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		startKey = regions[len(regions)-1].GetEndKey()
+		if len(regions) < externalSnapshotBatchSize || len(startKey) == 0 {
+			break
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return resp.Operators, nil
+}
+
+// createOperator checks the proposal against the current state of the region
+// and the stores, and creates the operator.
+func (s *externalScheduler) createOperator(cluster opt.Cluster, p *extscheduler.OperatorProposal) (*operator.Operator, error) {
+	region := cluster.GetRegion(p.RegionID)
+	if region == nil {
+		return nil, errors.Errorf("region %d not found", p.RegionID)
+	}
+	epoch := region.GetRegionEpoch()
+	if p.RegionEpoch.GetVersion() != epoch.GetVersion() || p.RegionEpoch.GetConfVer() != epoch.GetConfVer() {
+		return nil, errors.Errorf("the epoch of region %d is stale", p.RegionID)
+	}
+	if err := s.checkProposal(cluster, region, p); err != nil {
+		return nil, err
+	}
+	desc := p.Desc
+	if len(desc) == 0 {
+		desc = s.GetName()
+	}
+
+	var op *operator.Operator
+	var err error
+	switch p.Kind {
+	case extscheduler.TransferLeader:
+		op, err = operator.CreateTransferLeaderOperator(desc, cluster, region, region.GetLeader().GetStoreId(), p.TargetStoreID, operator.OpLeader)
+	case extscheduler.MovePeer:
+		oldPeer := region.GetStorePeer(p.SourceStoreID)
+		if oldPeer == nil {
+			return nil, errors.Errorf("region %d has no peer in store %d", p.RegionID, p.SourceStoreID)
+		}
+		newPeer := &metapb.Peer{StoreId: p.TargetStoreID, IsLearner: oldPeer.GetIsLearner()}
+		op, err = operator.CreateMovePeerOperator(desc, cluster, region, operator.OpRegion, p.SourceStoreID, newPeer)
+	case extscheduler.AddPeer:
+		op, err = operator.CreateAddPeerOperator(desc, cluster, region, &metapb.Peer{StoreId: p.TargetStoreID}, operator.OpRegion)
+	case extscheduler.RemovePeer:
+		op, err = operator.CreateRemovePeerOperator(desc, cluster, operator.OpRegion, region, p.SourceStoreID)
+	default:
+		return nil, errors.Errorf("unknown proposal kind %s", p.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.HighPriority {
+		op.SetPriorityLevel(core.HighPriority)
+	}
+	return op, nil
+}
+
+// checkProposal rejects the proposal if the built-in schedulers would filter
+// out its target store, or if it makes the region fit the placement rules
+// worse.
+func (s *externalScheduler) checkProposal(cluster opt.Cluster, region *core.RegionInfo, p *extscheduler.OperatorProposal) error {
+	if p.Kind == extscheduler.RemovePeer {
+		if cluster.IsPlacementRulesEnabled() {
+			return s.checkRuleFit(cluster, region, region.Clone(core.WithRemoveStorePeer(p.SourceStoreID)))
+		}
+		if len(region.GetPeers()) <= cluster.GetMaxReplicas() {
+			return errors.Errorf("region %d has no extra peer to remove", region.GetID())
+		}
+		return nil
+	}
+
+	target := cluster.GetStore(p.TargetStoreID)
+	if target == nil {
+		return errors.Errorf("store %d not found", p.TargetStoreID)
+	}
+	filters := []filter.Filter{
+		filter.NewStateFilter(s.GetName()),
+		filter.NewHealthFilter(s.GetName()),
+	}
+	switch p.Kind {
+	case extscheduler.TransferLeader:
+		source := cluster.GetStore(region.GetLeader().GetStoreId())
+		if cluster.IsPlacementRulesEnabled() && source != nil {
+			filters = append(filters, filter.NewLeaderPreferenceFilter(s.GetName(), cluster, region, source))
+		}
+	case extscheduler.MovePeer:
+		filters = append(filters, filter.NewStorageThresholdFilter(s.GetName()))
+		source := cluster.GetStore(p.SourceStoreID)
+		if cluster.IsPlacementRulesEnabled() {
+			filters = append(filters, filter.NewRuleFitFilter(s.GetName(), cluster, region, p.SourceStoreID))
+		} else if source != nil {
+			filters = append(filters, filter.NewDistinctScoreFilter(s.GetName(), cluster.GetLocationLabels(), cluster.GetRegionStores(region), source))
+		}
+	case extscheduler.AddPeer:
+		filters = append(filters, filter.NewStorageThresholdFilter(s.GetName()))
+	}
+	if filter.Target(cluster, target, filters) {
+		return errors.Errorf("store %d is filtered out as the target", p.TargetStoreID)
+	}
+	if p.Kind == extscheduler.AddPeer && cluster.IsPlacementRulesEnabled() {
+		return s.checkRuleFit(cluster, region, region.Clone(core.WithAddPeer(&metapb.Peer{StoreId: p.TargetStoreID})))
+	}
+	return nil
+}
+
+func (s *externalScheduler) checkRuleFit(cluster opt.Cluster, region, newRegion *core.RegionInfo) error {
+	if placement.CompareRegionFit(cluster.FitRegion(region), cluster.FitRegion(newRegion)) > 0 {
+		return errors.Errorf("region %d fits the placement rules worse", region.GetID())
+	}
+	return nil
+}
+
+type externalHandler struct {
+	rd     *render.Render
+	config *externalSchedulerConfig
+}
+
+func (handler *externalHandler) ListConfig(w http.ResponseWriter, r *http.Request) {
+	handler.rd.JSON(w, http.StatusOK, handler.config)
+}
+
+func newExternalHandler(config *externalSchedulerConfig) http.Handler {
+	h := &externalHandler{
+		config: config,
+		rd:     render.New(render.Options{IndentJSON: true}),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/list", h.ListConfig).Methods("GET")
+	return router
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/extscheduler"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/pkg/testutil"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"google.golang.org/grpc"
+)
+
+var _ = Suite(&testExternalSchedulerSuite{})
+
+type testExternalSchedulerSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	server *grpc.Server
+	remote *mockExternalScheduler
+	addr   string
+}
+
+type mockExternalScheduler struct {
+	sync.Mutex
+	snapshot  *extscheduler.Snapshot
+	proposals []*extscheduler.OperatorProposal
+}
+
+func (m *mockExternalScheduler) Schedule(ctx context.Context, snapshot *extscheduler.Snapshot) ([]*extscheduler.OperatorProposal, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.snapshot = snapshot
+	return m.proposals, nil
+}
+
+func (s *testExternalSchedulerSuite) SetUpTest(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, IsNil)
+	s.remote = &mockExternalScheduler{}
+	s.server = grpc.NewServer()
+	extscheduler.RegisterScheduler(s.server, s.remote)
+	go s.server.Serve(lis)
+	s.addr = "http://" + lis.Addr().String()
+}
+
+func (s *testExternalSchedulerSuite) TearDownTest(c *C) {
+	s.server.Stop()
+	s.cancel()
+}
+
+func (s *testExternalSchedulerSuite) TestSchedule(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(s.ctx, nil, nil)
+	sche, err := schedule.CreateScheduler(ExternalType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(ExternalType, []string{"test", s.addr}))
+	c.Assert(err, IsNil)
+	c.Assert(sche.GetName(), Equals, "external-scheduler-test")
+	defer sche.Cleanup(tc)
+
+	for id := uint64(1); id <= 4; id++ {
+		tc.AddLeaderStore(id, 0)
+	}
+	tc.AddLeaderRegion(1, 1, 2, 3)
+	tc.AddLeaderRegion(2, 2, 1, 3)
+
+	s.remote.proposals = []*extscheduler.OperatorProposal{
+		{RegionID: 1, Kind: extscheduler.TransferLeader, TargetStoreID: 2, HighPriority: true},
+		// The proposal is rejected since the epoch is stale.
+		{RegionID: 2, RegionEpoch: &metapb.RegionEpoch{Version: 100}, Kind: extscheduler.TransferLeader, TargetStoreID: 3},
+		// The proposal is rejected since the region does not exist.
+		{RegionID: 3, Kind: extscheduler.TransferLeader, TargetStoreID: 3},
+		{RegionID: 2, Kind: extscheduler.MovePeer, SourceStoreID: 3, TargetStoreID: 4},
+	}
+	// The snapshot is streamed in background.
+	c.Assert(sche.Schedule(tc), IsNil)
+	c.Assert(sche.Prepare(tc), IsNil)
+	var ops []*operator.Operator
+	testutil.WaitUntil(c, func(c *C) bool {
+		ops = sche.Schedule(tc)
+		return len(ops) > 0
+	})
+	c.Assert(ops, HasLen, 2)
+	testutil.CheckTransferLeader(c, ops[0], operator.OpLeader, 1, 2)
+	c.Assert(ops[0].GetPriorityLevel(), Equals, core.HighPriority)
+	testutil.CheckTransferPeer(c, ops[1], operator.OpRegion, 3, 4)
+
+	// The whole cluster is streamed to the external scheduler.
+	s.remote.Lock()
+	snapshot := s.remote.snapshot
+	s.remote.Unlock()
+	c.Assert(snapshot.Scheduler, Equals, "external-scheduler-test")
+	c.Assert(snapshot.Stores, HasLen, 4)
+	c.Assert(snapshot.Regions, HasLen, 2)
+	c.Assert(snapshot.Regions[0].Leader.GetStoreId(), Equals, uint64(1))
+	c.Assert(snapshot.Regions[1].Region.GetPeers(), HasLen, 3)
+
+	// The proposals are taken only once.
+	c.Assert(sche.Schedule(tc), IsNil)
+}
+
+func (s *testExternalSchedulerSuite) TestCheckProposal(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.RegionScheduleLimit = 1
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(s.ctx, nil, nil)
+	sche, err := schedule.CreateScheduler(ExternalType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(ExternalType, []string{"test", s.addr}))
+	c.Assert(err, IsNil)
+	defer sche.Cleanup(tc)
+
+	for id := uint64(1); id <= 5; id++ {
+		tc.AddLeaderStore(id, 0)
+	}
+	tc.SetStoreOffline(5)
+	tc.AddLeaderRegion(1, 1, 2, 3)
+	tc.AddLeaderRegion(2, 2, 1, 3)
+
+	s.remote.proposals = []*extscheduler.OperatorProposal{
+		// The proposal is rejected since the target store is offline.
+		{RegionID: 1, Kind: extscheduler.MovePeer, SourceStoreID: 3, TargetStoreID: 5},
+		// The proposal is rejected since the region has no extra peer.
+		{RegionID: 2, Kind: extscheduler.RemovePeer, SourceStoreID: 3},
+		{RegionID: 2, Kind: extscheduler.MovePeer, SourceStoreID: 3, TargetStoreID: 4},
+		// The proposal is rejected since the region schedule limit is full,
+		// while the leader schedule limit is not.
+		{RegionID: 1, Kind: extscheduler.AddPeer, TargetStoreID: 4},
+		{RegionID: 1, Kind: extscheduler.TransferLeader, TargetStoreID: 2},
+	}
+	c.Assert(sche.Prepare(tc), IsNil)
+	var ops []*operator.Operator
+	testutil.WaitUntil(c, func(c *C) bool {
+		ops = sche.Schedule(tc)
+		return len(ops) > 0
+	})
+	c.Assert(ops, HasLen, 2)
+	testutil.CheckTransferPeer(c, ops[0], operator.OpRegion, 3, 4)
+	testutil.CheckTransferLeader(c, ops[1], operator.OpLeader, 1, 2)
+
+	// The proposals breaking the placement rules are rejected.
+	opt.EnablePlacementRules = true
+	es := sche.(*externalScheduler)
+	c.Assert(es.checkProposal(tc, tc.GetRegion(1), &extscheduler.OperatorProposal{RegionID: 1, Kind: extscheduler.RemovePeer, SourceStoreID: 3}), NotNil)
+	c.Assert(es.checkProposal(tc, tc.GetRegion(1), &extscheduler.OperatorProposal{RegionID: 1, Kind: extscheduler.AddPeer, TargetStoreID: 4}), NotNil)
+	c.Assert(es.checkProposal(tc, tc.GetRegion(1), &extscheduler.OperatorProposal{RegionID: 1, Kind: extscheduler.MovePeer, SourceStoreID: 3, TargetStoreID: 4}), IsNil)
+}
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"net/http"
 	"sort"
 	"sync"
 	"time"
@@ -39,14 +40,22 @@ func init() {
 		}
 	})
 	schedule.RegisterScheduler(HotRegionType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
-		return newHotScheduler(opController), nil
+		conf := initHotRegionScheduleConfig()
+		conf.storage = storage
+		if err := decoder(conf); err != nil {
+			return nil, err
+		}
+		if err := conf.validate(); err != nil {
+			return nil, err
+		}
+		return newHotScheduler(opController, conf), nil
 	})
 	// FIXME: remove this two schedule after the balance test move in schedulers package
 	schedule.RegisterScheduler(HotWriteRegionType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
-		return newHotWriteScheduler(opController), nil
+		return newHotWriteScheduler(opController, initHotRegionScheduleConfig()), nil
 	})
 	schedule.RegisterScheduler(HotReadRegionType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
-		return newHotReadScheduler(opController), nil
+		return newHotReadScheduler(opController, initHotRegionScheduleConfig()), nil
 	})
 }
 
@@ -63,23 +72,7 @@ const (
 
 	hotRegionLimitFactor = 0.75
 
-	maxPeerNum = 1000
-
-	maxZombieDur time.Duration = statistics.StoreHeartBeatReportInterval * time.Second
-
 	minRegionScheduleInterval time.Duration = statistics.StoreHeartBeatReportInterval * time.Second
-
-	minHotByteRate = 100
-	minHotKeyRate  = 10
-
-	// rank step ratio decide the step when calculate rank
-	// step = max current * rank step ratio
-	byteRateRankStepRatio = 0.05
-	keyRateRankStepRatio  = 0.05
-	countRankStepRatio    = 0.1
-
-	greatDecRatio = 0.95
-	minorDecRatio = 0.99
 )
 
 type hotScheduler struct {
@@ -90,6 +83,9 @@ type hotScheduler struct {
 	peerLimit   uint64
 	types       []rwType
 	r           *rand.Rand
+	conf        *hotRegionSchedulerConfig
+	handler     http.Handler
+	diagnostic  *schedule.DiagnosticRecorder
 
 	// states across multiple `Schedule` calls
 	pendings       [resourceTypeLen]map[*pendingInfluence]struct{}
@@ -100,7 +96,7 @@ type hotScheduler struct {
 	pendingSums [resourceTypeLen]map[uint64]Influence
 }
 
-func newHotScheduler(opController *schedule.OperatorController) *hotScheduler {
+func newHotScheduler(opController *schedule.OperatorController, conf *hotRegionSchedulerConfig) *hotScheduler {
 	base := NewBaseScheduler(opController)
 	ret := &hotScheduler{
 		name:           HotRegionName,
@@ -109,8 +105,11 @@ func newHotScheduler(opController *schedule.OperatorController) *hotScheduler {
 		peerLimit:      1,
 		types:          []rwType{write, read},
 		r:              rand.New(rand.NewSource(time.Now().UnixNano())),
+		conf:           conf,
+		handler:        newHotRegionHandler(conf),
 		regionPendings: make(map[uint64][2]*operator.Operator),
 	}
+	ret.diagnostic = schedule.NewDiagnosticRecorder(ret.GetName())
 	for ty := resourceType(0); ty < resourceTypeLen; ty++ {
 		ret.pendings[ty] = map[*pendingInfluence]struct{}{}
 		ret.stLoadInfos[ty] = map[uint64]*storeLoadDetail{}
@@ -118,15 +117,15 @@ func newHotScheduler(opController *schedule.OperatorController) *hotScheduler {
 	return ret
 }
 
-func newHotReadScheduler(opController *schedule.OperatorController) *hotScheduler {
-	ret := newHotScheduler(opController)
+func newHotReadScheduler(opController *schedule.OperatorController, conf *hotRegionSchedulerConfig) *hotScheduler {
+	ret := newHotScheduler(opController, conf)
 	ret.name = ""
 	ret.types = []rwType{read}
 	return ret
 }
 
-func newHotWriteScheduler(opController *schedule.OperatorController) *hotScheduler {
-	ret := newHotScheduler(opController)
+func newHotWriteScheduler(opController *schedule.OperatorController, conf *hotRegionSchedulerConfig) *hotScheduler {
+	ret := newHotScheduler(opController, conf)
 	ret.name = ""
 	ret.types = []rwType{write}
 	return ret
@@ -140,6 +139,16 @@ func (h *hotScheduler) GetType() string {
 	return HotRegionType
 }
 
+func (h *hotScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.handler.ServeHTTP(w, r)
+}
+
+func (h *hotScheduler) EncodeConfig() ([]byte, error) {
+	h.conf.RLock()
+	defer h.conf.RUnlock()
+	return schedule.EncodeConfig(h.conf)
+}
+
 func (h *hotScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return h.allowBalanceLeader(cluster) || h.allowBalanceRegion(cluster)
 }
@@ -153,8 +162,15 @@ func (h *hotScheduler) allowBalanceRegion(cluster opt.Cluster) bool {
 	return h.OpController.OperatorCount(operator.OpHotRegion) < minUint64(h.peerLimit, cluster.GetHotRegionScheduleLimit())
 }
 
-func (h *hotScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+// GetDiagnosticRecorder returns the recorder of the decision trace.
+func (h *hotScheduler) GetDiagnosticRecorder() *schedule.DiagnosticRecorder {
+	return h.diagnostic
+}
+
+func (h *hotScheduler) Schedule(cluster opt.Cluster) (ops []*operator.Operator) {
 	schedulerCounter.WithLabelValues(h.GetName(), "schedule").Inc()
+	h.diagnostic.Begin()
+	defer func() { h.diagnostic.End(ops) }()
 	return h.dispatch(h.types[h.r.Int()%len(h.types)], cluster)
 }
 
@@ -178,7 +194,7 @@ func (h *hotScheduler) prepareForBalance(cluster opt.Cluster) {
 
 	storesStat := cluster.GetStoresStats()
 
-	minHotDegree := cluster.GetHotRegionCacheHitsThreshold()
+	minHotDegree := h.conf.getMinHotDegree(cluster)
 	{ // update read statistics
 		regionRead := cluster.RegionReadStats()
 		storeByte := storesStat.GetStoresBytesReadStat()
@@ -218,7 +234,7 @@ func (h *hotScheduler) prepareForBalance(cluster opt.Cluster) {
 
 func (h *hotScheduler) summaryPendingInfluence() {
 	for ty := resourceType(0); ty < resourceTypeLen; ty++ {
-		h.pendingSums[ty] = summaryPendingInfluence(h.pendings[ty], calcPendingWeight)
+		h.pendingSums[ty] = summaryPendingInfluence(h.pendings[ty], h.calcPendingWeight)
 	}
 	h.gcRegionPendings()
 }
@@ -354,6 +370,7 @@ func (h *hotScheduler) balanceHotReadRegions(cluster opt.Cluster) []*operator.Op
 	}
 
 	schedulerCounter.WithLabelValues(h.GetName(), "skip").Inc()
+	h.diagnostic.RecordEvent("skip")
 	return nil
 }
 
@@ -372,6 +389,7 @@ func (h *hotScheduler) balanceHotWriteRegions(cluster opt.Cluster) []*operator.O
 	}
 
 	schedulerCounter.WithLabelValues(h.GetName(), "skip").Inc()
+	h.diagnostic.RecordEvent("skip")
 	return nil
 }
 
@@ -382,6 +400,10 @@ type balanceSolver struct {
 	rwTy         rwType
 	opTy         opType
 
+	// firstPriority and secondPriority are the dimensions to balance.
+	firstPriority  int
+	secondPriority int
+
 	cur *solution
 
 	maxSrc   *storeLoad
@@ -410,6 +432,7 @@ func (bs *balanceSolver) init() {
 	case readLeader:
 		bs.stLoadDetail = bs.sche.stLoadInfos[readLeader]
 	}
+	bs.firstPriority, bs.secondPriority = bs.sche.conf.getPriorities(toResourceType(bs.rwTy, bs.opTy))
 	for _, id := range getUnhealthyStores(bs.cluster) {
 		delete(bs.stLoadDetail, id)
 	}
@@ -428,10 +451,13 @@ func (bs *balanceSolver) init() {
 		maxCur = maxLoad(maxCur, &detail.LoadPred.Current)
 	}
 
+	// rank step ratio decide the step when calculate rank
+	// step = max current * rank step ratio
+	byteRatio, keyRatio, countRatio := bs.sche.conf.getRankStepRatios()
 	bs.rankStep = &storeLoad{
-		ByteRate: maxCur.ByteRate * byteRateRankStepRatio,
-		KeyRate:  maxCur.KeyRate * keyRateRankStepRatio,
-		Count:    maxCur.Count * countRankStepRatio,
+		ByteRate: maxCur.ByteRate * byteRatio,
+		KeyRate:  maxCur.KeyRate * keyRatio,
+		Count:    maxCur.Count * countRatio,
 	}
 }
 
@@ -529,10 +555,18 @@ func (bs *balanceSolver) allowBalance() bool {
 	}
 }
 
+// recordEvent records the event of the solver with its resource type, such as
+// "write-peer-no-region".
+func (bs *balanceSolver) recordEvent(event string) {
+	bs.sche.diagnostic.RecordEvent(toResourceType(bs.rwTy, bs.opTy).String() + "-" + event)
+}
+
 func (bs *balanceSolver) filterSrcStores() map[uint64]*storeLoadDetail {
 	ret := make(map[uint64]*storeLoadDetail)
+	sources := make([]*core.StoreInfo, 0, len(bs.stLoadDetail))
 	for id, detail := range bs.stLoadDetail {
-		if bs.cluster.GetStore(id) == nil {
+		store := bs.cluster.GetStore(id)
+		if store == nil {
 			log.Error("failed to get the source store", zap.Uint64("store-id", id))
 			continue
 		}
@@ -540,6 +574,11 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*storeLoadDetail {
 			continue
 		}
 		ret[id] = detail
+		sources = append(sources, store)
+	}
+	bs.sche.diagnostic.RecordSourceStores(sources)
+	if len(ret) == 0 {
+		bs.recordEvent("no-hot-peer")
 	}
 	return ret
 }
@@ -547,34 +586,35 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*storeLoadDetail {
 func (bs *balanceSolver) filterHotPeers() []*statistics.HotPeerStat {
 	ret := bs.stLoadDetail[bs.cur.srcStoreID].HotPeers
 	// Return at most maxPeerNum peers, to prevent balanceSolver.solve() too slow.
+	maxPeerNum := bs.sche.conf.getMaxPeerNum()
 	if len(ret) <= maxPeerNum {
 		return ret
 	}
 
-	byteSort := make([]*statistics.HotPeerStat, len(ret))
-	copy(byteSort, ret)
-	sort.Slice(byteSort, func(i, j int) bool {
-		return byteSort[i].GetByteRate() > byteSort[j].GetByteRate()
+	firstSort := make([]*statistics.HotPeerStat, len(ret))
+	copy(firstSort, ret)
+	sort.Slice(firstSort, func(i, j int) bool {
+		return peerRate(firstSort[i], bs.firstPriority) > peerRate(firstSort[j], bs.firstPriority)
 	})
-	keySort := make([]*statistics.HotPeerStat, len(ret))
-	copy(keySort, ret)
-	sort.Slice(keySort, func(i, j int) bool {
-		return keySort[i].GetKeyRate() > keySort[j].GetKeyRate()
+	secondSort := make([]*statistics.HotPeerStat, len(ret))
+	copy(secondSort, ret)
+	sort.Slice(secondSort, func(i, j int) bool {
+		return peerRate(secondSort[i], bs.secondPriority) > peerRate(secondSort[j], bs.secondPriority)
 	})
 
 	union := make(map[*statistics.HotPeerStat]struct{}, maxPeerNum)
 	for len(union) < maxPeerNum {
-		for len(byteSort) > 0 {
-			peer := byteSort[0]
-			byteSort = byteSort[1:]
+		for len(firstSort) > 0 {
+			peer := firstSort[0]
+			firstSort = firstSort[1:]
 			if _, ok := union[peer]; !ok {
 				union[peer] = struct{}{}
 				break
 			}
 		}
-		for len(keySort) > 0 {
-			peer := keySort[0]
-			keySort = keySort[1:]
+		for len(secondSort) > 0 {
+			peer := secondSort[0]
+			secondSort = secondSort[1:]
 			if _, ok := union[peer]; !ok {
 				union[peer] = struct{}{}
 				break
@@ -592,27 +632,32 @@ func (bs *balanceSolver) filterHotPeers() []*statistics.HotPeerStat {
 func (bs *balanceSolver) isRegionAvailable(region *core.RegionInfo) bool {
 	if region == nil {
 		schedulerCounter.WithLabelValues(bs.sche.GetName(), "no-region").Inc()
+		bs.recordEvent("no-region")
 		return false
 	}
 
 	if pendings, ok := bs.sche.regionPendings[region.GetID()]; ok {
 		if bs.opTy == transferLeader {
+			bs.recordEvent("region-pending")
 			return false
 		}
 		if pendings[movePeer] != nil ||
 			(pendings[transferLeader] != nil && !pendings[transferLeader].IsEnd()) {
+			bs.recordEvent("region-pending")
 			return false
 		}
 	}
 
 	if !opt.IsHealthyAllowPending(bs.cluster, region) {
 		schedulerCounter.WithLabelValues(bs.sche.GetName(), "unhealthy-replica").Inc()
+		bs.recordEvent("unhealthy-replica")
 		return false
 	}
 
 	if !opt.IsRegionReplicated(bs.cluster, region) {
 		log.Debug("region has abnormal replica count", zap.String("scheduler", bs.sche.GetName()), zap.Uint64("region-id", region.GetID()))
 		schedulerCounter.WithLabelValues(bs.sche.GetName(), "abnormal-replica").Inc()
+		bs.recordEvent("abnormal-replica")
 		return false
 	}
 
@@ -664,7 +709,7 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*storeLoadDetail {
 		}
 
 		filters = []filter.Filter{
-			filter.StoreStateFilter{ActionScope: bs.sche.GetName(), MoveRegion: true},
+			filter.StoreStateFilter{ActionScope: bs.sche.GetName(), MoveRegion: true, OpKind: operator.OpHotRegion.String()},
 			filter.NewExcludedFilter(bs.sche.GetName(), bs.cur.region.GetStoreIds(), bs.cur.region.GetStoreIds()),
 			filter.NewHealthFilter(bs.sche.GetName()),
 			scoreGuard,
@@ -684,12 +729,16 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*storeLoadDetail {
 		return nil
 	}
 
+	filters = bs.sche.diagnostic.WrapFilters(filters)
 	ret := make(map[uint64]*storeLoadDetail, len(candidates))
 	for _, store := range candidates {
 		if !filter.Target(bs.cluster, store, filters) {
 			ret[store.GetID()] = bs.stLoadDetail[store.GetID()]
 		}
 	}
+	if len(ret) == 0 {
+		bs.recordEvent("no-target-store")
+	}
 	return ret
 }
 
@@ -702,31 +751,54 @@ func (bs *balanceSolver) calcProgressiveRank() {
 	rank := int64(0)
 	if bs.rwTy == write && bs.opTy == transferLeader {
 		// In this condition, CPU usage is the matter.
-		// Only consider about count and key rate.
+		// Only consider about count and the first priority.
+		rate := stLdRate(bs.firstPriority)
 		if srcLd.Count > dstLd.Count &&
-			srcLd.KeyRate >= dstLd.KeyRate+peer.GetKeyRate() {
+			rate(srcLd) >= rate(dstLd)+peerRate(peer, bs.firstPriority) {
 			rank = -1
 		}
 	} else {
-		keyDecRatio := (dstLd.KeyRate + peer.GetKeyRate()) / (srcLd.KeyRate + 1)
-		keyHot := peer.GetKeyRate() >= minHotKeyRate
-		byteDecRatio := (dstLd.ByteRate + peer.GetByteRate()) / (srcLd.ByteRate + 1)
-		byteHot := peer.GetByteRate() > minHotByteRate
+		firstDecRatio, firstHot := bs.calcDecRatio(srcLd, dstLd, bs.firstPriority)
+		secondDecRatio, secondHot := bs.calcDecRatio(srcLd, dstLd, bs.secondPriority)
+		minorDecRatio := bs.sche.conf.getMinorDecRatio()
+		firstGreatDecRatio := 1 - bs.sche.conf.getToleranceRatio(bs.firstPriority)
+		secondGreatDecRatio := 1 - bs.sche.conf.getToleranceRatio(bs.secondPriority)
 		switch {
-		case byteHot && byteDecRatio <= greatDecRatio && keyHot && keyDecRatio <= greatDecRatio:
-			// Both byte rate and key rate are balanced, the best choice.
+		case firstHot && firstDecRatio <= firstGreatDecRatio && secondHot && secondDecRatio <= secondGreatDecRatio:
+			// Both dimensions are balanced, the best choice.
 			rank = -3
-		case byteDecRatio <= minorDecRatio && keyHot && keyDecRatio <= greatDecRatio:
-			// Byte rate is not worsened, key rate is balanced.
+		case firstDecRatio <= minorDecRatio && secondHot && secondDecRatio <= secondGreatDecRatio:
+			// The first dimension is not worsened, the second dimension is balanced.
 			rank = -2
-		case byteHot && byteDecRatio <= greatDecRatio:
-			// Byte rate is balanced, ignore the key rate.
+		case firstHot && firstDecRatio <= firstGreatDecRatio:
+			// The first dimension is balanced, ignore the second dimension.
 			rank = -1
 		}
 	}
 	bs.cur.progressiveRank = rank
 }
 
+// calcDecRatio returns the ratio of the destination load after the move to the
+// source load, and whether the peer is hot enough in the dimension.
+func (bs *balanceSolver) calcDecRatio(srcLd, dstLd *storeLoad, dim int) (float64, bool) {
+	rate := stLdRate(dim)
+	peer := peerRate(bs.cur.srcPeerStat, dim)
+	decRatio := (rate(dstLd) + peer) / (rate(srcLd) + 1)
+	minHotRate := bs.sche.conf.getMinHotRate(dim)
+	if dim == byteDim {
+		return decRatio, peer > minHotRate
+	}
+	return decRatio, peer >= minHotRate
+}
+
+// minHotRateStep returns the step to compare the rates of hot peers.
+func (bs *balanceSolver) minHotRateStep(dim int) float64 {
+	if step := bs.sche.conf.getMinHotRate(dim); step > 0 {
+		return step
+	}
+	return 1
+}
+
 // betterThan checks if `bs.cur` is a better solution than `old`.
 func (bs *balanceSolver) betterThan(old *solution) bool {
 	if old == nil {
@@ -756,34 +828,38 @@ func (bs *balanceSolver) betterThan(old *solution) bool {
 		// compare region
 
 		if bs.rwTy == write && bs.opTy == transferLeader {
+			curRate := peerRate(bs.cur.srcPeerStat, bs.firstPriority)
+			oldRate := peerRate(old.srcPeerStat, bs.firstPriority)
 			switch {
-			case bs.cur.srcPeerStat.GetKeyRate() > old.srcPeerStat.GetKeyRate():
+			case curRate > oldRate:
 				return true
-			case bs.cur.srcPeerStat.GetKeyRate() < old.srcPeerStat.GetKeyRate():
+			case curRate < oldRate:
 				return false
 			}
 		} else {
-			byteRkCmp := rankCmp(bs.cur.srcPeerStat.GetByteRate(), old.srcPeerStat.GetByteRate(), stepRank(0, 100))
-			keyRkCmp := rankCmp(bs.cur.srcPeerStat.GetKeyRate(), old.srcPeerStat.GetKeyRate(), stepRank(0, 10))
+			firstRkCmp := rankCmp(peerRate(bs.cur.srcPeerStat, bs.firstPriority), peerRate(old.srcPeerStat, bs.firstPriority),
+				stepRank(0, bs.minHotRateStep(bs.firstPriority)))
+			secondRkCmp := rankCmp(peerRate(bs.cur.srcPeerStat, bs.secondPriority), peerRate(old.srcPeerStat, bs.secondPriority),
+				stepRank(0, bs.minHotRateStep(bs.secondPriority)))
 
 			switch bs.cur.progressiveRank {
-			case -2: // greatDecRatio < byteDecRatio <= minorDecRatio && keyDecRatio <= greatDecRatio
-				if keyRkCmp != 0 {
-					return keyRkCmp > 0
+			case -2: // firstDecRatio <= minorDecRatio && secondDecRatio <= secondGreatDecRatio
+				if secondRkCmp != 0 {
+					return secondRkCmp > 0
 				}
-				if byteRkCmp != 0 {
-					// prefer smaller byte rate, to reduce oscillation
-					return byteRkCmp < 0
+				if firstRkCmp != 0 {
+					// prefer smaller rate of the first dimension, to reduce oscillation
+					return firstRkCmp < 0
 				}
-			case -3: // byteDecRatio <= greatDecRatio && keyDecRatio <= greatDecRatio
-				if keyRkCmp != 0 {
-					return keyRkCmp > 0
+			case -3: // firstDecRatio <= firstGreatDecRatio && secondDecRatio <= secondGreatDecRatio
+				if secondRkCmp != 0 {
+					return secondRkCmp > 0
 				}
 				fallthrough
-			case -1: // byteDecRatio <= greatDecRatio
-				if byteRkCmp != 0 {
-					// prefer region with larger byte rate, to converge faster
-					return byteRkCmp > 0
+			case -1: // firstDecRatio <= firstGreatDecRatio
+				if firstRkCmp != 0 {
+					// prefer region with larger rate of the first dimension, to converge faster
+					return firstRkCmp > 0
 				}
 			}
 		}
@@ -797,27 +873,28 @@ func (bs *balanceSolver) compareSrcStore(st1, st2 uint64) int {
 	if st1 != st2 {
 		// compare source store
 		var lpCmp storeLPCmp
+		firstRate, secondRate := stLdRate(bs.firstPriority), stLdRate(bs.secondPriority)
 		if bs.rwTy == write && bs.opTy == transferLeader {
 			lpCmp = sliceLPCmp(
 				minLPCmp(negLoadCmp(sliceLoadCmp(
 					stLdRankCmp(stLdCount, stepRank(bs.maxSrc.Count, bs.rankStep.Count)),
-					stLdRankCmp(stLdKeyRate, stepRank(bs.maxSrc.KeyRate, bs.rankStep.KeyRate)),
-					stLdRankCmp(stLdByteRate, stepRank(bs.maxSrc.ByteRate, bs.rankStep.ByteRate)),
+					stLdRankCmp(firstRate, stepRank(firstRate(bs.maxSrc), firstRate(bs.rankStep))),
+					stLdRankCmp(secondRate, stepRank(secondRate(bs.maxSrc), secondRate(bs.rankStep))),
 				))),
 				diffCmp(sliceLoadCmp(
 					stLdRankCmp(stLdCount, stepRank(0, bs.rankStep.Count)),
-					stLdRankCmp(stLdKeyRate, stepRank(0, bs.rankStep.KeyRate)),
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					stLdRankCmp(firstRate, stepRank(0, firstRate(bs.rankStep))),
+					stLdRankCmp(secondRate, stepRank(0, secondRate(bs.rankStep))),
 				)),
 			)
 		} else {
 			lpCmp = sliceLPCmp(
 				minLPCmp(negLoadCmp(sliceLoadCmp(
-					stLdRankCmp(stLdByteRate, stepRank(bs.maxSrc.ByteRate, bs.rankStep.ByteRate)),
-					stLdRankCmp(stLdKeyRate, stepRank(bs.maxSrc.KeyRate, bs.rankStep.KeyRate)),
+					stLdRankCmp(firstRate, stepRank(firstRate(bs.maxSrc), firstRate(bs.rankStep))),
+					stLdRankCmp(secondRate, stepRank(secondRate(bs.maxSrc), secondRate(bs.rankStep))),
 				))),
 				diffCmp(
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					stLdRankCmp(firstRate, stepRank(0, firstRate(bs.rankStep))),
 				),
 			)
 		}
@@ -834,26 +911,27 @@ func (bs *balanceSolver) compareDstStore(st1, st2 uint64) int {
 	if st1 != st2 {
 		// compare destination store
 		var lpCmp storeLPCmp
+		firstRate, secondRate := stLdRate(bs.firstPriority), stLdRate(bs.secondPriority)
 		if bs.rwTy == write && bs.opTy == transferLeader {
 			lpCmp = sliceLPCmp(
 				maxLPCmp(sliceLoadCmp(
 					stLdRankCmp(stLdCount, stepRank(bs.minDst.Count, bs.rankStep.Count)),
-					stLdRankCmp(stLdKeyRate, stepRank(bs.minDst.KeyRate, bs.rankStep.KeyRate)),
-					stLdRankCmp(stLdByteRate, stepRank(bs.minDst.ByteRate, bs.rankStep.ByteRate)),
+					stLdRankCmp(firstRate, stepRank(firstRate(bs.minDst), firstRate(bs.rankStep))),
+					stLdRankCmp(secondRate, stepRank(secondRate(bs.minDst), secondRate(bs.rankStep))),
 				)),
 				diffCmp(sliceLoadCmp(
 					stLdRankCmp(stLdCount, stepRank(0, bs.rankStep.Count)),
-					stLdRankCmp(stLdKeyRate, stepRank(0, bs.rankStep.KeyRate)),
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					stLdRankCmp(firstRate, stepRank(0, firstRate(bs.rankStep))),
+					stLdRankCmp(secondRate, stepRank(0, secondRate(bs.rankStep))),
 				)))
 		} else {
 			lpCmp = sliceLPCmp(
 				maxLPCmp(sliceLoadCmp(
-					stLdRankCmp(stLdByteRate, stepRank(bs.minDst.ByteRate, bs.rankStep.ByteRate)),
-					stLdRankCmp(stLdKeyRate, stepRank(bs.minDst.KeyRate, bs.rankStep.KeyRate)),
+					stLdRankCmp(firstRate, stepRank(firstRate(bs.minDst), firstRate(bs.rankStep))),
+					stLdRankCmp(secondRate, stepRank(secondRate(bs.minDst), secondRate(bs.rankStep))),
 				)),
 				diffCmp(
-					stLdRankCmp(stLdByteRate, stepRank(0, bs.rankStep.ByteRate)),
+					stLdRankCmp(firstRate, stepRank(0, firstRate(bs.rankStep))),
 				),
 			)
 		}
@@ -921,6 +999,7 @@ func (bs *balanceSolver) buildOperators() ([]*operator.Operator, []Influence) {
 	if err != nil {
 		log.Debug("fail to create operator", zap.Error(err), zap.Stringer("rwType", bs.rwTy), zap.Stringer("opType", bs.opTy))
 		schedulerCounter.WithLabelValues(bs.sche.GetName(), "create-operator-fail").Inc()
+		bs.recordEvent("create-operator-fail")
 		return nil, nil
 	}
 
@@ -981,6 +1060,22 @@ func (h *hotScheduler) GetHotWriteStatus() *statistics.StoreHotPeersInfos {
 	}
 }
 
+// GetStoreLoadVectors returns the load vectors of the stores for each kind of
+// resource, which are used in the last balance.
+func (h *hotScheduler) GetStoreLoadVectors() map[string]map[uint64]*statistics.StoreLoadVector {
+	h.RLock()
+	defer h.RUnlock()
+	ret := make(map[string]map[uint64]*statistics.StoreLoadVector, resourceTypeLen)
+	for ty := resourceType(0); ty < resourceTypeLen; ty++ {
+		loads := make(map[uint64]*statistics.StoreLoadVector, len(h.stLoadInfos[ty]))
+		for id, detail := range h.stLoadInfos[ty] {
+			loads[id] = detail.toLoadVector()
+		}
+		ret[ty.String()] = loads
+	}
+	return ret
+}
+
 func (h *hotScheduler) GetWritePendingInfluence() map[uint64]Influence {
 	return h.copyPendingInfluence(writePeer)
 }
@@ -1000,7 +1095,7 @@ func (h *hotScheduler) copyPendingInfluence(ty resourceType) map[uint64]Influenc
 	return ret
 }
 
-func calcPendingWeight(op *operator.Operator) float64 {
+func (h *hotScheduler) calcPendingWeight(op *operator.Operator) float64 {
 	if op.CheckExpired() || op.CheckTimeout() {
 		return 0
 	}
@@ -1010,6 +1105,7 @@ func calcPendingWeight(op *operator.Operator) float64 {
 	}
 	switch status {
 	case operator.SUCCESS:
+		maxZombieDur := h.conf.getMaxZombieDuration()
 		zombieDur := time.Since(op.GetReachTimeOf(status))
 		if zombieDur >= maxZombieDur {
 			return 0
@@ -1075,6 +1171,19 @@ const (
 	resourceTypeLen
 )
 
+func (ty resourceType) String() string {
+	switch ty {
+	case writePeer:
+		return "write-peer"
+	case writeLeader:
+		return "write-leader"
+	case readLeader:
+		return "read-leader"
+	default:
+		return ""
+	}
+}
+
 func toResourceType(rwTy rwType, opTy opType) resourceType {
 	switch rwTy {
 	case write:
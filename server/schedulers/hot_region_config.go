@@ -0,0 +1,332 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/statistics"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+)
+
+// The dimensions of the load that the hot region scheduler balances.
+const (
+	BytePriority = "byte"
+	KeyPriority  = "key"
+)
+
+const (
+	byteDim int = iota
+	keyDim
+	dimLen
+)
+
+const (
+	defaultMinHotByteRate        = 100
+	defaultMinHotKeyRate         = 10
+	defaultMaxZombieRounds       = 1
+	defaultMaxPeerNum            = 1000
+	defaultByteRateRankStepRatio = 0.05
+	defaultKeyRateRankStepRatio  = 0.05
+	defaultCountRankStepRatio    = 0.1
+	defaultToleranceRatio        = 0.05
+	defaultMinorDecRatio         = 0.99
+)
+
+func stringToDim(name string) int {
+	switch name {
+	case BytePriority:
+		return byteDim
+	case KeyPriority:
+		return keyDim
+	}
+	return -1
+}
+
+func initHotRegionScheduleConfig() *hotRegionSchedulerConfig {
+	return &hotRegionSchedulerConfig{
+		MinHotByteRate:         defaultMinHotByteRate,
+		MinHotKeyRate:          defaultMinHotKeyRate,
+		MaxZombieRounds:        defaultMaxZombieRounds,
+		MaxPeerNum:             defaultMaxPeerNum,
+		ByteRateRankStepRatio:  defaultByteRateRankStepRatio,
+		KeyRateRankStepRatio:   defaultKeyRateRankStepRatio,
+		CountRankStepRatio:     defaultCountRankStepRatio,
+		MinorDecRatio:          defaultMinorDecRatio,
+		ReadPriorities:         []string{BytePriority, KeyPriority},
+		WriteLeaderPriorities:  []string{KeyPriority, BytePriority},
+		WritePeerPriorities:    []string{BytePriority, KeyPriority},
+		ByteRateToleranceRatio: defaultToleranceRatio,
+		KeyRateToleranceRatio:  defaultToleranceRatio,
+	}
+}
+
+// hotRegionSchedulerConfig is the config of the hot region scheduler.
+// The priorities list the first and the second dimension that the scheduler
+// balances for each kind of resource. A move balances a dimension only when
+// the load of the destination store after the move is lower than the load of
+// the source store by at least the tolerance ratio of the dimension.
+// A MinHotDegree of 0 means following the hot-region-cache-hits-threshold of
+// the cluster.
+type hotRegionSchedulerConfig struct {
+	sync.RWMutex
+	storage *core.Storage
+
+	MinHotByteRate float64 `json:"min-hot-byte-rate"`
+	MinHotKeyRate  float64 `json:"min-hot-key-rate"`
+	MinHotDegree   int     `json:"min-hot-degree"`
+	// MaxZombieRounds is the number of store heartbeats that a finished
+	// operator keeps its pending influence.
+	MaxZombieRounds int `json:"max-zombie-rounds"`
+	// MaxPeerNum is the max number of hot peers considered in one round.
+	MaxPeerNum            int     `json:"max-peer-number"`
+	ByteRateRankStepRatio float64 `json:"byte-rate-rank-step-ratio"`
+	KeyRateRankStepRatio  float64 `json:"key-rate-rank-step-ratio"`
+	CountRankStepRatio    float64 `json:"count-rank-step-ratio"`
+	MinorDecRatio         float64 `json:"minor-dec-ratio"`
+
+	ReadPriorities         []string `json:"read-priorities"`
+	WriteLeaderPriorities  []string `json:"write-leader-priorities"`
+	WritePeerPriorities    []string `json:"write-peer-priorities"`
+	ByteRateToleranceRatio float64  `json:"byte-rate-tolerance-ratio"`
+	KeyRateToleranceRatio  float64  `json:"key-rate-tolerance-ratio"`
+}
+
+// getMinHotRate returns the min rate for a peer to be regarded as hot in the
+// dimension.
+func (conf *hotRegionSchedulerConfig) getMinHotRate(dim int) float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	switch dim {
+	case byteDim:
+		return conf.MinHotByteRate
+	case keyDim:
+		return conf.MinHotKeyRate
+	}
+	return 0
+}
+
+func (conf *hotRegionSchedulerConfig) getMinHotDegree(cluster opt.Cluster) int {
+	conf.RLock()
+	defer conf.RUnlock()
+	if conf.MinHotDegree > 0 {
+		return conf.MinHotDegree
+	}
+	return cluster.GetHotRegionCacheHitsThreshold()
+}
+
+func (conf *hotRegionSchedulerConfig) getMaxZombieDuration() time.Duration {
+	conf.RLock()
+	defer conf.RUnlock()
+	return time.Duration(conf.MaxZombieRounds) * statistics.StoreHeartBeatReportInterval * time.Second
+}
+
+func (conf *hotRegionSchedulerConfig) getMaxPeerNum() int {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.MaxPeerNum
+}
+
+func (conf *hotRegionSchedulerConfig) getMinorDecRatio() float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.MinorDecRatio
+}
+
+// getRankStepRatios returns the rank step ratios of byte, key and count.
+func (conf *hotRegionSchedulerConfig) getRankStepRatios() (byteRatio, keyRatio, countRatio float64) {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.ByteRateRankStepRatio, conf.KeyRateRankStepRatio, conf.CountRankStepRatio
+}
+
+// getPriorities returns the first and the second dimension to balance.
+func (conf *hotRegionSchedulerConfig) getPriorities(ty resourceType) (first, second int) {
+	conf.RLock()
+	defer conf.RUnlock()
+	var priorities []string
+	switch ty {
+	case readLeader:
+		priorities = conf.ReadPriorities
+	case writeLeader:
+		priorities = conf.WriteLeaderPriorities
+	case writePeer:
+		priorities = conf.WritePeerPriorities
+	}
+	return stringToDim(priorities[0]), stringToDim(priorities[1])
+}
+
+// getToleranceRatio returns the tolerance ratio of the dimension.
+func (conf *hotRegionSchedulerConfig) getToleranceRatio(dim int) float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	switch dim {
+	case byteDim:
+		return conf.ByteRateToleranceRatio
+	case keyDim:
+		return conf.KeyRateToleranceRatio
+	}
+	return 0
+}
+
+func (conf *hotRegionSchedulerConfig) validate() error {
+	conf.RLock()
+	defer conf.RUnlock()
+	if conf.MinHotByteRate < 0 || conf.MinHotKeyRate < 0 {
+		return errors.New("min hot rate should not be negative")
+	}
+	if conf.MinHotDegree < 0 || conf.MaxZombieRounds < 0 {
+		return errors.New("min-hot-degree and max-zombie-rounds should not be negative")
+	}
+	if conf.MaxPeerNum <= 0 {
+		return errors.Errorf("max-peer-number should be positive, got %v", conf.MaxPeerNum)
+	}
+	for _, ratio := range []float64{conf.ByteRateRankStepRatio, conf.KeyRateRankStepRatio, conf.CountRankStepRatio} {
+		if ratio < 0 || ratio > 1 {
+			return errors.Errorf("rank step ratio should be in [0, 1], got %v", ratio)
+		}
+	}
+	if conf.MinorDecRatio <= 0 || conf.MinorDecRatio > 1 {
+		return errors.Errorf("minor-dec-ratio should be in (0, 1], got %v", conf.MinorDecRatio)
+	}
+	for _, priorities := range [][]string{conf.ReadPriorities, conf.WriteLeaderPriorities, conf.WritePeerPriorities} {
+		if len(priorities) != 2 {
+			return errors.Errorf("priorities should have exactly 2 dimensions, got %v", priorities)
+		}
+		if stringToDim(priorities[0]) < 0 || stringToDim(priorities[1]) < 0 {
+			return errors.Errorf("invalid dimension in priorities %v", priorities)
+		}
+		if priorities[0] == priorities[1] {
+			return errors.Errorf("duplicated dimension in priorities %v", priorities)
+		}
+	}
+	for _, ratio := range []float64{conf.ByteRateToleranceRatio, conf.KeyRateToleranceRatio} {
+		if ratio < 0 || ratio >= 1 {
+			return errors.Errorf("tolerance ratio should be in [0, 1), got %v", ratio)
+		}
+	}
+	return nil
+}
+
+func (conf *hotRegionSchedulerConfig) clone() *hotRegionSchedulerConfig {
+	conf.RLock()
+	defer conf.RUnlock()
+	return &hotRegionSchedulerConfig{
+		storage:                conf.storage,
+		MinHotByteRate:         conf.MinHotByteRate,
+		MinHotKeyRate:          conf.MinHotKeyRate,
+		MinHotDegree:           conf.MinHotDegree,
+		MaxZombieRounds:        conf.MaxZombieRounds,
+		MaxPeerNum:             conf.MaxPeerNum,
+		ByteRateRankStepRatio:  conf.ByteRateRankStepRatio,
+		KeyRateRankStepRatio:   conf.KeyRateRankStepRatio,
+		CountRankStepRatio:     conf.CountRankStepRatio,
+		MinorDecRatio:          conf.MinorDecRatio,
+		ReadPriorities:         append([]string(nil), conf.ReadPriorities...),
+		WriteLeaderPriorities:  append([]string(nil), conf.WriteLeaderPriorities...),
+		WritePeerPriorities:    append([]string(nil), conf.WritePeerPriorities...),
+		ByteRateToleranceRatio: conf.ByteRateToleranceRatio,
+		KeyRateToleranceRatio:  conf.KeyRateToleranceRatio,
+	}
+}
+
+// update replaces the config with a validated one and persists it.
+func (conf *hotRegionSchedulerConfig) update(newConf *hotRegionSchedulerConfig) error {
+	conf.Lock()
+	defer conf.Unlock()
+	conf.MinHotByteRate = newConf.MinHotByteRate
+	conf.MinHotKeyRate = newConf.MinHotKeyRate
+	conf.MinHotDegree = newConf.MinHotDegree
+	conf.MaxZombieRounds = newConf.MaxZombieRounds
+	conf.MaxPeerNum = newConf.MaxPeerNum
+	conf.ByteRateRankStepRatio = newConf.ByteRateRankStepRatio
+	conf.KeyRateRankStepRatio = newConf.KeyRateRankStepRatio
+	conf.CountRankStepRatio = newConf.CountRankStepRatio
+	conf.MinorDecRatio = newConf.MinorDecRatio
+	conf.ReadPriorities = newConf.ReadPriorities
+	conf.WriteLeaderPriorities = newConf.WriteLeaderPriorities
+	conf.WritePeerPriorities = newConf.WritePeerPriorities
+	conf.ByteRateToleranceRatio = newConf.ByteRateToleranceRatio
+	conf.KeyRateToleranceRatio = newConf.KeyRateToleranceRatio
+	return conf.persist()
+}
+
+// persist saves the config. The caller should hold the lock.
+func (conf *hotRegionSchedulerConfig) persist() error {
+	if conf.storage == nil {
+		return nil
+	}
+	data, err := schedule.EncodeConfig(conf)
+	if err != nil {
+		return err
+	}
+	return conf.storage.SaveScheduleConfig(HotRegionName, data)
+}
+
+type hotRegionHandler struct {
+	rd     *render.Render
+	config *hotRegionSchedulerConfig
+}
+
+func (handler *hotRegionHandler) ListConfig(w http.ResponseWriter, r *http.Request) {
+	handler.rd.JSON(w, http.StatusOK, handler.config.clone())
+}
+
+// UpdateConfig updates the fields given in the request body and keeps the
+// others unchanged.
+func (handler *hotRegionHandler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		handler.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	newConf := handler.config.clone()
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(newConf); err != nil {
+		handler.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := newConf.validate(); err != nil {
+		handler.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := handler.config.update(newConf); err != nil {
+		handler.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	handler.rd.JSON(w, http.StatusOK, nil)
+}
+
+func newHotRegionHandler(config *hotRegionSchedulerConfig) http.Handler {
+	h := &hotRegionHandler{
+		config: config,
+		rd:     render.New(render.Options{IndentJSON: true}),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/config", h.UpdateConfig).Methods("POST")
+	router.HandleFunc("/list", h.ListConfig).Methods("GET")
+	return router
+}
@@ -40,7 +40,7 @@ func (s *testHotSchedulerSuite) TestGCPendingOpInfos(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	newTestReplication(opt, 3, "zone", "host")
 	tc := mockcluster.NewCluster(opt)
-	sche, err := schedule.CreateScheduler(HotRegionType, schedule.NewOperatorController(ctx, nil, nil), core.NewStorage(kv.NewMemoryKV()), nil)
+	sche, err := schedule.CreateScheduler(HotRegionType, schedule.NewOperatorController(ctx, nil, nil), core.NewStorage(kv.NewMemoryKV()), schedule.ConfigJSONDecoder([]byte("null")))
 	c.Assert(err, IsNil)
 	hb := sche.(*hotScheduler)
 
@@ -107,6 +107,36 @@ func (s *testHotSchedulerSuite) TestGCPendingOpInfos(c *C) {
 	}
 }
 
+func (s *testHotSchedulerSuite) TestHotRegionSchedulerConfig(c *C) {
+	conf := initHotRegionScheduleConfig()
+	c.Assert(conf.validate(), IsNil)
+	first, second := conf.getPriorities(readLeader)
+	c.Assert(first, Equals, byteDim)
+	c.Assert(second, Equals, keyDim)
+	first, second = conf.getPriorities(writeLeader)
+	c.Assert(first, Equals, keyDim)
+	c.Assert(second, Equals, byteDim)
+
+	for _, priorities := range [][]string{
+		{KeyPriority},
+		{KeyPriority, KeyPriority},
+		{KeyPriority, "cpu"},
+		{BytePriority, KeyPriority, "count"},
+	} {
+		conf.ReadPriorities = priorities
+		c.Assert(conf.validate(), NotNil)
+	}
+	conf.ReadPriorities = []string{BytePriority, KeyPriority}
+
+	conf.KeyRateToleranceRatio = 1
+	c.Assert(conf.validate(), NotNil)
+	conf.KeyRateToleranceRatio = -0.1
+	c.Assert(conf.validate(), NotNil)
+	conf.KeyRateToleranceRatio = 0.1
+	c.Assert(conf.validate(), IsNil)
+	c.Assert(conf.getToleranceRatio(keyDim), Equals, 0.1)
+}
+
 func newTestRegion(id uint64) *core.RegionInfo {
 	peers := []*metapb.Peer{{Id: id*100 + 1, StoreId: 1}, {Id: id*100 + 2, StoreId: 2}, {Id: id*100 + 3, StoreId: 3}}
 	return core.NewRegionInfo(&metapb.Region{Id: id, Peers: peers}, peers[0])
@@ -388,6 +418,50 @@ func (s *testHotWriteRegionSchedulerSuite) TestLeader(c *C) {
 	}
 }
 
+func (s *testHotWriteRegionSchedulerSuite) TestDiagnostic(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statistics.Denoising = false
+	opt := mockoption.NewScheduleOptions()
+	hb, err := schedule.CreateScheduler(HotWriteRegionType, schedule.NewOperatorController(ctx, nil, nil), core.NewStorage(kv.NewMemoryKV()), nil)
+	c.Assert(err, IsNil)
+	opt.HotRegionCacheHitsThreshold = 0
+
+	tc := mockcluster.NewCluster(opt)
+	for id := uint64(1); id <= 4; id++ {
+		tc.AddRegionStore(id, 20)
+	}
+	tc.SetStoreDown(4)
+	tc.UpdateStorageWrittenBytes(1, 10*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageWrittenBytes(2, 1*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStorageWrittenBytes(3, 1*MB*statistics.StoreHeartBeatReportInterval)
+	addRegionInfo(tc, write, []testRegionInfo{
+		{1, []uint64{1, 2, 3}, 512 * KB, 0},
+		{2, []uint64{1, 2, 3}, 512 * KB, 0},
+	})
+
+	recorder := hb.(schedule.DiagnosableScheduler).GetDiagnosticRecorder()
+	c.Assert(recorder.GetLastResult(), IsNil)
+	ops := hb.Schedule(tc)
+	result := recorder.GetLastResult()
+	c.Assert(result, NotNil)
+	c.Assert(result.OperatorCount, Equals, len(ops))
+	c.Assert(result.SourceStores, DeepEquals, []uint64{1, 2, 3})
+	// The peers can only be moved to store 4, which is down.
+	c.Assert(result.Events["write-peer-no-target-store"], Greater, 0)
+	rejected := make(map[uint64]string)
+	for _, f := range result.Filters {
+		c.Assert(f.Action, Equals, "target")
+		rejected[f.StoreID] = f.Type
+	}
+	c.Assert(rejected, DeepEquals, map[uint64]string{
+		1: "exclude-filter",
+		2: "exclude-filter",
+		3: "exclude-filter",
+		4: "store-state-filter",
+	})
+}
+
 func (s *testHotWriteRegionSchedulerSuite) TestWithPendingInfluence(c *C) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -22,6 +22,7 @@ import (
 	"github.com/montanaflynn/stats"
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/statistics"
@@ -60,7 +61,7 @@ func minDuration(a, b time.Duration) time.Duration {
 	return b
 }
 
-func shouldBalance(cluster opt.Cluster, source, target *core.StoreInfo, region *core.RegionInfo, kind core.ScheduleKind, opInfluence operator.OpInfluence, scheduleName string) bool {
+func shouldBalance(cluster opt.Cluster, source, target *core.StoreInfo, region *core.RegionInfo, kind core.ScheduleKind, opInfluence operator.OpInfluence, scheduleName string, recorder *schedule.DiagnosticRecorder) bool {
 	// The reason we use max(regionSize, averageRegionSize) to check is:
 	// 1. prevent moving small regions between stores with close scores, leading to unnecessary balance.
 	// 2. prevent moving huge regions, leading to over balance.
@@ -78,6 +79,15 @@ func shouldBalance(cluster opt.Cluster, source, target *core.StoreInfo, region *
 	}
 	// Make sure after move, source score is still greater than target score.
 	shouldBalance := sourceScore > targetScore
+	recorder.RecordScore(&schedule.ScoreRecord{
+		RegionID:         region.GetID(),
+		SourceStoreID:    sourceID,
+		TargetStoreID:    targetID,
+		SourceScore:      sourceScore,
+		TargetScore:      targetScore,
+		TolerantResource: tolerantResource,
+		ShouldBalance:    shouldBalance,
+	})
 
 	if !shouldBalance {
 		log.Debug("skip balance "+kind.Resource.String(),
@@ -92,6 +102,29 @@ func shouldBalance(cluster opt.Cluster, source, target *core.StoreInfo, region *
 	return shouldBalance
 }
 
+// getRegionWeights returns the region weights of stores derived from their
+// capacities. It returns nil if the region weights are set by user.
+func getRegionWeights(cluster opt.Cluster) map[uint64]float64 {
+	if cluster.GetRegionWeightMode() != core.CapacityRegionWeight {
+		return nil
+	}
+	stores := make([]*core.StoreInfo, 0, len(cluster.GetStores()))
+	for _, store := range cluster.GetStores() {
+		if !store.IsTombstone() {
+			stores = append(stores, store)
+		}
+	}
+	return core.CapacityRegionWeights(stores, cluster.GetStoreClassWeights())
+}
+
+// withRegionWeight returns the store with the derived region weight if any.
+func withRegionWeight(store *core.StoreInfo, weights map[uint64]float64) *core.StoreInfo {
+	if weight, ok := weights[store.GetID()]; ok {
+		return store.Clone(core.SetRegionWeight(weight))
+	}
+	return store
+}
+
 func getTolerantResource(cluster opt.Cluster, region *core.RegionInfo, kind core.ScheduleKind) int64 {
 	if kind.Resource == core.LeaderKind && kind.Policy == core.ByCount {
 		tolerantSizeRatio := cluster.GetTolerantSizeRatio()
@@ -233,6 +266,28 @@ func stLdCount(ld *storeLoad) float64 {
 	return ld.Count
 }
 
+// stLdRate returns the function to get the rate of the dimension.
+func stLdRate(dim int) func(ld *storeLoad) float64 {
+	switch dim {
+	case byteDim:
+		return stLdByteRate
+	case keyDim:
+		return stLdKeyRate
+	}
+	return nil
+}
+
+// peerRate returns the rate of the hot peer in the dimension.
+func peerRate(peer *statistics.HotPeerStat, dim int) float64 {
+	switch dim {
+	case byteDim:
+		return peer.GetByteRate()
+	case keyDim:
+		return peer.GetKeyRate()
+	}
+	return 0
+}
+
 type storeLoadCmp func(ld1, ld2 *storeLoad) int
 
 func negLoadCmp(cmp storeLoadCmp) storeLoadCmp {
@@ -343,6 +398,14 @@ type storeLoadDetail struct {
 	HotPeers []*statistics.HotPeerStat
 }
 
+func (li *storeLoadDetail) toLoadVector() *statistics.StoreLoadVector {
+	return &statistics.StoreLoadVector{
+		ByteRate: li.LoadPred.Current.ByteRate,
+		KeyRate:  li.LoadPred.Current.KeyRate,
+		Count:    li.LoadPred.Current.Count,
+	}
+}
+
 func (li *storeLoadDetail) toHotPeersStat() *statistics.HotPeersStat {
 	peers := make([]statistics.HotPeerStat, 0, len(li.HotPeers))
 	for _, peer := range li.HotPeers {
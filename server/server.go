@@ -124,6 +124,13 @@ type Server struct {
 	basicCluster *core.BasicCluster
 	// for tso.
 	tso *tso.TimestampOracle
+	// for the local tso of the data center, nil if dc-location is not set.
+	localTSO *tso.LocalAllocator
+	// the recent jumps of the system time.
+	clockJumps clockJumpHistory
+	// serviceSafePointLock serializes the updates of service GC safe points and
+	// the GC safe point, which is bounded by the service ones.
+	serviceSafePointLock sync.Mutex
 	// for raft cluster
 	cluster *cluster.RaftCluster
 	// For async region heartbeat.
@@ -372,6 +379,16 @@ func (s *Server) startServer(ctx context.Context) error {
 		s.cfg.TsoSaveInterval.Duration,
 		func() time.Duration { return s.scheduleOpt.LoadPDServerConfig().MaxResetTSGap },
 	)
+	if s.cfg.DCLocation != "" {
+		s.localTSO = tso.NewLocalAllocator(
+			s.client,
+			s.rootPath,
+			s.cfg.DCLocation,
+			s.member.MemberValue(),
+			s.cfg.LeaderLease,
+			s.cfg.TsoSaveInterval.Duration,
+		)
+	}
 	kvBase := kv.NewEtcdKVBase(s.client, s.rootPath)
 	path := filepath.Join(s.cfg.DataDir, "region-meta")
 	regionStorage, err := core.NewRegionStorage(ctx, path)
@@ -455,9 +472,15 @@ func (s *Server) IsClosed() bool {
 
 // Run runs the pd server.
 func (s *Server) Run() error {
-	go StartMonitor(s.ctx, time.Now, func() {
-		log.Error("system time jumps backward")
-		timeJumpBackCounter.Inc()
+	go StartMonitor(s.ctx, time.Now, func(offset time.Duration) {
+		if offset < 0 {
+			log.Error("system time jumps backward")
+			timeJumpBackCounter.Inc()
+		} else {
+			log.Error("system time jumps forward")
+			timeJumpForwardCounter.Inc()
+		}
+		s.clockJumps.record(offset)
 	})
 	if err := s.startEtcd(s.ctx); err != nil {
 		return err
@@ -492,6 +515,10 @@ func (s *Server) startServerLoop(ctx context.Context) {
 		s.serverLoopWg.Add(1)
 		go s.configCheckLoop()
 	}
+	if s.localTSO != nil {
+		s.serverLoopWg.Add(1)
+		go s.localTSOLoop()
+	}
 }
 
 func (s *Server) stopServerLoop() {
@@ -658,6 +685,17 @@ func (s *Server) GetMember() *member.Member {
 	return s.member
 }
 
+// GetLocalTSOAllocator returns the local tso allocator of the data center of
+// the server, nil if dc-location is not set.
+func (s *Server) GetLocalTSOAllocator() *tso.LocalAllocator {
+	return s.localTSO
+}
+
+// GetClockJumps returns the recent jumps of the system time.
+func (s *Server) GetClockJumps() []ClockJump {
+	return s.clockJumps.get()
+}
+
 // GetStorage returns the backend storage of server.
 func (s *Server) GetStorage() *core.Storage {
 	return s.storage
@@ -998,6 +1036,72 @@ func (s *Server) GetClusterStatus() (*cluster.Status, error) {
 	return s.cluster.LoadClusterStatus()
 }
 
+// UpdateServiceGCSafePoint updates the safe point for a specific service, and
+// returns the minimum safe point across all services with its remaining TTL in
+// seconds. A non-positive TTL removes the safe point of the service.
+func (s *Server) UpdateServiceGCSafePoint(serviceID string, ttl int64, safePoint uint64) (*core.ServiceSafePoint, int64, error) {
+	s.serviceSafePointLock.Lock()
+	defer s.serviceSafePointLock.Unlock()
+
+	if s.GetRaftCluster() == nil {
+		return nil, 0, errors.WithStack(cluster.ErrNotBootstrapped)
+	}
+
+	if ttl <= 0 {
+		if err := s.storage.RemoveServiceGCSafePoint(serviceID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	now, err := s.TSONow()
+	if err != nil {
+		return nil, 0, err
+	}
+	min, err := s.storage.LoadMinServiceGCSafePoint(now)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// A service can not move the minimum safe point backward.
+	if ttl > 0 && safePoint >= min.SafePoint {
+		ssp := &core.ServiceSafePoint{
+			ServiceID: serviceID,
+			ExpiredAt: now.Unix() + ttl,
+			SafePoint: safePoint,
+		}
+		if err := s.storage.SaveServiceGCSafePoint(ssp); err != nil {
+			return nil, 0, err
+		}
+		log.Info("update service GC safe point",
+			zap.String("service-id", ssp.ServiceID),
+			zap.Int64("expire-at", ssp.ExpiredAt),
+			zap.Uint64("safepoint", ssp.SafePoint))
+		// If the minimum safe point is held by the service, it may be changed.
+		if min.ServiceID == "" || min.ServiceID == serviceID {
+			if min, err = s.storage.LoadMinServiceGCSafePoint(now); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+
+	// The TTL is 0 if no service holds a safe point.
+	minTTL := min.ExpiredAt - now.Unix()
+	if minTTL < 0 {
+		minTTL = 0
+	}
+	return min, minTTL, nil
+}
+
+// TSONow returns the physical time of a newly allocated timestamp, which is
+// consistent across PD leader changes.
+func (s *Server) TSONow() (time.Time, error) {
+	ts, err := s.tso.GetRespTS(1)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, ts.GetPhysical()*int64(time.Millisecond)), nil
+}
+
 // SetLogLevel sets log level.
 func (s *Server) SetLogLevel(level string) {
 	s.cfg.Log.Level = level
@@ -1085,6 +1189,7 @@ func (s *Server) campaignLeader() {
 		return
 	}
 	defer s.tso.ResetTimestamp()
+	go s.tso.WatchLocalTimestamps(ctx)
 
 	err := s.reloadConfigFromKV()
 	if err != nil {
@@ -1135,6 +1240,13 @@ func (s *Server) campaignLeader() {
 	}
 }
 
+func (s *Server) localTSOLoop() {
+	defer logutil.LogPanic()
+	defer s.serverLoopWg.Done()
+
+	s.localTSO.Run(s.serverLoopCtx)
+}
+
 func (s *Server) etcdLeaderLoop() {
 	defer logutil.LogPanic()
 	defer s.serverLoopWg.Done()
@@ -1144,6 +1256,9 @@ func (s *Server) etcdLeaderLoop() {
 	for {
 		select {
 		case <-time.After(s.cfg.LeaderPriorityCheckInterval.Duration):
+			if s.cfg.EnableAutoLeaderPriority {
+				s.checkHealth(ctx)
+			}
 			s.member.CheckPriority(ctx)
 		case <-ctx.Done():
 			log.Info("server is closed, exit etcd leader loop")
@@ -1152,6 +1267,17 @@ func (s *Server) etcdLeaderLoop() {
 	}
 }
 
+// checkHealth scores the health of the member, and the leader steps down if
+// it is unhealthy.
+func (s *Server) checkHealth(ctx context.Context) {
+	var tsoSaveLatency time.Duration
+	if status := s.tso.GetStatus(); status.Allocating {
+		tsoSaveLatency = status.SaveDuration.Duration
+	}
+	score := s.member.CheckHealth(ctx, s.cfg.DataDir, tsoSaveLatency, s.cfg.LeaderHealthScoreThreshold, s.cfg.LeaderPriorityCheckInterval.Duration)
+	healthScoreGauge.Set(score.Score)
+}
+
 func (s *Server) configCheckLoop() {
 	defer logutil.LogPanic()
 	defer s.serverLoopWg.Done()
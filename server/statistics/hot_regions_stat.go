@@ -19,3 +19,11 @@ type HotPeersStat struct {
 	Count          int           `json:"regions_count"`
 	Stats          []HotPeerStat `json:"statistics"`
 }
+
+// StoreLoadVector is the load of a store in each dimension that the hot region
+// scheduler balances.
+type StoreLoadVector struct {
+	ByteRate float64 `json:"byte-rate"`
+	KeyRate  float64 `json:"key-rate"`
+	Count    float64 `json:"count"`
+}
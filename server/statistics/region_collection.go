@@ -15,6 +15,7 @@ package statistics
 
 import (
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
 )
 
 // RegionStatisticType represents the type of the region's status.
@@ -29,6 +30,7 @@ const (
 	OfflinePeer
 	LearnerPeer
 	EmptyRegion
+	IsolationViolated
 )
 
 const nonIsolation = "none"
@@ -54,6 +56,7 @@ func NewRegionStatistics(opt ScheduleOptions) *RegionStatistics {
 	r.stats[OfflinePeer] = make(map[uint64]*core.RegionInfo)
 	r.stats[LearnerPeer] = make(map[uint64]*core.RegionInfo)
 	r.stats[EmptyRegion] = make(map[uint64]*core.RegionInfo)
+	r.stats[IsolationViolated] = make(map[uint64]*core.RegionInfo)
 	return r
 }
 
@@ -121,12 +124,38 @@ func (r *RegionStatistics) Observe(region *core.RegionInfo, stores []*core.Store
 	}
 
 	if oldIndex, ok := r.index[regionID]; ok {
+		// The isolation is observed by ObserveRuleFit, which is kept until
+		// the next fit unless placement rules are disabled.
+		if r.opt.IsPlacementRulesEnabled() {
+			peerTypeIndex |= oldIndex & IsolationViolated
+		}
 		deleteIndex = oldIndex &^ peerTypeIndex
 	}
 	r.deleteEntry(deleteIndex, regionID)
 	r.index[regionID] = peerTypeIndex
 }
 
+// ObserveRuleFit records whether the region violates the isolation level of
+// placement rules by its fit, which is computed by the rule checker, so that
+// the region is not fitted again when observed.
+func (r *RegionStatistics) ObserveRuleFit(region *core.RegionInfo, fit *placement.RegionFit) {
+	regionID := region.GetID()
+	index, ok := r.index[regionID]
+	if !ok {
+		// The region is not observed yet or has been removed.
+		return
+	}
+	for _, rf := range fit.RuleFits {
+		if !rf.IsIsolationSatisfied() {
+			r.stats[IsolationViolated][regionID] = region
+			r.index[regionID] = index | IsolationViolated
+			return
+		}
+	}
+	delete(r.stats[IsolationViolated], regionID)
+	r.index[regionID] = index &^ IsolationViolated
+}
+
 // ClearDefunctRegion is used to handle the overlap region.
 func (r *RegionStatistics) ClearDefunctRegion(regionID uint64) {
 	if oldIndex, ok := r.index[regionID]; ok {
@@ -143,6 +172,7 @@ func (r *RegionStatistics) Collect() {
 	regionStatusGauge.WithLabelValues("offline-peer-region-count").Set(float64(len(r.stats[OfflinePeer])))
 	regionStatusGauge.WithLabelValues("learner-peer-region-count").Set(float64(len(r.stats[LearnerPeer])))
 	regionStatusGauge.WithLabelValues("empty-region-count").Set(float64(len(r.stats[EmptyRegion])))
+	regionStatusGauge.WithLabelValues("isolation-violated-region-count").Set(float64(len(r.stats[IsolationViolated])))
 }
 
 // Reset resets the metrics of the regions' status.
@@ -19,6 +19,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
 )
 
 var _ = Suite(&testRegionStatisticsSuite{})
@@ -101,6 +102,57 @@ func (t *testRegionStatisticsSuite) TestRegionStatistics(c *C) {
 	c.Assert(len(regionStats.stats[OfflinePeer]), Equals, 0)
 }
 
+func (t *testRegionStatisticsSuite) TestRegionIsolationViolated(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.EnablePlacementRules = true
+	stores := core.NewStoresInfo()
+	var storeList []*core.StoreInfo
+	for id, zone := range map[uint64]string{1: "z1", 2: "z1", 3: "z2", 4: "z3"} {
+		store := core.NewStoreInfo(&metapb.Store{Id: id, Address: "mock://tikv"},
+			core.SetStoreLabels([]*metapb.StoreLabel{{Key: "zone", Value: zone}}))
+		stores.SetStore(store)
+		storeList = append(storeList, store)
+	}
+	rules := []*placement.Rule{{
+		GroupID:        "pd",
+		ID:             "default",
+		Role:           placement.Voter,
+		Count:          3,
+		LocationLabels: []string{"zone"},
+		IsolationLevel: "zone",
+	}}
+	peers := []*metapb.Peer{{Id: 11, StoreId: 1}, {Id: 12, StoreId: 2}, {Id: 13, StoreId: 3}}
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, Peers: peers}, peers[0])
+	fit := placement.FitRegion(stores, region, rules)
+
+	regionStats := NewRegionStatistics(opt)
+	// The fit of a region that is not observed is ignored.
+	regionStats.ObserveRuleFit(region, fit)
+	c.Assert(regionStats.stats[IsolationViolated], HasLen, 0)
+
+	regionStats.Observe(region, storeList)
+	c.Assert(regionStats.stats[IsolationViolated], HasLen, 0)
+	regionStats.ObserveRuleFit(region, fit)
+	c.Assert(regionStats.stats[IsolationViolated], HasLen, 1)
+	// The violation is kept until the next fit.
+	regionStats.Observe(region, storeList)
+	c.Assert(regionStats.stats[IsolationViolated], HasLen, 1)
+
+	// The peers are isolated by zone after moving the peer on store 2 to store 4.
+	peers = []*metapb.Peer{{Id: 11, StoreId: 1}, {Id: 14, StoreId: 4}, {Id: 13, StoreId: 3}}
+	region = core.NewRegionInfo(&metapb.Region{Id: 1, Peers: peers}, peers[0])
+	regionStats.Observe(region, storeList)
+	regionStats.ObserveRuleFit(region, placement.FitRegion(stores, region, rules))
+	c.Assert(regionStats.stats[IsolationViolated], HasLen, 0)
+
+	// The violation is cleared once placement rules are disabled.
+	regionStats.ObserveRuleFit(region, fit)
+	c.Assert(regionStats.stats[IsolationViolated], HasLen, 1)
+	opt.EnablePlacementRules = false
+	regionStats.Observe(region, storeList)
+	c.Assert(regionStats.stats[IsolationViolated], HasLen, 0)
+}
+
 func (t *testRegionStatisticsSuite) TestRegionLabelIsolationLevel(c *C) {
 	locationLabels := []string{"zone", "rack", "host"}
 	labelLevelStats := NewLabelStatistics()
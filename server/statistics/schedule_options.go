@@ -34,6 +34,7 @@ type ScheduleOptions interface {
 	GetRegionScheduleLimit() uint64
 	GetReplicaScheduleLimit() uint64
 	GetMergeScheduleLimit() uint64
+	GetSplitScheduleLimit() uint64
 	GetHotRegionScheduleLimit() uint64
 	GetMaxReplicas() int
 	GetHotRegionCacheHitsThreshold() int
@@ -48,6 +49,7 @@ type ScheduleOptions interface {
 	IsRemoveExtraReplicaEnabled() bool
 	IsRemoveDownReplicaEnabled() bool
 	IsReplaceOfflineReplicaEnabled() bool
+	IsPlacementRulesEnabled() bool
 
 	GetMaxStoreDownTime() time.Duration
 }
@@ -324,6 +324,9 @@ func (r *RollingStoreStats) Set(stats *pdpb.StoreStats) {
 	r.bytesReadRate.Set(float64(stats.BytesRead) / float64(interval))
 	r.keysWriteRate.Set(float64(stats.KeysWritten) / float64(interval))
 	r.keysReadRate.Set(float64(stats.KeysRead) / float64(interval))
+	r.totalCPUUsage.Set(collect(stats.GetCpuUsages()))
+	r.totalBytesDiskReadRate.Set(collect(stats.GetReadIoRates()))
+	r.totalBytesDiskWriteRate.Set(collect(stats.GetWriteIoRates()))
 }
 
 // GetBytesRate returns the bytes write rate and the bytes read rate.
@@ -143,6 +143,7 @@ func (s *storeStatistics) Collect() {
 	configs["leader-schedule-limit"] = float64(s.opt.GetLeaderScheduleLimit())
 	configs["region-schedule-limit"] = float64(s.opt.GetRegionScheduleLimit())
 	configs["merge-schedule-limit"] = float64(s.opt.GetMergeScheduleLimit())
+	configs["split-schedule-limit"] = float64(s.opt.GetSplitScheduleLimit())
 	configs["replica-schedule-limit"] = float64(s.opt.GetReplicaScheduleLimit())
 	configs["max-replicas"] = float64(s.opt.GetMaxReplicas())
 	configs["high-space-ratio"] = s.opt.GetHighSpaceRatio()
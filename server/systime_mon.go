@@ -15,27 +15,72 @@ package server
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"go.uber.org/zap"
 )
 
-// StartMonitor calls systimeErrHandler if system time jump backward.
-func StartMonitor(ctx context.Context, now func() time.Time, systimeErrHandler func()) {
+const (
+	// systimeForwardJumpThreshold is the min offset of the system time to the
+	// monotonic clock to be considered as jumping forward.
+	systimeForwardJumpThreshold = time.Second
+	maxClockJumpHistory         = 32
+)
+
+// StartMonitor calls systimeErrHandler with the offset if system time jumps
+// backward, or jumps forward more than systimeForwardJumpThreshold.
+func StartMonitor(ctx context.Context, now func() time.Time, systimeErrHandler func(offset time.Duration)) {
 	log.Info("start system time monitor")
 	tick := time.NewTicker(100 * time.Millisecond)
 	defer tick.Stop()
 	for {
-		last := now().UnixNano()
+		last := now()
 		select {
 		case <-tick.C:
-			if now().UnixNano() < last {
-				log.Error("system time jump backward", zap.Int64("last", last))
-				systimeErrHandler()
+			current := now()
+			wallElapsed := time.Duration(current.UnixNano() - last.UnixNano())
+			if wallElapsed < 0 {
+				log.Error("system time jump backward", zap.Int64("last", last.UnixNano()))
+				systimeErrHandler(wallElapsed)
+			} else if offset := wallElapsed - current.Sub(last); offset > systimeForwardJumpThreshold {
+				// The elapsed time of the system time exceeds the one of the
+				// monotonic clock.
+				log.Error("system time jump forward", zap.Int64("last", last.UnixNano()), zap.Duration("offset", offset))
+				systimeErrHandler(offset)
 			}
 		case <-ctx.Done():
 			return
 		}
 	}
 }
+
+// ClockJump is a jump of the system time detected by the monitor.
+type ClockJump struct {
+	Time time.Time `json:"time"`
+	// Offset is negative if the system time jumps backward.
+	Offset typeutil.Duration `json:"offset"`
+}
+
+// clockJumpHistory keeps the recent jumps of the system time.
+type clockJumpHistory struct {
+	sync.RWMutex
+	jumps []ClockJump
+}
+
+func (h *clockJumpHistory) record(offset time.Duration) {
+	h.Lock()
+	defer h.Unlock()
+	h.jumps = append(h.jumps, ClockJump{Time: time.Now(), Offset: typeutil.NewDuration(offset)})
+	if len(h.jumps) > maxClockJumpHistory {
+		h.jumps = h.jumps[len(h.jumps)-maxClockJumpHistory:]
+	}
+}
+
+func (h *clockJumpHistory) get() []ClockJump {
+	h.RLock()
+	defer h.RUnlock()
+	return append([]ClockJump{}, h.jumps...)
+}
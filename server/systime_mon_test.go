@@ -34,7 +34,7 @@ func TestSystimeMonitor(t *testing.T) {
 			}
 
 			return time.Now().Add(-2 * time.Second)
-		}, func() {
+		}, func(time.Duration) {
 			atomic.StoreInt32(&jumpForward, 1)
 		})
 
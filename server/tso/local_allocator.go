@@ -0,0 +1,248 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tso
+
+import (
+	"context"
+	"path"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/etcdutil"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/member"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+	"go.uber.org/zap"
+)
+
+const (
+	// localAllocatorPath is the path of the local allocators, under which
+	// each data center saves its leader and timestamp window.
+	localAllocatorPath = "lta"
+	campaignRetryDelay = 200 * time.Millisecond
+)
+
+// LocalAllocator allocates the local timestamps of a data center. The PD
+// members in the same data center elect one of them to allocate, which saves
+// its timestamp window in etcd separately from the global one. The global
+// allocator never falls behind the windows of the local allocators, so a
+// global timestamp is greater than the local timestamps allocated before.
+type LocalAllocator struct {
+	dcLocation  string
+	client      *clientv3.Client
+	member      string
+	leaderPath  string
+	leaderLease int64
+	oracle      *TimestampOracle
+	isLeader    int64
+}
+
+// NewLocalAllocator creates a local allocator of the data center for the
+// member.
+func NewLocalAllocator(client *clientv3.Client, rootPath string, dcLocation string, member string, leaderLease int64, saveInterval time.Duration) *LocalAllocator {
+	allocatorPath := path.Join(rootPath, localAllocatorPath, dcLocation)
+	leaderPath := path.Join(allocatorPath, "leader")
+	return &LocalAllocator{
+		dcLocation:  dcLocation,
+		client:      client,
+		member:      member,
+		leaderPath:  leaderPath,
+		leaderLease: leaderLease,
+		oracle: &TimestampOracle{
+			timestampPath: path.Join(allocatorPath, "timestamp"),
+			leaderPath:    leaderPath,
+			member:        member,
+			client:        client,
+			saveInterval:  saveInterval,
+			gaugeLabel:    "tso-" + dcLocation,
+			statusLabel:   dcLocation,
+		},
+	}
+}
+
+// GetDCLocation returns the data center of the allocator.
+func (a *LocalAllocator) GetDCLocation() string {
+	return a.dcLocation
+}
+
+// IsLeader returns if the member is elected to allocate the local timestamps.
+func (a *LocalAllocator) IsLeader() bool {
+	return atomic.LoadInt64(&a.isLeader) == 1
+}
+
+// GetLeader returns the member elected to allocate the local timestamps, or
+// nil if there is no one.
+func (a *LocalAllocator) GetLeader() (*pdpb.Member, error) {
+	leader := &pdpb.Member{}
+	ok, _, err := etcdutil.GetProtoMsgWithModRev(a.client, a.leaderPath, leader)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return leader, nil
+}
+
+// GetRespTS is used to get the local timestamps.
+func (a *LocalAllocator) GetRespTS(count uint32) (pdpb.Timestamp, error) {
+	if !a.IsLeader() {
+		return pdpb.Timestamp{}, errors.Errorf("not the local tso allocator leader of %s", a.dcLocation)
+	}
+	return a.oracle.GetRespTS(count)
+}
+
+// GetStatus returns the status of the local timestamp oracle.
+func (a *LocalAllocator) GetStatus() *Status {
+	return a.oracle.GetStatus()
+}
+
+// Run campaigns for the local allocator leader and allocates the local
+// timestamps after elected, until the context is done.
+func (a *LocalAllocator) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("local tso allocator is stopped", zap.String("dc-location", a.dcLocation))
+			return
+		default:
+		}
+
+		resp, err := etcdutil.EtcdKVGet(a.client, a.leaderPath)
+		if err != nil {
+			log.Error("failed to get local tso allocator leader", zap.String("dc-location", a.dcLocation), zap.Error(err))
+			time.Sleep(campaignRetryDelay)
+			continue
+		}
+		if len(resp.Kvs) > 0 {
+			if string(resp.Kvs[0].Value) == a.member {
+				// The leader key is left by this member before it restarts.
+				if err := a.deleteLeaderKey(); err != nil {
+					log.Error("failed to delete local tso allocator leader key", zap.String("dc-location", a.dcLocation), zap.Error(err))
+					time.Sleep(campaignRetryDelay)
+				}
+				continue
+			}
+			a.watchLeader(ctx, resp.Kvs[0].ModRevision)
+			continue
+		}
+		a.campaign(ctx)
+		time.Sleep(campaignRetryDelay)
+	}
+}
+
+func (a *LocalAllocator) deleteLeaderKey() error {
+	resp, err := kv.NewSlowLogTxn(a.client).
+		If(clientv3.Compare(clientv3.Value(a.leaderPath), "=", a.member)).
+		Then(clientv3.OpDelete(a.leaderPath)).
+		Commit()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !resp.Succeeded {
+		return errors.New("failed to delete local tso allocator leader key, maybe not leader")
+	}
+	return nil
+}
+
+// watchLeader waits until the leader key is deleted.
+func (a *LocalAllocator) watchLeader(ctx context.Context, revision int64) {
+	watcher := clientv3.NewWatcher(a.client)
+	defer watcher.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for {
+		rch := watcher.Watch(ctx, a.leaderPath, clientv3.WithRev(revision))
+		for wresp := range rch {
+			if wresp.CompactRevision != 0 {
+				revision = wresp.CompactRevision
+				break
+			}
+			if wresp.Canceled {
+				log.Error("local tso allocator leader watcher is canceled", zap.String("dc-location", a.dcLocation), zap.Error(wresp.Err()))
+				return
+			}
+			for _, ev := range wresp.Events {
+				if ev.Type == mvccpb.DELETE {
+					log.Info("local tso allocator leader is deleted", zap.String("dc-location", a.dcLocation))
+					return
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+// campaign tries to be elected as the leader and allocates the local
+// timestamps until the lease expires or the context is done.
+func (a *LocalAllocator) campaign(ctx context.Context) {
+	lease := member.NewLeaderLease(a.client)
+	defer lease.Close()
+	if err := lease.Grant(a.leaderLease); err != nil {
+		log.Error("failed to grant local tso allocator lease", zap.String("dc-location", a.dcLocation), zap.Error(err))
+		return
+	}
+	// The leader key must not exist, so the CreateRevision is 0.
+	resp, err := kv.NewSlowLogTxn(a.client).
+		If(clientv3.Compare(clientv3.CreateRevision(a.leaderPath), "=", 0)).
+		Then(clientv3.OpPut(a.leaderPath, a.member, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil {
+		log.Error("failed to campaign local tso allocator leader", zap.String("dc-location", a.dcLocation), zap.Error(err))
+		return
+	}
+	if !resp.Succeeded {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go lease.KeepAlive(ctx)
+	log.Info("campaign local tso allocator leader ok", zap.String("dc-location", a.dcLocation))
+
+	if err := a.oracle.SyncTimestamp(lease); err != nil {
+		log.Error("failed to sync local timestamp", zap.String("dc-location", a.dcLocation), zap.Error(err))
+		return
+	}
+	defer a.oracle.ResetTimestamp()
+
+	atomic.StoreInt64(&a.isLeader, 1)
+	defer atomic.StoreInt64(&a.isLeader, 0)
+
+	tsTicker := time.NewTicker(UpdateTimestampStep)
+	defer tsTicker.Stop()
+	for {
+		select {
+		case <-tsTicker.C:
+			if lease.IsExpired() {
+				log.Info("local tso allocator lease expired, step down", zap.String("dc-location", a.dcLocation))
+				return
+			}
+			if err := a.oracle.UpdateTimestamp(); err != nil {
+				log.Error("failed to update local timestamp", zap.String("dc-location", a.dcLocation), zap.Error(err))
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -31,9 +31,18 @@ var (
 			Name:      "tso",
 			Help:      "Record of tso metadata.",
 		}, []string{"type"})
+
+	tsoStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "tso",
+			Name:      "status_seconds",
+			Help:      "Status of the timestamp oracle in seconds.",
+		}, []string{"allocator", "type"})
 )
 
 func init() {
 	prometheus.MustRegister(tsoCounter)
 	prometheus.MustRegister(tsoGauge)
+	prometheus.MustRegister(tsoStatusGauge)
 }
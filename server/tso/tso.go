@@ -14,7 +14,9 @@
 package tso
 
 import (
+	"context"
 	"path"
+	"strings"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -29,6 +31,7 @@ import (
 	"github.com/pingcap/pd/v4/server/member"
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
 	"go.uber.org/zap"
 )
 
@@ -44,24 +47,52 @@ type TimestampOracle struct {
 	// For tso, set after pd becomes leader.
 	ts            unsafe.Pointer
 	lastSavedTime atomic.Value
-	lease         *member.LeaderLease
-
-	rootPath      string
+	// lastSaveTime is the system time when the timestamp is saved last time.
+	lastSaveTime atomic.Value
+	// lease is the *member.LeaderLease set in sync.
+	lease atomic.Value
+	// logicalOverflowCount is the number of times the logical time runs out
+	// and the allocation waits for the physical time to be updated.
+	logicalOverflowCount int64
+	// lastSaveDuration is the latency of saving the timestamp last time in
+	// nanoseconds.
+	lastSaveDuration int64
+
+	timestampPath string
+	leaderPath    string
 	member        string
 	client        *clientv3.Client
 	saveInterval  time.Duration
 	maxResetTsGap func() time.Duration
+	// localTimestampPrefix is the prefix of the timestamp windows saved by
+	// the local allocators, which the global allocator never falls behind.
+	// It is empty for a local allocator.
+	localTimestampPrefix string
+	gaugeLabel           string
+	// statusLabel is the allocator label of the status metrics, which is
+	// the data center of a local allocator.
+	statusLabel string
+	// maxLocalPhysical is the max window saved by the local allocators,
+	// which is watched by the global allocator.
+	maxLocalPhysical atomic.Value
+	// localRevision is the etcd revision when the windows of the local
+	// allocators are loaded in sync, from which they are watched.
+	localRevision int64
 }
 
 // NewTimestampOracle creates a new TimestampOracle.
 // TODO: remove saveInterval
 func NewTimestampOracle(client *clientv3.Client, rootPath string, member string, saveInterval time.Duration, maxResetTsGap func() time.Duration) *TimestampOracle {
 	return &TimestampOracle{
-		rootPath:      rootPath,
-		client:        client,
-		saveInterval:  saveInterval,
-		maxResetTsGap: maxResetTsGap,
-		member:        member,
+		timestampPath:        path.Join(rootPath, "timestamp"),
+		leaderPath:           path.Join(rootPath, "leader"),
+		client:               client,
+		saveInterval:         saveInterval,
+		maxResetTsGap:        maxResetTsGap,
+		member:               member,
+		localTimestampPrefix: path.Join(rootPath, localAllocatorPath) + "/",
+		gaugeLabel:           "tso",
+		statusLabel:          "global",
 	}
 }
 
@@ -70,12 +101,8 @@ type atomicObject struct {
 	logical  int64
 }
 
-func (t *TimestampOracle) getTimestampPath() string {
-	return path.Join(t.rootPath, "timestamp")
-}
-
 func (t *TimestampOracle) loadTimestamp() (time.Time, error) {
-	data, err := etcdutil.GetValue(t.client, t.getTimestampPath())
+	data, err := etcdutil.GetValue(t.client, t.timestampPath)
 	if err != nil {
 		return typeutil.ZeroTime, err
 	}
@@ -85,14 +112,93 @@ func (t *TimestampOracle) loadTimestamp() (time.Time, error) {
 	return typeutil.ParseTimestamp(data)
 }
 
+// loadMaxLocalTimestamp returns the max timestamp window saved by the local
+// allocators, which is zero for a local allocator or if there is no local
+// allocator, and the etcd revision of the read.
+func (t *TimestampOracle) loadMaxLocalTimestamp() (time.Time, int64, error) {
+	max := typeutil.ZeroTime
+	if t.localTimestampPrefix == "" {
+		return max, 0, nil
+	}
+	resp, err := etcdutil.EtcdKVGet(t.client, t.localTimestampPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return max, 0, err
+	}
+	for _, item := range resp.Kvs {
+		if !strings.HasSuffix(string(item.Key), "/timestamp") {
+			continue
+		}
+		ts, err := typeutil.ParseTimestamp(item.Value)
+		if err != nil {
+			return max, 0, err
+		}
+		if ts.After(max) {
+			max = ts
+		}
+	}
+	return max, resp.Header.GetRevision(), nil
+}
+
+// WatchLocalTimestamps watches the timestamp windows saved by the local
+// allocators until the context is done, so that the global timestamp catches
+// up with the local ones without reading etcd in every update. A local
+// allocator may allocate timestamps up to its saved window, so the global
+// timestamp catches up with the whole window.
+func (t *TimestampOracle) WatchLocalTimestamps(ctx context.Context) {
+	if t.localTimestampPrefix == "" {
+		return
+	}
+	watcher := clientv3.NewWatcher(t.client)
+	defer watcher.Close()
+
+	revision := atomic.LoadInt64(&t.localRevision) + 1
+	for {
+		rch := watcher.Watch(ctx, t.localTimestampPrefix, clientv3.WithPrefix(), clientv3.WithRev(revision))
+		for wresp := range rch {
+			if wresp.CompactRevision != 0 {
+				revision = wresp.CompactRevision
+				break
+			}
+			if wresp.Canceled {
+				log.Error("local timestamp watcher is canceled", zap.Error(wresp.Err()))
+				break
+			}
+			for _, ev := range wresp.Events {
+				if ev.Type != mvccpb.PUT || !strings.HasSuffix(string(ev.Kv.Key), "/timestamp") {
+					continue
+				}
+				saved, err := typeutil.ParseTimestamp(ev.Kv.Value)
+				if err != nil {
+					log.Error("failed to parse local timestamp", zap.ByteString("key", ev.Kv.Key), zap.Error(err))
+					continue
+				}
+				t.updateMaxLocalPhysical(saved)
+			}
+			revision = wresp.Header.GetRevision() + 1
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+func (t *TimestampOracle) updateMaxLocalPhysical(physical time.Time) {
+	if max, ok := t.maxLocalPhysical.Load().(time.Time); !ok || physical.After(max) {
+		t.maxLocalPhysical.Store(physical)
+	}
+}
+
 // save timestamp, if lastTs is 0, we think the timestamp doesn't exist, so create it,
 // otherwise, update it.
 func (t *TimestampOracle) saveTimestamp(ts time.Time) error {
 	data := typeutil.Uint64ToBytes(uint64(ts.UnixNano()))
-	key := t.getTimestampPath()
+	key := t.timestampPath
 
-	leaderPath := path.Join(t.rootPath, "leader")
-	txn := kv.NewSlowLogTxn(t.client).If(append([]clientv3.Cmp{}, clientv3.Compare(clientv3.Value(leaderPath), "=", t.member))...)
+	start := time.Now()
+	txn := kv.NewSlowLogTxn(t.client).If(append([]clientv3.Cmp{}, clientv3.Compare(clientv3.Value(t.leaderPath), "=", t.member))...)
 	resp, err := txn.Then(clientv3.OpPut(key, string(data))).Commit()
 	if err != nil {
 		return errors.WithStack(err)
@@ -102,6 +208,8 @@ func (t *TimestampOracle) saveTimestamp(ts time.Time) error {
 	}
 
 	t.lastSavedTime.Store(ts)
+	t.lastSaveTime.Store(time.Now())
+	atomic.StoreInt64(&t.lastSaveDuration, int64(time.Since(start)))
 
 	return nil
 }
@@ -114,6 +222,15 @@ func (t *TimestampOracle) SyncTimestamp(lease *member.LeaderLease) error {
 	if err != nil {
 		return err
 	}
+	// The global allocator starts after the windows of the local allocators.
+	maxLocal, revision, err := t.loadMaxLocalTimestamp()
+	if err != nil {
+		return err
+	}
+	if maxLocal.After(last) {
+		last = maxLocal
+	}
+	atomic.StoreInt64(&t.localRevision, revision)
 
 	next := time.Now()
 	failpoint.Inject("fallBackSync", func() {
@@ -139,7 +256,7 @@ func (t *TimestampOracle) SyncTimestamp(lease *member.LeaderLease) error {
 	current := &atomicObject{
 		physical: next,
 	}
-	t.lease = lease
+	t.lease.Store(lease)
 	atomic.StorePointer(&t.ts, unsafe.Pointer(current))
 
 	return nil
@@ -147,7 +264,7 @@ func (t *TimestampOracle) SyncTimestamp(lease *member.LeaderLease) error {
 
 // ResetUserTimestamp update the physical part with specified tso.
 func (t *TimestampOracle) ResetUserTimestamp(tso uint64) error {
-	if t.lease == nil || t.lease.IsExpired() {
+	if lease := t.getLease(); lease == nil || lease.IsExpired() {
 		tsoCounter.WithLabelValues("err_lease_reset_ts").Inc()
 		return errors.New("Setup timestamp failed, lease expired")
 	}
@@ -199,6 +316,7 @@ func (t *TimestampOracle) UpdateTimestamp() error {
 	})
 
 	tsoCounter.WithLabelValues("save").Inc()
+	t.updateStatusMetrics()
 
 	jetLag := typeutil.SubTimeByWallClock(now, prev.physical)
 	if jetLag > 3*UpdateTimestampStep {
@@ -220,7 +338,16 @@ func (t *TimestampOracle) UpdateTimestamp() error {
 		// Because there is enough timestamp can be allocated before next update.
 		log.Warn("the logical time may be not enough", zap.Int64("prev-logical", prevLogical))
 		next = prev.physical.Add(time.Millisecond)
-	} else {
+	}
+
+	// The global timestamp catches up with the windows of the local
+	// allocators when they are saved, so it is greater than the local
+	// timestamps allocated before.
+	if maxLocal, ok := t.maxLocalPhysical.Load().(time.Time); ok && maxLocal.After(prev.physical) && maxLocal.After(next) {
+		next = maxLocal
+	}
+
+	if next == typeutil.ZeroTime {
 		// It will still use the previous physical time to alloc the timestamp.
 		tsoCounter.WithLabelValues("skip_save").Inc()
 		return nil
@@ -242,7 +369,7 @@ func (t *TimestampOracle) UpdateTimestamp() error {
 	}
 
 	atomic.StorePointer(&t.ts, unsafe.Pointer(current))
-	tsoGauge.WithLabelValues("tso").Set(float64(next.Unix()))
+	tsoGauge.WithLabelValues(t.gaugeLabel).Set(float64(next.Unix()))
 
 	return nil
 }
@@ -284,13 +411,72 @@ func (t *TimestampOracle) GetRespTS(count uint32) (pdpb.Timestamp, error) {
 				zap.Reflect("response", resp),
 				zap.Int("retry-count", i))
 			tsoCounter.WithLabelValues("logical_overflow").Inc()
+			atomic.AddInt64(&t.logicalOverflowCount, 1)
 			time.Sleep(UpdateTimestampStep)
 			continue
 		}
-		if t.lease == nil || t.lease.IsExpired() {
+		if lease := t.getLease(); lease == nil || lease.IsExpired() {
 			return pdpb.Timestamp{}, errors.New("alloc timestamp failed, lease expired")
 		}
 		return resp, nil
 	}
 	return resp, errors.New("can not get timestamp")
 }
+
+// Status is the status of a timestamp oracle.
+type Status struct {
+	// Allocating is true if the oracle is allocating timestamps.
+	Allocating bool `json:"allocating"`
+	// Physical and Logical are the current timestamp in memory.
+	Physical time.Time `json:"physical"`
+	Logical  int64     `json:"logical"`
+	// SavedPhysical is the upper bound of the physical time saved in etcd.
+	SavedPhysical time.Time `json:"saved_physical"`
+	// SaveWindow is the saved upper bound minus the physical time in memory.
+	SaveWindow typeutil.Duration `json:"save_window"`
+	// LastSaveTime is the system time when the upper bound is saved last time.
+	LastSaveTime  time.Time         `json:"last_save_time"`
+	SinceLastSave typeutil.Duration `json:"since_last_save"`
+	// SaveDuration is the latency of saving the upper bound last time.
+	SaveDuration typeutil.Duration `json:"save_duration"`
+	// LogicalOverflowCount is the number of times the logical time runs out
+	// and the allocation waits for the physical time to be updated.
+	LogicalOverflowCount int64 `json:"logical_overflow_count"`
+}
+
+// GetStatus returns the status of the timestamp oracle.
+func (t *TimestampOracle) GetStatus() *Status {
+	status := &Status{
+		LogicalOverflowCount: atomic.LoadInt64(&t.logicalOverflowCount),
+	}
+	current := (*atomicObject)(atomic.LoadPointer(&t.ts))
+	if current != nil && current.physical != typeutil.ZeroTime {
+		lease := t.getLease()
+		status.Allocating = lease != nil && !lease.IsExpired()
+		status.Physical = current.physical
+		status.Logical = atomic.LoadInt64(&current.logical)
+	}
+	if saved, ok := t.lastSavedTime.Load().(time.Time); ok {
+		status.SavedPhysical = saved
+		if status.Physical != typeutil.ZeroTime {
+			status.SaveWindow = typeutil.NewDuration(typeutil.SubTimeByWallClock(saved, status.Physical))
+		}
+	}
+	if saveTime, ok := t.lastSaveTime.Load().(time.Time); ok {
+		status.LastSaveTime = saveTime
+		status.SinceLastSave = typeutil.NewDuration(time.Since(saveTime))
+		status.SaveDuration = typeutil.NewDuration(time.Duration(atomic.LoadInt64(&t.lastSaveDuration)))
+	}
+	return status
+}
+
+func (t *TimestampOracle) getLease() *member.LeaderLease {
+	lease, _ := t.lease.Load().(*member.LeaderLease)
+	return lease
+}
+
+func (t *TimestampOracle) updateStatusMetrics() {
+	status := t.GetStatus()
+	tsoStatusGauge.WithLabelValues(t.statusLabel, "save_window").Set(status.SaveWindow.Seconds())
+	tsoStatusGauge.WithLabelValues(t.statusLabel, "since_last_save").Set(status.SinceLastSave.Seconds())
+}
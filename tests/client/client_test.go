@@ -28,10 +28,13 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	pd "github.com/pingcap/pd/v4/client"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pingcap/pd/v4/pkg/mock/mockid"
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/tso"
 	"github.com/pingcap/pd/v4/tests"
 	"go.etcd.io/etcd/clientv3"
 	"go.uber.org/goleak"
@@ -187,6 +190,115 @@ func (s *clientTestSuite) TestLeaderTransfer(c *C) {
 	wg.Wait()
 }
 
+func (s *clientTestSuite) TestLocalTSO(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 3, func(conf *config.Config) { conf.DCLocation = "dc-1" })
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+
+	var endpoints []string
+	for _, s := range cluster.GetServers() {
+		endpoints = append(endpoints, s.GetConfig().AdvertiseClientUrls)
+	}
+	cli, err := pd.NewClientWithContext(s.ctx, endpoints, pd.SecurityOption{})
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	var local uint64
+	testutil.WaitUntil(c, func(c *C) bool {
+		physical, logical, err := cli.GetTS(s.ctx, pd.WithDCLocation("dc-1"))
+		if err != nil {
+			c.Log(err)
+			return false
+		}
+		local = s.makeTS(physical, logical)
+		return true
+	})
+	for i := 0; i < 10; i++ {
+		physical, logical, err := cli.GetTS(s.ctx, pd.WithDCLocation("dc-1"))
+		c.Assert(err, IsNil)
+		ts := s.makeTS(physical, logical)
+		c.Assert(local, Less, ts)
+		local = ts
+	}
+	// The global timestamp is greater than the local timestamps allocated
+	// before, once the global allocator updates its timestamp.
+	time.Sleep(2 * tso.UpdateTimestampStep)
+	physical, logical, err := cli.GetTS(s.ctx)
+	c.Assert(err, IsNil)
+	c.Assert(local, Less, s.makeTS(physical, logical))
+}
+
+func (s *clientTestSuite) TestFollowerRead(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 3)
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	rc := leaderServer.GetRaftCluster()
+	c.Assert(rc, NotNil)
+	leaderPeer := &metapb.Peer{Id: 3, StoreId: 1}
+	region := &metapb.Region{
+		Id: 2,
+		RegionEpoch: &metapb.RegionEpoch{
+			ConfVer: 1,
+			Version: 1,
+		},
+		Peers: []*metapb.Peer{leaderPeer},
+	}
+	c.Assert(rc.HandleRegionHeartbeat(core.NewRegionInfo(region, leaderPeer)), IsNil)
+
+	follower := cluster.GetServer(cluster.GetFollower())
+	grpcClient := testutil.MustNewGrpcClient(c, follower.GetAddr())
+	req := &pdpb.GetRegionByIDRequest{
+		Header:   &pdpb.RequestHeader{ClusterId: follower.GetClusterID()},
+		RegionId: region.GetId(),
+	}
+	// The follower rejects the request which does not allow the follower read.
+	_, err = grpcClient.GetRegionByID(s.ctx, req)
+	c.Assert(err, NotNil)
+	testutil.WaitUntil(c, func(c *C) bool {
+		resp, err := grpcClient.GetRegionByID(grpcutil.WithFollowerHandle(s.ctx, time.Minute), req)
+		if err != nil {
+			c.Log(err)
+			return false
+		}
+		// The follower does not know the leader of the region.
+		return c.Check(resp.GetRegion(), DeepEquals, region) &&
+			c.Check(resp.GetLeader(), IsNil)
+	})
+
+	var endpoints []string
+	for _, s := range cluster.GetServers() {
+		endpoints = append(endpoints, s.GetConfig().AdvertiseClientUrls)
+	}
+	cli, err := pd.NewClientWithContext(s.ctx, endpoints, pd.SecurityOption{}, pd.WithFollowerRead(time.Minute))
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	for i := 0; i < 10; i++ {
+		r, _, err := cli.GetRegionByID(s.ctx, region.GetId())
+		c.Assert(err, IsNil)
+		c.Assert(r, DeepEquals, region)
+		r, _, err = cli.GetRegion(s.ctx, []byte("a"))
+		c.Assert(err, IsNil)
+		c.Assert(r, DeepEquals, region)
+		store, err := cli.GetStore(s.ctx, 1)
+		c.Assert(err, IsNil)
+		c.Assert(store.GetId(), Equals, uint64(1))
+	}
+	// Fall back to the leader if the region is not found by the follower.
+	r, _, err := cli.GetRegionByID(s.ctx, 100)
+	c.Assert(err, IsNil)
+	c.Assert(r, IsNil)
+}
+
 func (s *clientTestSuite) waitLeader(c *C, cli client, leader string) {
 	testutil.WaitUntil(c, func(c *C) bool {
 		cli.ScheduleCheckLeader()
@@ -351,6 +463,31 @@ func (s *testClientSuite) TestTSORace(c *C) {
 	wg.Wait()
 }
 
+func (s *testClientSuite) TestTSOBatchWait(c *C) {
+	cli, err := pd.NewClientWithContext(s.ctx, s.srv.GetEndpoints(), pd.SecurityOption{},
+		pd.WithTSOBatchWait(time.Millisecond), pd.WithTSOStreamCount(3))
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	var wg sync.WaitGroup
+	count := 10
+	wg.Add(count)
+	for i := 0; i < count; i++ {
+		go func() {
+			defer wg.Done()
+			var last int64
+			for i := 0; i < 100; i++ {
+				p, l, err := cli.GetTS(context.Background())
+				c.Assert(err, IsNil)
+				ts := p<<18 + l
+				c.Assert(ts, Greater, last)
+				last = ts
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func (s *testClientSuite) TestGetRegion(c *C) {
 	regionID := regionIDAllocator.alloc()
 	region := &metapb.Region{
@@ -583,14 +720,29 @@ func (s *testClientSuite) checkGCSafePoint(c *C, expectedSafePoint uint64) {
 
 func (s *testClientSuite) TestUpdateGCSafePoint(c *C) {
 	s.checkGCSafePoint(c, 0)
-	for _, safePoint := range []uint64{0, 1, 2, 3, 233, 23333, 233333333333, math.MaxUint64} {
+	for _, safePoint := range []uint64{0, 1, 2, 3, 233, 23333} {
 		newSafePoint, err := s.client.UpdateGCSafePoint(context.Background(), safePoint)
 		c.Assert(err, IsNil)
 		c.Assert(newSafePoint, Equals, safePoint)
 		s.checkGCSafePoint(c, safePoint)
 	}
+	// A service safe point less than the new one holds the GC safe point.
+	_, _, err := s.srv.UpdateServiceGCSafePoint("hold", 1000, 30000)
+	c.Assert(err, IsNil)
+	newSafePoint, err := s.client.UpdateGCSafePoint(context.Background(), 40000)
+	c.Assert(err, IsNil)
+	c.Assert(newSafePoint, Equals, uint64(30000))
+	s.checkGCSafePoint(c, 30000)
+	_, _, err = s.srv.UpdateServiceGCSafePoint("hold", 0, 0)
+	c.Assert(err, IsNil)
+	for _, safePoint := range []uint64{233333333333, math.MaxUint64} {
+		newSafePoint, err = s.client.UpdateGCSafePoint(context.Background(), safePoint)
+		c.Assert(err, IsNil)
+		c.Assert(newSafePoint, Equals, safePoint)
+		s.checkGCSafePoint(c, safePoint)
+	}
 	// If the new safe point is less than the old one, it should not be updated.
-	newSafePoint, err := s.client.UpdateGCSafePoint(context.Background(), 1)
+	newSafePoint, err = s.client.UpdateGCSafePoint(context.Background(), 1)
 	c.Assert(newSafePoint, Equals, uint64(math.MaxUint64))
 	c.Assert(err, IsNil)
 	s.checkGCSafePoint(c, math.MaxUint64)
@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tests/pdctl"
 )
@@ -207,3 +208,50 @@ func (s *configTestSuite) TestConfig(c *C) {
 	_, _, err = pdctl.ExecuteCommandC(cmd, args1...)
 	c.Assert(err, IsNil)
 }
+
+func (s *configTestSuite) TestRegionLabel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	c.Assert(err, IsNil)
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURLs()
+	cmd := pdctl.InitCommand()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	defer cluster.Destroy()
+
+	// config region-label set
+	args := []string{"-u", pdAddr, "config", "region-label", "set", "t1", "61", "63", "merge=deny", "schedule=deny"}
+	_, output, err := pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+	args = []string{"-u", pdAddr, "config", "region-label", "set", "t2", "XX", "", "merge=deny"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Failed!"), IsTrue)
+
+	// config region-label show
+	args = []string{"-u", pdAddr, "config", "region-label", "show"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	var rules []*labeler.LabelRule
+	c.Assert(json.Unmarshal(output, &rules), IsNil)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].ID, Equals, "t1")
+	c.Assert(rules[0].Labels, DeepEquals, map[string]string{"merge": "deny", "schedule": "deny"})
+
+	// config region-label delete
+	args = []string{"-u", pdAddr, "config", "region-label", "delete", "t1"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+	args = []string{"-u", pdAddr, "config", "region-label", "show"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	c.Assert(json.Unmarshal(output, &rules), IsNil)
+	c.Assert(rules, HasLen, 0)
+}
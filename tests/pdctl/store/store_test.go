@@ -23,7 +23,9 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/api"
+	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tests/pdctl"
 )
@@ -126,17 +128,30 @@ func (s *storeTestSuite) TestStore(c *C) {
 	args = []string{"-u", pdAddr, "store", "limit", "1", "10"}
 	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
 	c.Assert(err, IsNil)
+	addPeer := schedule.StoreLimitKey{Type: core.StoreLimitAddPeer}
+	removePeer := schedule.StoreLimitKey{Type: core.StoreLimitRemovePeer}
 	limits := leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit()
-	c.Assert(limits[1].Rate()*60, Equals, float64(10))
+	c.Assert(limits[1][addPeer].Rate()*60, Equals, float64(10))
+	c.Assert(limits[1][removePeer].Rate()*60, Equals, float64(10))
+
+	// store limit <store_id> <rate> <type> <kind>
+	args = []string{"-u", pdAddr, "store", "limit", "1", "30", "add-peer", "replica"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	limits = leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit()
+	c.Assert(limits[1][schedule.StoreLimitKey{Type: core.StoreLimitAddPeer, Kind: operator.OpReplica}].Rate()*60, Equals, float64(30))
+	c.Assert(limits[1][addPeer].Rate()*60, Equals, float64(10))
+	_, ok := limits[1][schedule.StoreLimitKey{Type: core.StoreLimitRemovePeer, Kind: operator.OpReplica}]
+	c.Assert(ok, IsFalse)
 
 	// store limit all <rate>
 	args = []string{"-u", pdAddr, "store", "limit", "all", "20"}
 	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
 	c.Assert(err, IsNil)
 	limits = leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit()
-	c.Assert(limits[1].Rate()*60, Equals, float64(20))
-	c.Assert(limits[3].Rate()*60, Equals, float64(20))
-	_, ok := limits[2]
+	c.Assert(limits[1][addPeer].Rate()*60, Equals, float64(20))
+	c.Assert(limits[3][removePeer].Rate()*60, Equals, float64(20))
+	_, ok = limits[2]
 	c.Assert(ok, IsFalse)
 
 	// store limit
@@ -144,8 +159,8 @@ func (s *storeTestSuite) TestStore(c *C) {
 	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
 	c.Assert(err, IsNil)
 	limits = leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit()
-	c.Assert(limits[1].Rate()*60, Equals, float64(20))
-	c.Assert(limits[3].Rate()*60, Equals, float64(20))
+	c.Assert(limits[1][addPeer].Rate()*60, Equals, float64(20))
+	c.Assert(limits[3][addPeer].Rate()*60, Equals, float64(20))
 	_, ok = limits[2]
 	c.Assert(ok, IsFalse)
 
@@ -25,8 +25,10 @@ import (
 	"time"
 
 	. "github.com/pingcap/check"
+	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/pkg/testutil"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/tests"
@@ -75,6 +77,12 @@ func (s *serverTestSuite) TestMemberDelete(c *C) {
 		}
 	}
 	c.Assert(members, HasLen, 2)
+	for _, m := range members {
+		m.GetServer().GetMember().CheckHealth(s.ctx, m.GetConfig().DataDir, 0, 0, time.Second)
+		score, err := leader.GetServer().GetMember().GetMemberHealthScore(m.GetServerID())
+		c.Assert(err, IsNil)
+		c.Assert(score, NotNil)
+	}
 
 	var table = []struct {
 		path    string
@@ -115,6 +123,13 @@ func (s *serverTestSuite) TestMemberDelete(c *C) {
 			return true
 		})
 	}
+
+	// The health scores of the removed members are deleted.
+	for _, m := range members {
+		score, err := leader.GetServer().GetMember().GetMemberHealthScore(m.GetServerID())
+		c.Assert(err, IsNil)
+		c.Assert(score, IsNil)
+	}
 }
 
 func (s *serverTestSuite) checkMemberList(c *C, clientURL string, configs []*config.Config) error {
@@ -172,6 +187,43 @@ func (s *serverTestSuite) TestLeaderPriority(c *C) {
 	})
 }
 
+func (s *serverTestSuite) TestAutoLeaderPriority(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 3, func(cfg *config.Config) {
+		cfg.EnableAutoLeaderPriority = true
+		cfg.LeaderPriorityCheckInterval = typeutil.NewDuration(100 * time.Millisecond)
+	})
+	defer cluster.Destroy()
+	c.Assert(err, IsNil)
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+
+	cluster.WaitLeader()
+
+	leader1, err := cluster.GetServer("pd1").GetEtcdLeader()
+	c.Assert(err, IsNil)
+	server1 := cluster.GetServer(leader1)
+	testutil.WaitUntil(c, func(c *C) bool {
+		return cluster.GetLeader() == leader1
+	})
+	// The unhealthy leader steps down.
+	c.Assert(failpoint.Enable("github.com/pingcap/pd/v4/server/member/unhealthyMember", fmt.Sprintf(`return("%s")`, leader1)), IsNil)
+	defer func() {
+		c.Assert(failpoint.Disable("github.com/pingcap/pd/v4/server/member/unhealthyMember"), IsNil)
+	}()
+	leader2 := s.waitEtcdLeaderChange(c, server1, leader1)
+	testutil.WaitUntil(c, func(c *C) bool {
+		return cluster.GetLeader() == leader2
+	})
+
+	// The unhealthy member does not take over the leadership by priority.
+	s.post(c, cluster.GetServer(leader2).GetConfig().ClientUrls+"/pd/api/v1/members/name/"+leader1, `{"leader-priority": 100}`)
+	time.Sleep(time.Second)
+	leader, err := server1.GetEtcdLeader()
+	c.Assert(err, IsNil)
+	c.Assert(leader, Equals, leader2)
+}
+
 func (s *serverTestSuite) post(c *C, url string, body string) {
 	testutil.WaitUntil(c, func(c *C) bool {
 		res, err := http.Post(url, "", bytes.NewBufferString(body))
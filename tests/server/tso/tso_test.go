@@ -23,8 +23,11 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/tso"
 	"github.com/pingcap/pd/v4/tests"
 	"go.uber.org/goleak"
 )
@@ -311,3 +314,106 @@ func (s *testFollowerTsoSuite) TestRequest(c *C) {
 	c.Assert(strings.Contains(err.Error(), "can not get timestamp"), IsTrue)
 	failpoint.Disable("github.com/pingcap/pd/v4/server/tso/skipRetryGetTS")
 }
+
+var _ = Suite(&testLocalTsoSuite{})
+
+type testLocalTsoSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *testLocalTsoSuite) SetUpSuite(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	server.EnableZap = true
+}
+
+func (s *testLocalTsoSuite) TearDownSuite(c *C) {
+	s.cancel()
+}
+
+func (s *testLocalTsoSuite) getTimestamp(c *C, svr *tests.TestServer, dcLocation string) (*pdpb.Timestamp, error) {
+	grpcPDClient := testutil.MustNewGrpcClient(c, svr.GetAddr())
+	req := &pdpb.TsoRequest{Header: testutil.NewRequestHeader(svr.GetClusterID()), Count: 1}
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	if dcLocation != "" {
+		ctx = grpcutil.WithDCLocation(ctx, dcLocation)
+	}
+	tsoClient, err := grpcPDClient.Tso(ctx)
+	c.Assert(err, IsNil)
+	defer tsoClient.CloseSend()
+	err = tsoClient.Send(req)
+	c.Assert(err, IsNil)
+	resp, err := tsoClient.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetTimestamp(), nil
+}
+
+func composeTS(ts *pdpb.Timestamp) uint64 {
+	return uint64(ts.GetPhysical()<<18 + ts.GetLogical())
+}
+
+func (s *testLocalTsoSuite) TestLocalTso(c *C) {
+	c.Assert(failpoint.Enable("github.com/pingcap/pd/v4/server/tso/skipRetryGetTS", `return(true)`), IsNil)
+	defer failpoint.Disable("github.com/pingcap/pd/v4/server/tso/skipRetryGetTS")
+	cluster, err := tests.NewTestCluster(s.ctx, 3, func(conf *config.Config) { conf.DCLocation = "dc-1" })
+	defer cluster.Destroy()
+	c.Assert(err, IsNil)
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	leaderServer := cluster.GetServer(cluster.WaitLeader())
+
+	var localLeader *tests.TestServer
+	testutil.WaitUntil(c, func(c *C) bool {
+		for _, svr := range cluster.GetServers() {
+			if svr.GetServer().GetLocalTSOAllocator().IsLeader() {
+				localLeader = svr
+				return true
+			}
+		}
+		return false
+	})
+
+	// Only the local allocator leader of the data center serves local timestamps.
+	var last *pdpb.Timestamp
+	for _, svr := range cluster.GetServers() {
+		ts, err := s.getTimestamp(c, svr, "dc-1")
+		if svr != localLeader {
+			c.Assert(err, NotNil)
+			continue
+		}
+		c.Assert(err, IsNil)
+		last = ts
+	}
+	_, err = s.getTimestamp(c, localLeader, "dc-2")
+	c.Assert(err, NotNil)
+
+	// The global timestamp is greater than the local timestamps allocated
+	// before, once it has caught up with the saved local window.
+	time.Sleep(2 * tso.UpdateTimestampStep)
+	for i := 0; i < 10; i++ {
+		last, err = s.getTimestamp(c, localLeader, "dc-1")
+		c.Assert(err, IsNil)
+		ts, err := s.getTimestamp(c, leaderServer, "")
+		c.Assert(err, IsNil)
+		c.Assert(composeTS(ts), Greater, composeTS(last))
+	}
+
+	// The local timestamps keep increasing after the local allocator leader changes.
+	c.Assert(localLeader.Stop(), IsNil)
+	testutil.WaitUntil(c, func(c *C) bool {
+		for _, svr := range cluster.GetServers() {
+			if svr != localLeader && svr.GetServer().GetLocalTSOAllocator().IsLeader() {
+				localLeader = svr
+				return true
+			}
+		}
+		return false
+	})
+	ts, err := s.getTimestamp(c, localLeader, "dc-1")
+	c.Assert(err, IsNil)
+	c.Assert(composeTS(ts), Greater, composeTS(last))
+}
@@ -0,0 +1,43 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"net/http"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serviceGCSafepointPrefix = "pd/api/v1/gc/safepoint"
+)
+
+// NewServiceGCSafepointCommand return a service gc safepoint subcommand of rootCmd
+func NewServiceGCSafepointCommand() *cobra.Command {
+	l := &cobra.Command{
+		Use:   "service-gc-safepoint",
+		Short: "show the gc safepoint of the cluster and the services that hold back gc",
+		Run:   showServiceGCSafepointCommandFunc,
+	}
+	return l
+}
+
+func showServiceGCSafepointCommandFunc(cmd *cobra.Command, args []string) {
+	r, err := doRequest(cmd, serviceGCSafepointPrefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get service GC safepoint: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
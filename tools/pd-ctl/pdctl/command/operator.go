@@ -16,6 +16,7 @@ package command
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -36,6 +37,7 @@ func NewOperatorCommand() *cobra.Command {
 	c.AddCommand(NewCheckOperatorCommand())
 	c.AddCommand(NewAddOperatorCommand())
 	c.AddCommand(NewRemoveOperatorCommand())
+	c.AddCommand(NewHistoryOperatorCommand())
 	return c
 }
 
@@ -412,6 +414,49 @@ func removeOperatorCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println("Success!")
 }
 
+// NewHistoryOperatorCommand returns a command to show the ended operators.
+func NewHistoryOperatorCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "history [--start=<unix_time>] [--end=<unix_time>] [--kind=<kind>] [--store=<store_id>] [--region=<region_id>]",
+		Short: "show the ended operators in the order of finish time",
+		Run:   historyOperatorCommandFunc,
+	}
+	c.Flags().String("start", "", "only show the operators finished at or after the unix time in seconds")
+	c.Flags().String("end", "", "only show the operators finished before the unix time in seconds")
+	c.Flags().String("kind", "", "only show the operators of the kinds, for example \"region,balance\"")
+	c.Flags().String("store", "", "only show the operators moving leader or peers from or to the store")
+	c.Flags().String("region", "", "only show the operators of the region")
+	return c
+}
+
+func historyOperatorCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	query := url.Values{}
+	for _, name := range []string{"start", "end", "kind", "store", "region"} {
+		v, err := cmd.Flags().GetString(name)
+		if err != nil {
+			cmd.Println(err)
+			return
+		}
+		if v != "" {
+			query.Set(name, v)
+		}
+	}
+	path := operatorsPrefix + "/history"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
 func parseUint64s(args []string) ([]uint64, error) {
 	results := make([]uint64, 0, len(args))
 	for _, arg := range args {
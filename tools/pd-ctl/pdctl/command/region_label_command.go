@@ -0,0 +1,128 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	regionLabelPrefix = "pd/api/v1/config/region-label"
+)
+
+// NewRegionLabelCommand returns a region-label subcommand of configCmd.
+func NewRegionLabelCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "region-label <subcommand>",
+		Short: "region label rules configuration",
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "show [<id>]",
+		Short: "show all region label rules or the rule with the id",
+		Run:   showRegionLabelRulesCommandFunc,
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "set <id> <start_key> <end_key> <label_key>=<label_value> [<label_key>=<label_value>...]",
+		Short: "set a region label rule, keys are in hex format and an empty end key means +inf",
+		Run:   setRegionLabelRuleCommandFunc,
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "delete <id>",
+		Short: "delete a region label rule",
+		Run:   deleteRegionLabelRuleCommandFunc,
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "region <region_id>",
+		Short: "show the labels of a region",
+		Run:   showRegionLabelsCommandFunc,
+	})
+	return c
+}
+
+func showRegionLabelRulesCommandFunc(cmd *cobra.Command, args []string) {
+	var prefix string
+	switch len(args) {
+	case 0:
+		prefix = path.Join(regionLabelPrefix, "rules")
+	case 1:
+		prefix = path.Join(regionLabelPrefix, "rule", args[0])
+	default:
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, prefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get region label rules: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func setRegionLabelRuleCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 4 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	labels := make(map[string]interface{})
+	for _, arg := range args[3:] {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			cmd.Printf("Invalid label: %s\n", arg)
+			return
+		}
+		labels[kv[0]] = kv[1]
+	}
+	input := map[string]interface{}{
+		"id":        args[0],
+		"start_key": args[1],
+		"end_key":   args[2],
+		"labels":    labels,
+	}
+	postJSON(cmd, path.Join(regionLabelPrefix, "rule"), input)
+}
+
+func deleteRegionLabelRuleCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	_, err := doRequest(cmd, path.Join(regionLabelPrefix, "rule", args[0]), http.MethodDelete)
+	if err != nil {
+		cmd.Printf("Failed to delete region label rule: %s\n", err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+func showRegionLabelsCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		cmd.Println("region_id should be a number")
+		return
+	}
+	r, err := doRequest(cmd, path.Join(regionLabelPrefix, "region", args[0]), http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get region labels: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
@@ -44,6 +44,8 @@ func NewSchedulerCommand() *cobra.Command {
 	c.AddCommand(NewPauseSchedulerCommand())
 	c.AddCommand(NewResumeSchedulerCommand())
 	c.AddCommand(NewConfigSchedulerCommand())
+	c.AddCommand(NewDiagnoseSchedulerCommand())
+	c.AddCommand(NewSchedulerWindowCommand())
 	return c
 }
 
@@ -76,6 +78,28 @@ func pauseOrResumeSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 	postJSON(cmd, path, input)
 }
 
+// NewSchedulerWindowCommand returns a command to set the schedule windows of a scheduler or a checker.
+func NewSchedulerWindowCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "window <scheduler|merge-checker|replica-checker> [<window>...]",
+		Short: "only allow a scheduler or a checker to run in the time windows like \"Mon-Fri 01:00-06:00\", no window means no restriction",
+		Run:   setSchedulerWindowCommandFunc,
+	}
+	return c
+}
+
+func setSchedulerWindowCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	path := schedulersPrefix + "/" + args[0] + "/windows"
+	input := map[string]interface{}{
+		"windows": args[1:],
+	}
+	postJSON(cmd, path, input)
+}
+
 // NewShowSchedulerCommand returns a command to show schedulers.
 func NewShowSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -110,6 +134,30 @@ func showSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println(r)
 }
 
+// NewDiagnoseSchedulerCommand returns a command to show the decision trace of a scheduler.
+func NewDiagnoseSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "diagnose <scheduler>",
+		Short: "show why a scheduler did or did not create operators in its last round",
+		Run:   diagnoseSchedulerCommandFunc,
+	}
+	return c
+}
+
+func diagnoseSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	path := schedulersPrefix + "/" + args[0] + "/diagnostic"
+	r, err := doRequest(cmd, path, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to diagnose scheduler %s: %s\n", args[0], err)
+		return
+	}
+	cmd.Println(r)
+}
+
 // NewAddSchedulerCommand returns a command to add scheduler.
 func NewAddSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -122,9 +170,11 @@ func NewAddSchedulerCommand() *cobra.Command {
 	c.AddCommand(NewShuffleRegionSchedulerCommand())
 	c.AddCommand(NewShuffleHotRegionSchedulerCommand())
 	c.AddCommand(NewScatterRangeSchedulerCommand())
+	c.AddCommand(NewExternalSchedulerCommand())
 	c.AddCommand(NewBalanceLeaderSchedulerCommand())
 	c.AddCommand(NewBalanceRegionSchedulerCommand())
 	c.AddCommand(NewBalanceHotRegionSchedulerCommand())
+	c.AddCommand(NewBalanceLoadSchedulerCommand())
 	c.AddCommand(NewRandomMergeSchedulerCommand())
 	c.AddCommand(NewBalanceAdjacentRegionSchedulerCommand())
 	c.AddCommand(NewLabelSchedulerCommand())
@@ -280,6 +330,16 @@ func NewBalanceHotRegionSchedulerCommand() *cobra.Command {
 	return c
 }
 
+// NewBalanceLoadSchedulerCommand returns a command to add a balance-load-scheduler.
+func NewBalanceLoadSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "balance-load-scheduler",
+		Short: "add a scheduler to move leaders and regions away from the stores with high CPU usage or disk IO",
+		Run:   addSchedulerCommandFunc,
+	}
+	return c
+}
+
 // NewRandomMergeSchedulerCommand returns a command to add a random-merge-scheduler.
 func NewRandomMergeSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -346,6 +406,28 @@ func addSchedulerForScatterRangeCommandFunc(cmd *cobra.Command, args []string) {
 	postJSON(cmd, schedulersPrefix, input)
 }
 
+// NewExternalSchedulerCommand returns a command to add an external-scheduler.
+func NewExternalSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "external-scheduler <name> <address>",
+		Short: "add a scheduler running out of the PD process",
+		Run:   addSchedulerForExternalCommandFunc,
+	}
+	return c
+}
+
+func addSchedulerForExternalCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	input := make(map[string]interface{})
+	input["name"] = cmd.Name()
+	input["scheduler_name"] = args[0]
+	input["address"] = args[1]
+	postJSON(cmd, schedulersPrefix, input)
+}
+
 // NewBalanceAdjacentRegionSchedulerCommand returns a command to add a balance-adjacent-region-scheduler.
 func NewBalanceAdjacentRegionSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -447,6 +529,7 @@ func NewConfigUpdateCommand() *cobra.Command {
 	}
 	c.AddCommand(NewConfigUpdateEvictLeaderSchedulerCommand())
 	c.AddCommand(NewConfigUpdateGrantLeaderSchedulerCommand())
+	c.AddCommand(NewConfigUpdateHotRegionSchedulerCommand())
 	return c
 }
 
@@ -458,6 +541,7 @@ func NewConfigShowCommand() *cobra.Command {
 	}
 	c.AddCommand(NewConfigShowEvictLeaderSchedulerCommand())
 	c.AddCommand(NewConfigShowGrantLeaderSchedulerCommand())
+	c.AddCommand(NewConfigShowHotRegionSchedulerCommand())
 	return c
 }
 
@@ -532,6 +616,41 @@ func NewConfigDeleteGrantLeaderSchedulerCommand() *cobra.Command {
 	return c
 }
 
+// NewConfigUpdateHotRegionSchedulerCommand returns a command to config balance-hot-region-scheduler
+func NewConfigUpdateHotRegionSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "balance-hot-region-scheduler <key> <value>",
+		Short: "set the config item of balance-hot-region-scheduler, use comma to separate the priorities, e.g. key,byte",
+		Run:   updateConfigHotRegionSchedulerCommandFunc,
+	}
+	return c
+}
+
+// NewConfigShowHotRegionSchedulerCommand returns a command to show the config of balance-hot-region-scheduler
+func NewConfigShowHotRegionSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "balance-hot-region-scheduler",
+		Short: "show the config of balance-hot-region-scheduler",
+		Run:   showConfigSchedulerForStoreCommandFunc,
+	}
+	return c
+}
+
+func updateConfigHotRegionSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	key, value := args[0], args[1]
+	input := make(map[string]interface{})
+	if v, err := strconv.ParseFloat(value, 64); err == nil {
+		input[key] = v
+	} else {
+		input[key] = strings.Split(value, ",")
+	}
+	postJSON(cmd, path.Join(schedulerConfigPrefix, cmd.Name(), "config"), input)
+}
+
 func updateConfigSchedulerForStoreCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 1 {
 		cmd.Println(cmd.UsageString())
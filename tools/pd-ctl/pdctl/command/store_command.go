@@ -41,6 +41,7 @@ func NewStoreCommand() *cobra.Command {
 	s.AddCommand(NewStoreLimitCommand())
 	s.AddCommand(NewRemoveTombStoneCommand())
 	s.AddCommand(NewStoreLimitSceneCommand())
+	s.AddCommand(NewStoreProgressCommand())
 	s.Flags().String("jq", "", "jq query")
 	return s
 }
@@ -85,10 +86,19 @@ func NewSetStoreWeightCommand() *cobra.Command {
 	}
 }
 
+// NewStoreProgressCommand returns a progress subcommand of storeCmd.
+func NewStoreProgressCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "progress <store_id>",
+		Short: "show the progress of removing an offline store or filling up a new store",
+		Run:   showStoreProgressCommandFunc,
+	}
+}
+
 // NewStoreLimitCommand returns a limit subcommand of storeCmd.
 func NewStoreLimitCommand() *cobra.Command {
 	return &cobra.Command{
-		Use:   "limit [<store_id>|<all> <rate>]",
+		Use:   "limit [<store_id>|<all> <rate> [add-peer|remove-peer] [<operator_kind>]]",
 		Short: "set a store's rate limit",
 		Run:   storeLimitCommandFunc,
 	}
@@ -238,6 +248,24 @@ func showStoreCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println(r)
 }
 
+func showStoreProgressCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Usage()
+		return
+	}
+	if _, err := strconv.Atoi(args[0]); err != nil {
+		cmd.Println("store_id should be a number")
+		return
+	}
+	prefix := fmt.Sprintf(path.Join(storePrefix, "progress"), args[0])
+	r, err := doRequest(cmd, prefix, http.MethodGet)
+	if err != nil {
+		cmd.Printf("Failed to get store progress: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
+
 func deleteStoreCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 1 {
 		cmd.Usage()
@@ -347,7 +375,7 @@ func storeLimitCommandFunc(cmd *cobra.Command, args []string) {
 		showAllLimitCommandFunc(cmd, args)
 		return
 	}
-	if len(args) != 2 {
+	if len(args) < 2 || len(args) > 4 {
 		cmd.Usage()
 		return
 	}
@@ -356,18 +384,23 @@ func storeLimitCommandFunc(cmd *cobra.Command, args []string) {
 		cmd.Println("rate should be a number that >= 0.")
 		return
 	}
+	input := map[string]interface{}{
+		"rate": rate,
+	}
+	if len(args) > 2 {
+		input["type"] = args[2]
+	}
+	if len(args) > 3 {
+		input["kind"] = args[3]
+	}
 	// if the storeid is "all", set limits for all stores
 	if args[0] == "all" {
 		prefix := path.Join(storesPrefix, "limit")
-		postJSON(cmd, prefix, map[string]interface{}{
-			"rate": rate,
-		})
+		postJSON(cmd, prefix, input)
 		return
 	}
 	prefix := fmt.Sprintf(path.Join(storePrefix, "limit"), args[0])
-	postJSON(cmd, prefix, map[string]interface{}{
-		"rate": rate,
-	})
+	postJSON(cmd, prefix, input)
 }
 
 func showStoresCommandFunc(cmd *cobra.Command, args []string) {
@@ -66,6 +66,7 @@ func Start(args []string) {
 		command.NewLogCommand(),
 		command.NewPluginCommand(),
 		command.NewComponentConfigCommand(),
+		command.NewServiceGCSafepointCommand(),
 	)
 
 	rootCmd.SetArgs(args)
@@ -82,6 +82,9 @@ func run(simCase string) {
 	if err := simConfig.Adjust(); err != nil {
 		simutil.Logger.Fatal("failed to adjust simulator configuration", zap.Error(err))
 	}
+	if adjust, ok := cases.ScheduleConfigMap[simCase]; ok {
+		adjust(&simConfig.ServerConfig.Schedule)
+	}
 
 	if *pdAddr != "" {
 		simStart(*pdAddr, simCase, simConfig)
@@ -15,6 +15,7 @@ package cases
 
 import (
 	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/tools/pd-simulator/simulator/info"
 	"github.com/pingcap/pd/v4/tools/pd-simulator/simulator/simutil"
@@ -102,6 +103,16 @@ var CaseMap = map[string]func() *Case{
 	"hot-write":                newHotWrite,
 	"makeup-down-replicas":     newMakeupDownReplicas,
 	"import-data":              newImportData,
+	"mixed-capacity":           newMixedCapacityBalanceRegion,
+	"store-class":              newStoreClassBalanceRegion,
+}
+
+// ScheduleConfigMap is a mapping of the cases to the functions which adjust
+// the schedule configuration they require. It only takes effect when the
+// simulator starts the PD server by itself.
+var ScheduleConfigMap = map[string]func(*config.ScheduleConfig){
+	"mixed-capacity": adjustMixedCapacityConfig,
+	"store-class":    adjustStoreClassConfig,
 }
 
 // NewCase creates a new case.
@@ -0,0 +1,110 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cases
+
+import (
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/tools/pd-simulator/simulator/info"
+	"github.com/pingcap/pd/v4/tools/pd-simulator/simulator/simutil"
+	"go.uber.org/zap"
+)
+
+// hddClassWeight is the weight of the hdd stores in the store class case.
+const hddClassWeight = 0.5
+
+func newMixedCapacityBalanceRegion() *Case {
+	return newMixedCapacityCase(nil)
+}
+
+func newStoreClassBalanceRegion() *Case {
+	return newMixedCapacityCase(map[string]float64{"hdd": hddClassWeight})
+}
+
+func adjustMixedCapacityConfig(cfg *config.ScheduleConfig) {
+	cfg.RegionWeightMode = core.CapacityRegionWeight.String()
+}
+
+func adjustStoreClassConfig(cfg *config.ScheduleConfig) {
+	cfg.RegionWeightMode = core.CapacityRegionWeight.String()
+	cfg.StoreClassWeights = map[string]float64{"hdd": hddClassWeight}
+}
+
+// newMixedCapacityCase creates a cluster whose stores are 1TB ssd and 4TB hdd
+// alternately, and the regions are distributed evenly at the beginning. The
+// case is finished when the region count of each store is proportional to its
+// capacity multiplied by the weight of its class.
+func newMixedCapacityCase(classWeights map[string]float64) *Case {
+	var simCase Case
+
+	storeNum := simutil.CaseConfigure.StoreNum
+	regionNum := simutil.CaseConfigure.RegionNum * storeNum / 3
+	if storeNum == 0 || regionNum == 0 {
+		storeNum, regionNum = 6, 4000
+	}
+
+	weights := make([]float64, storeNum+1)
+	var totalWeight float64
+	for i := 1; i <= storeNum; i++ {
+		capacity, class := uint64(1*TB), "ssd"
+		if i%2 == 0 {
+			capacity, class = 4*TB, "hdd"
+		}
+		simCase.Stores = append(simCase.Stores, &Store{
+			ID:        IDAllocator.nextID(),
+			Status:    metapb.StoreState_Up,
+			Labels:    []*metapb.StoreLabel{{Key: core.StoreClassLabel, Value: class}},
+			Capacity:  capacity,
+			Available: capacity,
+			Version:   "2.1.0",
+		})
+		weights[i] = float64(capacity / TB)
+		if classWeight, ok := classWeights[class]; ok {
+			weights[i] *= classWeight
+		}
+		totalWeight += weights[i]
+	}
+
+	for i := 0; i < regionNum; i++ {
+		peers := []*metapb.Peer{
+			{Id: IDAllocator.nextID(), StoreId: uint64(i%storeNum + 1)},
+			{Id: IDAllocator.nextID(), StoreId: uint64((i+1)%storeNum + 1)},
+			{Id: IDAllocator.nextID(), StoreId: uint64((i+2)%storeNum + 1)},
+		}
+		simCase.Regions = append(simCase.Regions, Region{
+			ID:     IDAllocator.nextID(),
+			Peers:  peers,
+			Leader: peers[0],
+			Size:   96 * MB,
+			Keys:   960000,
+		})
+	}
+
+	threshold := 0.05
+	simCase.Checker = func(regions *core.RegionsInfo, stats []info.StoreStats) bool {
+		res := true
+		regionCounts := make([]int, 0, storeNum)
+		for i := 1; i <= storeNum; i++ {
+			regionCount := regions.GetStoreRegionCount(uint64(i))
+			regionCounts = append(regionCounts, regionCount)
+			expected := int(float64(regionNum*3) * weights[i] / totalWeight)
+			res = res && isUniform(regionCount, expected, threshold)
+		}
+
+		simutil.Logger.Info("current counts", zap.Ints("region", regionCounts))
+		return res
+	}
+	return &simCase
+}
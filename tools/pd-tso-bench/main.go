@@ -36,6 +36,8 @@ var (
 	caPath      = flag.String("cacert", "", "path of file that contains list of trusted SSL CAs.")
 	certPath    = flag.String("cert", "", "path of file that contains X509 certificate in PEM format..")
 	keyPath     = flag.String("key", "", "path of file that contains X509 key in PEM format.")
+	batchWait   = flag.Duration("batch-wait", 0, "max time to wait for more requests to grow a batch, 0 disables the adaptive batching")
+	streams     = flag.Int("streams", 1, "number of tso streams to the leader")
 	wg          sync.WaitGroup
 )
 
@@ -46,7 +48,7 @@ func main() {
 		CAPath:   *caPath,
 		CertPath: *certPath,
 		KeyPath:  *keyPath,
-	})
+	}, pd.WithTSOBatchWait(*batchWait), pd.WithTSOStreamCount(*streams))
 	if err != nil {
 		log.Fatal(fmt.Sprintf("%v", err))
 	}